@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json/jsontext"
 	"errors"
 	"flag"
 	"fmt"
@@ -17,6 +18,7 @@ import (
 
 func init() {
 	log.SetFlags(0)
+	log.SetOutput(device.NewRedactWriter(os.Stderr))
 }
 
 // banner with the CLI version information and ASCII art.
@@ -36,6 +38,95 @@ func banner() {
 	fmt.Printf("\nRelease %s [%s] (Build Time %s)\n\n", meta.Version, meta.Hash, meta.BuildTime)
 }
 
+// parseFailure handles command parsing errors, returning the exit status.
+func parseFailure(flags *command.Flags, cmd *flag.FlagSet, err error) int {
+	switch {
+	// User explicitly passed -h or --help
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+
+	case errors.Is(err, command.ErrFlagConflict), errors.Is(err, command.ErrFlagMissing):
+		log.Printf("%v\n\n", err)
+		if cmd != nil {
+			cmd.Usage()
+		}
+
+	case errors.Is(err, command.ErrInvalid), errors.Is(err, command.ErrNotFound):
+		log.Printf("%v\n\n", err)
+		flags.Usage()
+	}
+
+	return 1
+}
+
+// report executes a listing procedure on the devices, and outputs the resulting Report.
+// The Report is output even when some devices fail, so the rest of them are still listed.
+func report(
+	tapper *device.Tapper,
+	devices device.Collection,
+	flags *command.Flags,
+	proc func(*device.Tapper, device.Resource, chan<- *device.ProcedureResult),
+	rep *device.Report,
+) error {
+	tapper.SetReport(rep)
+
+	_, err := tapper.Execute(proc, devices)
+
+	if rerr := device.ExecReport(rep, flags.ReportFormat(), flags.ReportOutput()); rerr != nil {
+		return rerr
+	}
+
+	return err
+}
+
+// offline executes the commands that run without scanning for devices.
+func offline(cmd *flag.FlagSet, driver string, flags *command.Flags) error {
+	switch cmd.Name() {
+	case command.Merge:
+		data, err := device.ReadFile(flags.File())
+		if err != nil {
+			return err
+		}
+
+		value := jsontext.Value(data)
+		if err = value.Indent(jsontext.WithIndent("  ")); err != nil {
+			return err
+		}
+
+		// References are resolved by then, so secrets must be redacted
+		if _, err = fmt.Fprintln(device.NewRedactWriter(os.Stdout), value.String()); err != nil {
+			return err
+		}
+
+	case command.Validate:
+		s, err := device.GetSchema(flags.SchemaKind(), driver)
+		if err != nil {
+			return err
+		}
+
+		if flags.SchemaExport() {
+			return device.ExportSchema(os.Stdout, s)
+		}
+
+		errs, err := device.ValidateFile(flags.File(), s)
+		if err != nil {
+			return err
+		}
+
+		for _, ve := range errs {
+			fmt.Printf("%s:%v\n", flags.File(), ve)
+		}
+
+		if len(errs) > 0 {
+			return fmt.Errorf("%w: %d issue(s) found", device.ErrValidationFailed, len(errs))
+		}
+
+		log.Printf("%s is valid\n", flags.File())
+	}
+
+	return nil
+}
+
 func main() {
 	banner()
 
@@ -49,6 +140,19 @@ func main() {
 		os.Exit(1)
 	}
 
+	if command.IsOffline(os.Args[1]) {
+		cmd, driver, err := flags.Parse(os.Args[1:])
+		if err != nil {
+			os.Exit(parseFailure(flags, cmd, err))
+		}
+
+		if err = offline(cmd, driver, flags); err != nil {
+			log.Fatalf("Unable to execute %s command: %v\n\n", cmd.Name(), err)
+		}
+
+		os.Exit(0)
+	}
+
 	// Collect IP addresses for scanning
 	ips, err := ip.Resolve(os.Args[1])
 	if err != nil {
@@ -61,23 +165,11 @@ func main() {
 
 	cmd, driver, err := flags.Parse(os.Args[2:])
 	if err != nil {
-		switch {
-		// User explicitly passed -h or --help
-		case errors.Is(err, flag.ErrHelp):
-			os.Exit(0)
-
-		case errors.Is(err, command.ErrFlagConflict):
-			log.Printf("%v\n\n", err)
-			if cmd != nil {
-				cmd.Usage()
-			}
-
-		case errors.Is(err, command.ErrInvalid), errors.Is(err, command.ErrNotFound):
-			log.Printf("%v\n\n", err)
-			flags.Usage()
-		}
+		os.Exit(parseFailure(flags, cmd, err))
+	}
 
-		os.Exit(1)
+	if command.IsOffline(cmd.Name()) {
+		os.Exit(parseFailure(flags, cmd, fmt.Errorf("%w: %s runs without an IP or CIDR", command.ErrInvalid, cmd.Name())))
 	}
 
 	tapper := device.NewTapper(flags.ProbeTimeout(), device.GetProbers(driver))
@@ -105,6 +197,16 @@ func main() {
 		}
 
 		tapper.SetConfig(cfg)
+		tapper.SetSnapshotDir(flags.SnapshotDir())
+	}
+
+	if flags.Variables() != "" {
+		vars, err := device.LoadVariables(flags.Variables())
+		if err != nil {
+			log.Fatalf("Unable to load device variables: %v\n\n", err)
+		}
+
+		tapper.SetVariables(vars)
 	}
 
 	if cmd.Name() == command.Secure && !flags.SecureOff() {
@@ -116,13 +218,122 @@ func main() {
 		tapper.SetAuthConfig(auth)
 	}
 
-	if cmd.Name() == command.Deploy {
-		dep, err := device.LoadDeployment(driver, flags.File())
+	if cmd.Name() == command.Deploy || (cmd.Name() == command.Scripts && (flags.Action() == command.ActionStatus || flags.Action() == command.ActionLint)) {
+		var (
+			dep *device.Deployment
+			err error
+		)
+
+		// Script status and lint checks don't deploy anything, so they work with any driver
+		if cmd.Name() == command.Scripts {
+			dep, err = device.ReadDeployment(flags.File())
+		} else {
+			dep, err = device.LoadDeployment(driver, flags.File())
+		}
+
 		if err != nil {
 			log.Fatalf("Unable to load deployment file: %v\n\n", err)
 		}
 
+		// Scripts are linted before any device is scanned, so nothing gets deployed if one of them is broken
+		if cmd.Name() != command.Scripts || flags.Action() == command.ActionLint {
+			if errs := dep.Lint(); len(errs) > 0 {
+				log.Fatalf("Unable to lint deployment scripts: %v\n\n", fmt.Errorf("%w:\n%w", device.ErrScriptLint, errors.Join(errs...)))
+			}
+		}
+
 		tapper.SetDeployment(dep)
+		tapper.SetPrune(flags.Prune())
+	}
+
+	if cmd.Name() == command.Schedule && flags.Action() == command.ActionApply {
+		sch, err := device.LoadSchedule(flags.File())
+		if err != nil {
+			log.Fatalf("Unable to load schedule file: %v\n\n", err)
+		}
+
+		tapper.SetSchedule(sch)
+		tapper.SetPrune(flags.Prune())
+	}
+
+	if cmd.Name() == command.Webhooks && flags.Action() == command.ActionApply {
+		wh, err := device.LoadWebhooks(flags.File())
+		if err != nil {
+			log.Fatalf("Unable to load webhooks file: %v\n\n", err)
+		}
+
+		tapper.SetWebhooks(wh)
+		tapper.SetPrune(flags.Prune())
+	}
+
+	if cmd.Name() == command.KVS {
+		tapper.SetKeys(flags.Keys())
+
+		// Deletions only load the key-value file when given, to enforce its policy
+		if flags.Action() == command.ActionSet || flags.Action() == command.ActionDelete && flags.File() != "" {
+			kv, err := device.LoadKeyValues(flags.File())
+			if err != nil {
+				log.Fatalf("Unable to load key-value file: %v\n\n", err)
+			}
+
+			tapper.SetKeyValues(kv)
+		}
+	}
+
+	if cmd.Name() == command.Virtual && flags.Action() == command.ActionApply {
+		v, err := device.LoadVirtualComponents(flags.File())
+		if err != nil {
+			log.Fatalf("Unable to load virtual components file: %v\n\n", err)
+		}
+
+		tapper.SetVirtualComponents(v)
+		tapper.SetPrune(flags.Prune())
+	}
+
+	if cmd.Name() == command.RPC {
+		rpc, err := device.NewRPC(flags.RPCMethod(), flags.RPCParams())
+		if err != nil {
+			log.Fatalf("Unable to parse RPC: %v\n\n", err)
+		}
+
+		tapper.SetRPC(rpc)
+	}
+
+	if cmd.Name() == command.Get {
+		q, err := device.NewQuery(flags.Document(), flags.Fields())
+		if err != nil {
+			log.Fatalf("Unable to parse field expressions: %v\n\n", err)
+		}
+
+		tapper.SetQuery(q)
+	}
+
+	if cmd.Name() == command.Switch {
+		sw, err := device.NewSwitch(flags.Action(), flags.SwitchChannel(), flags.SwitchTimer())
+		if err != nil {
+			log.Fatalf("Unable to parse switch: %v\n\n", err)
+		}
+
+		names, models, macs := flags.SwitchNames(), flags.SwitchModels(), flags.SwitchDevices()
+
+		if len(names) > 0 || len(models) > 0 || len(macs) > 0 {
+			mode := device.PolicyModeWhitelist
+			if flags.SwitchExclude() {
+				mode = device.PolicyModeBlacklist
+			}
+
+			sw.Policy, err = device.NewPolicy(mode, names, models, macs)
+			if err != nil {
+				log.Fatalf("Unable to parse device filters: %v\n\n", err)
+			}
+		}
+
+		tapper.SetSwitch(sw)
+	}
+
+	if cmd.Name() == command.Scripts {
+		tapper.SetScriptNames(flags.ScriptNames())
+		tapper.SetPullDir(flags.PullDir())
 	}
 
 	var affected = 0
@@ -200,6 +411,126 @@ func main() {
 		log.Print("Sending reboot request to devices...")
 
 		affected, err = tapper.Execute(device.Reboot, devices)
+
+	case command.RPC:
+		log.Printf("Calling %s on devices...", flags.RPCMethod())
+
+		err = report(tapper, devices, flags, device.Call, device.NewReport("Method", "Response").Raw("Response").Keyed())
+
+	case command.Get:
+		log.Printf("Getting device %s fields...", flags.Document())
+
+		err = report(tapper, devices, flags, device.QueryDocument, device.NewReport(flags.Fields()...))
+
+	case command.Switch:
+		log.Printf("Switching device outputs (%s)...", flags.Action())
+
+		err = report(tapper, devices, flags, device.SwitchOutput, device.NewReport("Channel", "Output"))
+
+	case command.Schedule:
+		switch flags.Action() {
+		case command.ActionList:
+			log.Print("Listing scheduled jobs...")
+
+			err = report(tapper, devices, flags, device.ListSchedule, device.NewReport("Job", "Enabled", "Schedule", "Action"))
+
+		case command.ActionApply:
+			log.Print("Applying schedule to devices...")
+
+			affected, err = tapper.Execute(device.ApplySchedule, devices)
+
+		case command.ActionClear:
+			log.Print("Clearing scheduled jobs from devices...")
+
+			affected, err = tapper.Execute(device.ClearSchedule, devices)
+		}
+
+	case command.Webhooks:
+		switch flags.Action() {
+		case command.ActionList:
+			log.Print("Listing webhooks...")
+
+			err = report(tapper, devices, flags, device.ListWebhooks, device.NewReport("Hook", "Event", "Channel", "Enabled", "URLs"))
+
+		case command.ActionApply:
+			log.Print("Applying webhooks to devices...")
+
+			affected, err = tapper.Execute(device.ApplyWebhooks, devices)
+		}
+
+	case command.KVS:
+		switch flags.Action() {
+		case command.ActionList:
+			log.Print("Listing key-value store entries...")
+
+			err = report(tapper, devices, flags, device.ListKeyValues, device.NewReport("Key", "Value"))
+
+		case command.ActionGet:
+			log.Print("Getting key-value store entries...")
+
+			err = report(tapper, devices, flags, device.GetKeyValues, device.NewReport("Key", "Value"))
+
+		case command.ActionSet:
+			log.Print("Setting key-value store entries on devices...")
+
+			affected, err = tapper.Execute(device.SetKeyValues, devices)
+
+		case command.ActionDelete:
+			log.Print("Deleting key-value store entries from devices...")
+
+			affected, err = tapper.Execute(device.DeleteKeyValues, devices)
+		}
+
+	case command.Virtual:
+		switch flags.Action() {
+		case command.ActionList:
+			log.Print("Listing virtual components...")
+
+			err = report(tapper, devices, flags, device.ListVirtual, device.NewReport("Component", "Name"))
+
+		case command.ActionApply:
+			log.Print("Applying virtual components to devices...")
+
+			affected, err = tapper.Execute(device.ApplyVirtual, devices)
+		}
+
+	case command.Scripts:
+		switch flags.Action() {
+		case command.ActionList:
+			log.Print("Listing scripts...")
+
+			err = report(tapper, devices, flags, device.ListScripts, device.NewReport("Script", "ID", "Enabled", "Running"))
+
+		case command.ActionStart:
+			log.Print("Starting scripts on devices...")
+
+			affected, err = tapper.Execute(device.StartScripts, devices)
+
+		case command.ActionStop:
+			log.Print("Stopping scripts on devices...")
+
+			affected, err = tapper.Execute(device.StopScripts, devices)
+
+		case command.ActionRemove:
+			log.Print("Removing scripts from devices...")
+
+			affected, err = tapper.Execute(device.RemoveScripts, devices)
+
+		case command.ActionPull:
+			log.Print("Pulling scripts from devices...")
+
+			affected, err = tapper.Execute(device.PullScripts, devices)
+
+		case command.ActionStatus:
+			log.Print("Comparing device scripts with the deployment...")
+
+			err = report(tapper, devices, flags, device.ScriptStatus, device.NewReport("Script", "Status", "Source", "Deployed"))
+
+		case command.ActionLint:
+			log.Print("Checking the deployment against device script limits...")
+
+			err = report(tapper, devices, flags, device.LintScripts, device.NewReport("Issue"))
+		}
 	}
 
 	if affected > 0 {
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/quetzyg/IoTap/command"
+)
+
+func TestParseFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		args    []string
+		status  int
+	}{
+		{
+			name:   "help flag",
+			args:   []string{command.Reboot, "-h"},
+			status: 0,
+		},
+		{
+			name:    "invalid command",
+			args:    []string{"foo"},
+			message: "foo",
+			status:  1,
+		},
+		{
+			name:    "flag conflict",
+			args:    []string{command.Secure, "-c", "auth.json", "-off"},
+			message: "'-c' and '--off' flags cannot be used together",
+			status:  1,
+		},
+		{
+			name:    "kvs get without keys",
+			args:    []string{command.KVS, command.ActionGet},
+			message: "'-k' flag is required by the get action",
+			status:  1,
+		},
+		{
+			name:    "scripts start without names",
+			args:    []string{command.Scripts, command.ActionStart},
+			message: "'-n' flag is required by the start action",
+			status:  1,
+		},
+		{
+			name:    "scripts remove without names",
+			args:    []string{command.Scripts, command.ActionRemove},
+			message: "'-n' flag is required by the remove action",
+			status:  1,
+		},
+		{
+			name:    "rpc without method",
+			args:    []string{command.RPC},
+			message: "'-m' flag is required",
+			status:  1,
+		},
+		{
+			name:    "get without fields",
+			args:    []string{command.Get},
+			message: "'-e' flag is required",
+			status:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			out := log.Writer()
+			log.SetOutput(&buf)
+			defer log.SetOutput(out)
+
+			flags := command.NewFlags()
+
+			cmd, _, err := flags.Parse(test.args)
+			if err == nil {
+				t.Fatal("expected a parsing error, got nil")
+			}
+
+			if status := parseFailure(flags, cmd, err); status != test.status {
+				t.Fatalf("expected status %d, got %d", test.status, status)
+			}
+
+			if !strings.Contains(buf.String(), test.message) {
+				t.Fatalf("expected %q to be printed, got %q", test.message, buf.String())
+			}
+		})
+	}
+}
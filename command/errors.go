@@ -8,4 +8,5 @@ var (
 	ErrInvalid       = errors.New("invalid command")
 	ErrArgumentParse = errors.New("error parsing argument")
 	ErrFlagConflict  = errors.New("conflicting command flags")
+	ErrFlagMissing   = errors.New("missing command flag")
 )
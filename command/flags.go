@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -47,19 +48,49 @@ func NewStrFlag(def string, options ...string) *StrFlag {
 
 // Available commands
 const (
-	Dump    = "dump"
-	Config  = "config"
-	Secure  = "secure"
-	Version = "version"
-	Update  = "update"
-	Deploy  = "deploy"
-	Reboot  = "reboot"
+	Dump     = "dump"
+	Config   = "config"
+	Secure   = "secure"
+	Version  = "version"
+	Update   = "update"
+	Deploy   = "deploy"
+	Reboot   = "reboot"
+	RPC      = "rpc"
+	Get      = "get"
+	Switch   = "switch"
+	Schedule = "schedule"
+	Webhooks = "webhooks"
+	KVS      = "kvs"
+	Virtual  = "virtual"
+	Scripts  = "scripts"
+	Merge    = "merge"
+	Validate = "validate"
+)
+
+// Command group actions
+const (
+	ActionList   = "list"
+	ActionApply  = "apply"
+	ActionClear  = "clear"
+	ActionGet    = "get"
+	ActionSet    = "set"
+	ActionDelete = "delete"
+	ActionStart  = "start"
+	ActionStop   = "stop"
+	ActionRemove = "remove"
+	ActionPull   = "pull"
+	ActionStatus = "status"
+	ActionLint   = "lint"
+	ActionOn     = "on"
+	ActionOff    = "off"
+	ActionToggle = "toggle"
 )
 
 // Usage strings
 const (
 	usage = `Usage:
 %s <IP|CIDR> <command> [flags]
+%s <offline command> [flags]
 
 Commands:
   dump    Output device scan results to STDOUT or to a file
@@ -69,27 +100,73 @@ Commands:
   update  Update firmware on outdated devices
   deploy  Deploy scripts to multiple devices
   reboot  Restart devices
-
-Use %s <IP|CIDR> <command> -h for more information about the command.
+  rpc     Call any device method, reporting the responses
+  get     Report device status or configuration fields
+
+Command groups:
+  switch <on|off|toggle>                            Operate device outputs (i.e. relays)
+  schedule <list|apply|clear>                       Manage scheduled jobs
+  webhooks <list|apply>                             Manage event webhooks
+  kvs <list|get|set|delete>                         Manage key-value store entries
+  virtual <list|apply>                              Manage virtual components
+  scripts <list|start|stop|remove|pull|status|lint> Manage installed scripts
+
+Offline commands:
+  merge    Output a configuration file, with its base files merged
+  validate Validate a configuration file, or export its JSON Schema
+
+Use %s <IP|CIDR> <command> -h or %s <offline command> -h for more information about the command.
 `
 	commandUsage = `Usage of %s:
  %s <IP|CIDR> %s [flags]
 
+Flags:
+`
+	groupUsage = `Usage of %s:
+ %s <IP|CIDR> %s <%s> [flags]
+
+Flags:
+`
+	offlineCommandUsage = `Usage of %s:
+ %s %s [flags]
+
 Flags:
 `
 )
 
+// IsOffline checks if a command runs without scanning for devices.
+func IsOffline(name string) bool {
+	return name == Merge || name == Validate
+}
+
+// snapshotDir returns the default directory where configuration snapshots are kept.
+func snapshotDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "iotap", "snapshots")
+}
+
 // Flags used in the command.
 type Flags struct {
 	driver  *StrFlag
 	file    *string
+	vars    *string
 	timeout *time.Duration
+	action  string
+	prune   *bool
+
+	reportFormat *StrFlag
+	reportOutput *string
 
 	dumpCmd       *flag.FlagSet
 	dumpSortField *StrFlag
 	dumpFormat    *StrFlag
 
-	configCmd *flag.FlagSet
+	configCmd         *flag.FlagSet
+	configSnapshotDir *string
 
 	secureCmd *flag.FlagSet
 	secureOff *bool
@@ -101,6 +178,47 @@ type Flags struct {
 	deployCmd *flag.FlagSet
 
 	rebootCmd *flag.FlagSet
+
+	rpcCmd    *flag.FlagSet
+	rpcMethod *string
+	rpcParams *string
+
+	getCmd      *flag.FlagSet
+	getDocument *StrFlag
+	getFields   *string
+
+	switchCmd     *flag.FlagSet
+	switchAction  *StrFlag
+	switchChannel *int
+	switchTimer   *time.Duration
+	switchNames   *string
+	switchModels  *string
+	switchDevices *string
+	switchExclude *bool
+
+	scheduleCmd    *flag.FlagSet
+	scheduleAction *StrFlag
+
+	webhooksCmd    *flag.FlagSet
+	webhooksAction *StrFlag
+
+	kvsCmd    *flag.FlagSet
+	kvsAction *StrFlag
+	kvsKeys   *string
+
+	virtualCmd    *flag.FlagSet
+	virtualAction *StrFlag
+
+	scriptsCmd     *flag.FlagSet
+	scriptsAction  *StrFlag
+	scriptsNames   *string
+	scriptsPullDir *string
+
+	mergeCmd *flag.FlagSet
+
+	validateCmd    *flag.FlagSet
+	validateKind   *StrFlag
+	validateExport *bool
 }
 
 // NewFlags creates a new *Flags instance.
@@ -109,6 +227,11 @@ func NewFlags() *Flags {
 		driver:  NewStrFlag(device.AllDrivers, device.AllDrivers, shellygen1.Driver, shellygen2.Driver),
 		timeout: new(time.Duration),
 		file:    new(string),
+		vars:    new(string),
+		prune:   new(bool),
+
+		reportFormat: NewStrFlag(device.FormatCSV, device.FormatCSV, device.FormatJSON),
+		reportOutput: new(string),
 	}
 
 	// Main usage
@@ -117,6 +240,8 @@ func NewFlags() *Flags {
 			usage,
 			os.Args[0],
 			os.Args[0],
+			os.Args[0],
+			os.Args[0],
 		)
 	}
 
@@ -147,6 +272,8 @@ func NewFlags() *Flags {
 	flags.configCmd.Var(flags.driver, "d", "Device driver")
 	flags.configCmd.DurationVar(flags.timeout, "t", device.ProbeTimeout, "Device probe timeout")
 	flags.configCmd.StringVar(flags.file, "c", "", "Device configuration file")
+	flags.configCmd.StringVar(flags.vars, "v", "", "Device variables file (CSV, JSON, YAML or TOML)")
+	flags.configSnapshotDir = flags.configCmd.String("s", snapshotDir(), "Configuration snapshot directory")
 	flags.configCmd.Usage = func() {
 		fmt.Printf(commandUsage, Config, os.Args[0], Config)
 		flags.configCmd.PrintDefaults()
@@ -186,6 +313,8 @@ func NewFlags() *Flags {
 	flags.deployCmd.Var(flags.driver, "d", "Device driver")
 	flags.deployCmd.DurationVar(flags.timeout, "t", device.ProbeTimeout, "Device probe timeout")
 	flags.deployCmd.StringVar(flags.file, "c", "", "Deployment configuration file")
+	flags.deployCmd.BoolVar(flags.prune, "prune", false, "Delete device scripts that aren't in the deployment file")
+	flags.deployCmd.StringVar(flags.vars, "v", "", "Device variables file (CSV, JSON, YAML or TOML)")
 	flags.deployCmd.Usage = func() {
 		fmt.Printf(commandUsage, Deploy, os.Args[0], Deploy)
 		flags.deployCmd.PrintDefaults()
@@ -200,6 +329,157 @@ func NewFlags() *Flags {
 		flags.rebootCmd.PrintDefaults()
 	}
 
+	// RPC
+	flags.rpcCmd = flag.NewFlagSet(RPC, flag.ContinueOnError)
+	flags.rpcCmd.Var(flags.driver, "d", "Device driver")
+	flags.rpcCmd.DurationVar(flags.timeout, "t", device.ProbeTimeout, "Device probe timeout")
+	flags.rpcMethod = flags.rpcCmd.String("m", "", "Method (Gen2) or HTTP API path (Gen1) to call")
+	flags.rpcParams = flags.rpcCmd.String("p", "", "Method parameters, as a JSON object")
+	flags.rpcCmd.StringVar(flags.vars, "v", "", "Device variables file (CSV, JSON, YAML or TOML)")
+	flags.rpcCmd.Var(flags.reportFormat, "f", "Report format")
+	flags.rpcCmd.StringVar(flags.reportOutput, "o", "", "Report output file")
+	flags.rpcCmd.Usage = func() {
+		fmt.Printf(commandUsage, RPC, os.Args[0], RPC)
+		flags.rpcCmd.PrintDefaults()
+	}
+
+	// Get
+	flags.getCmd = flag.NewFlagSet(Get, flag.ContinueOnError)
+	flags.getCmd.Var(flags.driver, "d", "Device driver")
+	flags.getCmd.DurationVar(flags.timeout, "t", device.ProbeTimeout, "Device probe timeout")
+	flags.getDocument = NewStrFlag(device.DocumentStatus, device.DocumentStatus, device.DocumentConfig)
+	flags.getCmd.Var(flags.getDocument, "k", "Document kind (status or config)")
+	flags.getFields = flags.getCmd.String("e", "", "Comma separated field expressions (e.g. cloud.enabled,wifi.sta.ssid)")
+	flags.getCmd.Var(flags.reportFormat, "f", "Report format")
+	flags.getCmd.StringVar(flags.reportOutput, "o", "", "Report output file")
+	flags.getCmd.Usage = func() {
+		fmt.Printf(commandUsage, Get, os.Args[0], Get)
+		flags.getCmd.PrintDefaults()
+	}
+
+	// Switch
+	flags.switchCmd = flag.NewFlagSet(Switch, flag.ContinueOnError)
+	flags.switchCmd.Var(flags.driver, "d", "Device driver")
+	flags.switchCmd.DurationVar(flags.timeout, "t", device.ProbeTimeout, "Device probe timeout")
+	flags.switchChannel = flags.switchCmd.Int("i", 0, "Output channel index")
+	flags.switchTimer = flags.switchCmd.Duration("timer", 0, "Turn the output back off after the given duration (on)")
+	flags.switchNames = flags.switchCmd.String("n", "", "Comma separated device name patterns to match")
+	flags.switchModels = flags.switchCmd.String("m", "", "Comma separated device model patterns to match")
+	flags.switchDevices = flags.switchCmd.String("a", "", "Comma separated device MAC addresses to match")
+	flags.switchExclude = flags.switchCmd.Bool("x", false, "Exclude the matching devices, instead of only including them")
+	flags.switchCmd.Var(flags.reportFormat, "f", "Report format")
+	flags.switchCmd.StringVar(flags.reportOutput, "o", "", "Report output file")
+	flags.switchAction = NewStrFlag("", ActionOn, ActionOff, ActionToggle)
+	flags.switchCmd.Usage = func() {
+		fmt.Printf(groupUsage, Switch, os.Args[0], Switch, strings.Join(flags.switchAction.options, "|"))
+		flags.switchCmd.PrintDefaults()
+	}
+
+	// Schedule
+	flags.scheduleCmd = flag.NewFlagSet(Schedule, flag.ContinueOnError)
+	flags.scheduleCmd.Var(flags.driver, "d", "Device driver")
+	flags.scheduleCmd.DurationVar(flags.timeout, "t", device.ProbeTimeout, "Device probe timeout")
+	flags.scheduleCmd.StringVar(flags.file, "c", "", "Schedule file (apply)")
+	flags.scheduleCmd.BoolVar(flags.prune, "prune", false, "Remove device jobs that aren't in the schedule file (apply)")
+	flags.scheduleCmd.Var(flags.reportFormat, "f", "Report format (list)")
+	flags.scheduleCmd.StringVar(flags.reportOutput, "o", "", "Report output file (list)")
+	flags.scheduleAction = NewStrFlag("", ActionList, ActionApply, ActionClear)
+	flags.scheduleCmd.Usage = func() {
+		fmt.Printf(groupUsage, Schedule, os.Args[0], Schedule, strings.Join(flags.scheduleAction.options, "|"))
+		flags.scheduleCmd.PrintDefaults()
+	}
+
+	// Webhooks
+	flags.webhooksCmd = flag.NewFlagSet(Webhooks, flag.ContinueOnError)
+	flags.webhooksCmd.Var(flags.driver, "d", "Device driver")
+	flags.webhooksCmd.DurationVar(flags.timeout, "t", device.ProbeTimeout, "Device probe timeout")
+	flags.webhooksCmd.StringVar(flags.file, "c", "", "Webhooks file (apply)")
+	flags.webhooksCmd.StringVar(flags.vars, "v", "", "Device variables file (CSV, JSON, YAML or TOML) (apply)")
+	flags.webhooksCmd.BoolVar(flags.prune, "prune", false, "Remove device hooks that aren't in the webhooks file (apply)")
+	flags.webhooksCmd.Var(flags.reportFormat, "f", "Report format (list)")
+	flags.webhooksCmd.StringVar(flags.reportOutput, "o", "", "Report output file (list)")
+	flags.webhooksAction = NewStrFlag("", ActionList, ActionApply)
+	flags.webhooksCmd.Usage = func() {
+		fmt.Printf(groupUsage, Webhooks, os.Args[0], Webhooks, strings.Join(flags.webhooksAction.options, "|"))
+		flags.webhooksCmd.PrintDefaults()
+	}
+
+	// KVS
+	flags.kvsCmd = flag.NewFlagSet(KVS, flag.ContinueOnError)
+	flags.kvsCmd.Var(flags.driver, "d", "Device driver")
+	flags.kvsCmd.DurationVar(flags.timeout, "t", device.ProbeTimeout, "Device probe timeout")
+	flags.kvsCmd.StringVar(flags.file, "c", "", "Key-value file (set), or its policy (delete)")
+	flags.kvsCmd.StringVar(flags.vars, "v", "", "Device variables file (CSV, JSON, YAML or TOML) (set)")
+	flags.kvsKeys = flags.kvsCmd.String("k", "", "Comma separated keys (get, delete)")
+	flags.kvsCmd.Var(flags.reportFormat, "f", "Report format (list, get)")
+	flags.kvsCmd.StringVar(flags.reportOutput, "o", "", "Report output file (list, get)")
+	flags.kvsAction = NewStrFlag("", ActionList, ActionGet, ActionSet, ActionDelete)
+	flags.kvsCmd.Usage = func() {
+		fmt.Printf(groupUsage, KVS, os.Args[0], KVS, strings.Join(flags.kvsAction.options, "|"))
+		flags.kvsCmd.PrintDefaults()
+	}
+
+	// Virtual
+	flags.virtualCmd = flag.NewFlagSet(Virtual, flag.ContinueOnError)
+	flags.virtualCmd.Var(flags.driver, "d", "Device driver")
+	flags.virtualCmd.DurationVar(flags.timeout, "t", device.ProbeTimeout, "Device probe timeout")
+	flags.virtualCmd.StringVar(flags.file, "c", "", "Virtual components file (apply)")
+	flags.virtualCmd.StringVar(flags.vars, "v", "", "Device variables file (CSV, JSON, YAML or TOML) (apply)")
+	flags.virtualCmd.BoolVar(flags.prune, "prune", false, "Delete device components that aren't in the virtual components file (apply)")
+	flags.virtualCmd.Var(flags.reportFormat, "f", "Report format (list)")
+	flags.virtualCmd.StringVar(flags.reportOutput, "o", "", "Report output file (list)")
+	flags.virtualAction = NewStrFlag("", ActionList, ActionApply)
+	flags.virtualCmd.Usage = func() {
+		fmt.Printf(groupUsage, Virtual, os.Args[0], Virtual, strings.Join(flags.virtualAction.options, "|"))
+		flags.virtualCmd.PrintDefaults()
+	}
+
+	// Scripts
+	flags.scriptsCmd = flag.NewFlagSet(Scripts, flag.ContinueOnError)
+	flags.scriptsCmd.Var(flags.driver, "d", "Device driver")
+	flags.scriptsCmd.DurationVar(flags.timeout, "t", device.ProbeTimeout, "Device probe timeout")
+	flags.scriptsCmd.StringVar(flags.file, "c", "", "Deployment configuration file (status, lint)")
+	flags.scriptsNames = flags.scriptsCmd.String("n", "", "Comma separated script names (start, stop, remove, pull)")
+	flags.scriptsPullDir = flags.scriptsCmd.String("p", "scripts", "Directory to pull scripts into (pull)")
+	flags.scriptsCmd.StringVar(flags.vars, "v", "", "Device variables file (CSV, JSON, YAML or TOML) (status, lint)")
+	flags.scriptsCmd.BoolVar(flags.prune, "prune", false, "Leave out device scripts that aren't in the deployment file (lint)")
+	flags.scriptsCmd.Var(flags.reportFormat, "f", "Report format (list, status, lint)")
+	flags.scriptsCmd.StringVar(flags.reportOutput, "o", "", "Report output file (list, status, lint)")
+	flags.scriptsAction = NewStrFlag("", ActionList, ActionStart, ActionStop, ActionRemove, ActionPull, ActionStatus, ActionLint)
+	flags.scriptsCmd.Usage = func() {
+		fmt.Printf(groupUsage, Scripts, os.Args[0], Scripts, strings.Join(flags.scriptsAction.options, "|"))
+		flags.scriptsCmd.PrintDefaults()
+	}
+
+	// Merge
+	flags.mergeCmd = flag.NewFlagSet(Merge, flag.ContinueOnError)
+	flags.mergeCmd.StringVar(flags.file, "c", "", "Configuration file")
+	flags.mergeCmd.Usage = func() {
+		fmt.Printf(offlineCommandUsage, Merge, os.Args[0], Merge)
+		flags.mergeCmd.PrintDefaults()
+	}
+
+	// Validate
+	flags.validateCmd = flag.NewFlagSet(Validate, flag.ContinueOnError)
+	flags.validateCmd.Var(flags.driver, "d", "Device driver")
+	flags.validateCmd.StringVar(flags.file, "c", "", "Configuration file")
+	flags.validateKind = NewStrFlag(
+		device.SchemaConfig,
+		device.SchemaConfig,
+		device.SchemaAuth,
+		device.SchemaDeployment,
+		device.SchemaSchedule,
+		device.SchemaWebhooks,
+		device.SchemaKVS,
+		device.SchemaVirtual,
+	)
+	flags.validateCmd.Var(flags.validateKind, "k", "Configuration file kind")
+	flags.validateExport = flags.validateCmd.Bool("x", false, "Export the JSON Schema, instead of validating a file")
+	flags.validateCmd.Usage = func() {
+		fmt.Printf(offlineCommandUsage, Validate, os.Args[0], Validate)
+		flags.validateCmd.PrintDefaults()
+	}
+
 	return flags
 }
 
@@ -223,6 +503,96 @@ func (f *Flags) File() string {
 	return *f.file
 }
 
+// Variables returns the device variables file path value.
+func (f *Flags) Variables() string {
+	return *f.vars
+}
+
+// Action returns the action of the parsed command group.
+func (f *Flags) Action() string {
+	return f.action
+}
+
+// ReportFormat returns the report data format value.
+func (f *Flags) ReportFormat() string {
+	return f.reportFormat.String()
+}
+
+// ReportOutput returns the report output file path value.
+func (f *Flags) ReportOutput() string {
+	return *f.reportOutput
+}
+
+// Prune returns true if device items that aren't declared in a file should be removed, false otherwise.
+func (f *Flags) Prune() bool {
+	return *f.prune
+}
+
+// Keys returns the key-value store entry keys value.
+func (f *Flags) Keys() []string {
+	return splitList(*f.kvsKeys)
+}
+
+// ScriptNames returns the device script names value.
+func (f *Flags) ScriptNames() []string {
+	return splitList(*f.scriptsNames)
+}
+
+// PullDir returns the directory where device scripts are downloaded.
+func (f *Flags) PullDir() string {
+	return *f.scriptsPullDir
+}
+
+// RPCMethod returns the method to call on devices.
+func (f *Flags) RPCMethod() string {
+	return *f.rpcMethod
+}
+
+// RPCParams returns the JSON parameters of the method to call on devices.
+func (f *Flags) RPCParams() string {
+	return *f.rpcParams
+}
+
+// Document returns the kind of device document to get.
+func (f *Flags) Document() string {
+	return f.getDocument.String()
+}
+
+// Fields returns the field expressions to get from device documents.
+func (f *Flags) Fields() []string {
+	return splitList(*f.getFields)
+}
+
+// SwitchChannel returns the index of the device output channel to operate.
+func (f *Flags) SwitchChannel() int {
+	return *f.switchChannel
+}
+
+// SwitchTimer returns the duration after which the device output is turned back off.
+func (f *Flags) SwitchTimer() time.Duration {
+	return *f.switchTimer
+}
+
+// SwitchNames returns the device name patterns to match.
+func (f *Flags) SwitchNames() []string {
+	return splitList(*f.switchNames)
+}
+
+// SwitchModels returns the device model patterns to match.
+func (f *Flags) SwitchModels() []string {
+	return splitList(*f.switchModels)
+}
+
+// SwitchDevices returns the device MAC addresses to match.
+func (f *Flags) SwitchDevices() []string {
+	return splitList(*f.switchDevices)
+}
+
+// SwitchExclude returns true if the matching devices should be excluded, false otherwise.
+func (f *Flags) SwitchExclude() bool {
+	return *f.switchExclude
+}
+
 // SortField returns the field by which the dump results should be sorted by.
 func (f *Flags) SortField() string {
 	return f.dumpSortField.String()
@@ -233,11 +603,63 @@ func (f *Flags) DumpFormat() string {
 	return f.dumpFormat.String()
 }
 
+// SnapshotDir returns the directory where configuration snapshots are kept.
+func (f *Flags) SnapshotDir() string {
+	return *f.configSnapshotDir
+}
+
+// SchemaKind returns the kind of configuration file to validate.
+func (f *Flags) SchemaKind() string {
+	return f.validateKind.String()
+}
+
+// SchemaExport returns true if the JSON Schema should be exported, false otherwise.
+func (f *Flags) SchemaExport() bool {
+	return *f.validateExport
+}
+
 // SecureOff returns true if device authentication should be turned off, false otherwise.
 func (f *Flags) SecureOff() bool {
 	return *f.secureOff
 }
 
+// splitList returns the non-empty values of a comma separated list.
+func splitList(list string) []string {
+	var values []string
+
+	for value := range strings.SplitSeq(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// parseAction parses the action of a command group, followed by the command flags.
+func (f *Flags) parseAction(cmd *flag.FlagSet, action *StrFlag, arguments []string) error {
+	if len(arguments) == 0 || strings.HasPrefix(arguments[0], "-") {
+		// Let the help flag through
+		if err := cmd.Parse(arguments); err != nil {
+			return fmt.Errorf("%w: %w", ErrArgumentParse, err)
+		}
+
+		return fmt.Errorf("%w: missing %s action (expected one of: %s)", ErrInvalid, cmd.Name(), strings.Join(action.options, ", "))
+	}
+
+	if err := action.Set(arguments[0]); err != nil {
+		return fmt.Errorf("%w: %s action %q (%w)", ErrInvalid, cmd.Name(), arguments[0], err)
+	}
+
+	if err := cmd.Parse(arguments[1:]); err != nil {
+		return fmt.Errorf("%w: %w", ErrArgumentParse, err)
+	}
+
+	f.action = action.String()
+
+	return nil
+}
+
 // Parse the CLI arguments.
 func (f *Flags) Parse(arguments []string) (*flag.FlagSet, string, error) {
 	if len(arguments) == 0 {
@@ -311,6 +733,106 @@ func (f *Flags) Parse(arguments []string) (*flag.FlagSet, string, error) {
 
 		return f.rebootCmd, f.driver.String(), nil
 
+	case RPC:
+		err = f.rpcCmd.Parse(arguments[1:])
+		if err != nil {
+			return f.rpcCmd, "", fmt.Errorf("%w: %w", ErrArgumentParse, err)
+		}
+
+		if strings.TrimSpace(f.RPCMethod()) == "" {
+			return f.rpcCmd, "", fmt.Errorf("%w: '-m' flag is required", ErrFlagMissing)
+		}
+
+		return f.rpcCmd, f.driver.String(), nil
+
+	case Get:
+		err = f.getCmd.Parse(arguments[1:])
+		if err != nil {
+			return f.getCmd, "", fmt.Errorf("%w: %w", ErrArgumentParse, err)
+		}
+
+		if len(f.Fields()) == 0 {
+			return f.getCmd, "", fmt.Errorf("%w: '-e' flag is required", ErrFlagMissing)
+		}
+
+		return f.getCmd, f.driver.String(), nil
+
+	case Switch:
+		err = f.parseAction(f.switchCmd, f.switchAction, arguments[1:])
+		if err != nil {
+			return f.switchCmd, "", err
+		}
+
+		if f.SwitchTimer() != 0 && f.Action() != ActionOn {
+			return f.switchCmd, "", fmt.Errorf("%w: '-timer' flag cannot be used with the %s action", ErrFlagConflict, f.Action())
+		}
+
+		return f.switchCmd, f.driver.String(), nil
+
+	case Schedule:
+		err = f.parseAction(f.scheduleCmd, f.scheduleAction, arguments[1:])
+		if err != nil {
+			return f.scheduleCmd, "", err
+		}
+
+		return f.scheduleCmd, f.driver.String(), nil
+
+	case Webhooks:
+		err = f.parseAction(f.webhooksCmd, f.webhooksAction, arguments[1:])
+		if err != nil {
+			return f.webhooksCmd, "", err
+		}
+
+		return f.webhooksCmd, f.driver.String(), nil
+
+	case KVS:
+		err = f.parseAction(f.kvsCmd, f.kvsAction, arguments[1:])
+		if err != nil {
+			return f.kvsCmd, "", err
+		}
+
+		if (f.Action() == ActionGet || f.Action() == ActionDelete) && len(f.Keys()) == 0 {
+			return f.kvsCmd, "", fmt.Errorf("%w: '-k' flag is required by the %s action", ErrFlagMissing, f.Action())
+		}
+
+		return f.kvsCmd, f.driver.String(), nil
+
+	case Virtual:
+		err = f.parseAction(f.virtualCmd, f.virtualAction, arguments[1:])
+		if err != nil {
+			return f.virtualCmd, "", err
+		}
+
+		return f.virtualCmd, f.driver.String(), nil
+
+	case Scripts:
+		err = f.parseAction(f.scriptsCmd, f.scriptsAction, arguments[1:])
+		if err != nil {
+			return f.scriptsCmd, "", err
+		}
+
+		if (f.Action() == ActionStart || f.Action() == ActionStop || f.Action() == ActionRemove) && len(f.ScriptNames()) == 0 {
+			return f.scriptsCmd, "", fmt.Errorf("%w: '-n' flag is required by the %s action", ErrFlagMissing, f.Action())
+		}
+
+		return f.scriptsCmd, f.driver.String(), nil
+
+	case Merge:
+		err = f.mergeCmd.Parse(arguments[1:])
+		if err != nil {
+			return f.mergeCmd, "", fmt.Errorf("%w: %w", ErrArgumentParse, err)
+		}
+
+		return f.mergeCmd, "", nil
+
+	case Validate:
+		err = f.validateCmd.Parse(arguments[1:])
+		if err != nil {
+			return f.validateCmd, "", fmt.Errorf("%w: %w", ErrArgumentParse, err)
+		}
+
+		return f.validateCmd, f.driver.String(), nil
+
 	default:
 		return nil, "", fmt.Errorf("%w: %s", ErrInvalid, arguments[0])
 	}
@@ -3,7 +3,9 @@ package command
 import (
 	"errors"
 	"flag"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/quetzyg/IoTap/device"
 	"github.com/quetzyg/IoTap/shellygen1"
@@ -14,6 +16,43 @@ func TestFlags_Usage(t *testing.T) {
 	NewFlags().Usage()
 }
 
+func TestIsOffline(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		offline bool
+	}{
+		{
+			name:    "online command",
+			command: Config,
+			offline: false,
+		},
+		{
+			name:    "offline command",
+			command: Merge,
+			offline: true,
+		},
+		{
+			name:    "validate command",
+			command: Validate,
+			offline: true,
+		},
+		{
+			name:    "IP address",
+			command: "192.168.146.123",
+			offline: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if offline := IsOffline(test.command); offline != test.offline {
+				t.Fatalf("expected %t, got %t", test.offline, offline)
+			}
+		})
+	}
+}
+
 func TestFlags_Driver(t *testing.T) {
 	drv := NewFlags().Driver()
 
@@ -259,6 +298,270 @@ func TestFlags_File(t *testing.T) {
 	}
 }
 
+func TestFlags_SnapshotDir(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  string
+		args []string
+	}{
+		{
+			name: "get default snapshot directory value",
+			args: []string{Config},
+			dir:  snapshotDir(),
+		},
+		{
+			name: "get snapshot directory value",
+			args: []string{Config, "-s", "/tmp/snapshots"},
+			dir:  "/tmp/snapshots",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := NewFlags()
+
+			_, _, err := flags.Parse(test.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			dir := flags.SnapshotDir()
+			if dir != test.dir {
+				t.Fatalf("Unexpected snapshot directory. Got %q, expected %q", dir, test.dir)
+			}
+		})
+	}
+}
+
+func TestFlags_Schema(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   string
+		args   []string
+		export bool
+	}{
+		{
+			name: "get default schema values",
+			args: []string{Validate},
+			kind: device.SchemaConfig,
+		},
+		{
+			name:   "get schema values",
+			args:   []string{Validate, "-k", device.SchemaDeployment, "-x"},
+			kind:   device.SchemaDeployment,
+			export: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := NewFlags()
+
+			_, _, err := flags.Parse(test.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if kind := flags.SchemaKind(); kind != test.kind {
+				t.Fatalf("Unexpected schema kind. Got %q, expected %q", kind, test.kind)
+			}
+
+			if export := flags.SchemaExport(); export != test.export {
+				t.Fatalf("Unexpected schema export. Got %t, expected %t", export, test.export)
+			}
+		})
+	}
+}
+
+func TestFlags_Action(t *testing.T) {
+	tests := []struct {
+		name   string
+		action string
+		format string
+		output string
+		args   []string
+		prune  bool
+	}{
+		{
+			name:   "get default action values",
+			args:   []string{Schedule, ActionList},
+			action: ActionList,
+			format: device.FormatCSV,
+		},
+		{
+			name:   "get action values",
+			args:   []string{Schedule, ActionApply, "-c", "schedule.json", "--prune", "-f", device.FormatJSON, "-o", "jobs.json"},
+			action: ActionApply,
+			format: device.FormatJSON,
+			output: "jobs.json",
+			prune:  true,
+		},
+		{
+			name:   "get webhooks action values",
+			args:   []string{Webhooks, ActionApply, "-c", "webhooks.json", "-v", "vars.csv", "--prune"},
+			action: ActionApply,
+			format: device.FormatCSV,
+			prune:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := NewFlags()
+
+			_, _, err := flags.Parse(test.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if action := flags.Action(); action != test.action {
+				t.Fatalf("Unexpected action. Got %q, expected %q", action, test.action)
+			}
+
+			if format := flags.ReportFormat(); format != test.format {
+				t.Fatalf("Unexpected report format. Got %q, expected %q", format, test.format)
+			}
+
+			if output := flags.ReportOutput(); output != test.output {
+				t.Fatalf("Unexpected report output. Got %q, expected %q", output, test.output)
+			}
+
+			if prune := flags.Prune(); prune != test.prune {
+				t.Fatalf("Unexpected prune. Got %t, expected %t", prune, test.prune)
+			}
+		})
+	}
+}
+
+func TestFlags_Keys(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		keys []string
+	}{
+		{
+			name: "get no keys",
+			args: []string{KVS, ActionList},
+		},
+		{
+			name: "get trimmed keys",
+			args: []string{KVS, ActionGet, "-k", "room, threshold,,"},
+			keys: []string{"room", "threshold"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := NewFlags()
+
+			_, _, err := flags.Parse(test.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if keys := flags.Keys(); !reflect.DeepEqual(keys, test.keys) {
+				t.Fatalf("Unexpected keys. Got %q, expected %q", keys, test.keys)
+			}
+		})
+	}
+}
+
+func TestFlags_ScriptNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		names []string
+	}{
+		{
+			name: "list no names",
+			args: []string{Scripts, ActionList},
+		},
+		{
+			name:  "start trimmed names",
+			args:  []string{Scripts, ActionStart, "-n", "announce.js, helper,,"},
+			names: []string{"announce.js", "helper"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := NewFlags()
+
+			_, _, err := flags.Parse(test.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if names := flags.ScriptNames(); !reflect.DeepEqual(names, test.names) {
+				t.Fatalf("Unexpected names. Got %q, expected %q", names, test.names)
+			}
+		})
+	}
+}
+
+func TestFlags_RPC(t *testing.T) {
+	flags := NewFlags()
+
+	_, _, err := flags.Parse([]string{RPC, "-m", "Script.Eval", "-p", `{"id":1,"code":"ping()"}`})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if method := flags.RPCMethod(); method != "Script.Eval" {
+		t.Fatalf("Unexpected method. Got %q, expected %q", method, "Script.Eval")
+	}
+
+	if params := flags.RPCParams(); params != `{"id":1,"code":"ping()"}` {
+		t.Fatalf("Unexpected params. Got %q", params)
+	}
+}
+
+func TestFlags_Get(t *testing.T) {
+	flags := NewFlags()
+
+	_, _, err := flags.Parse([]string{Get, "-e", "cloud.connected, wifi.sta_ip,,"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if document := flags.Document(); document != device.DocumentStatus {
+		t.Fatalf("Unexpected document kind. Got %q, expected %q", document, device.DocumentStatus)
+	}
+
+	if fields, expected := flags.Fields(), []string{"cloud.connected", "wifi.sta_ip"}; !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("Unexpected fields. Got %q, expected %q", fields, expected)
+	}
+}
+
+func TestFlags_Switch(t *testing.T) {
+	flags := NewFlags()
+
+	_, _, err := flags.Parse([]string{Switch, ActionOn, "-i", "1", "-timer", "5m", "-m", "SNSW, SHSW-1,,", "-x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if channel := flags.SwitchChannel(); channel != 1 {
+		t.Fatalf("Unexpected channel. Got %d, expected %d", channel, 1)
+	}
+
+	if timer := flags.SwitchTimer(); timer != 5*time.Minute {
+		t.Fatalf("Unexpected timer. Got %s, expected %s", timer, 5*time.Minute)
+	}
+
+	if models, expected := flags.SwitchModels(), []string{"SNSW", "SHSW-1"}; !reflect.DeepEqual(models, expected) {
+		t.Fatalf("Unexpected models. Got %q, expected %q", models, expected)
+	}
+
+	if names := flags.SwitchNames(); names != nil {
+		t.Fatalf("Unexpected names. Got %q", names)
+	}
+
+	if !flags.SwitchExclude() {
+		t.Fatal("Expected matching devices to be excluded")
+	}
+}
+
 func TestFlags_Parse(t *testing.T) {
 	tests := []struct {
 		err     error
@@ -495,6 +798,12 @@ func TestFlags_Parse(t *testing.T) {
 			command: Deploy,
 			driver:  shellygen1.Driver,
 		},
+		{
+			name:    "success: deploy command with variables",
+			args:    []string{Deploy, "-d", shellygen2.Driver, "-c", "deployment.json", "-v", "vars.csv"},
+			command: Deploy,
+			driver:  shellygen2.Driver,
+		},
 		{
 			name:    "success: deploy command with help flag",
 			args:    []string{Deploy, "-h"},
@@ -527,6 +836,355 @@ func TestFlags_Parse(t *testing.T) {
 			command: Reboot,
 			err:     flag.ErrHelp,
 		},
+
+		// RPC
+		{
+			name:    "failure: rpc command with undefined flag",
+			args:    []string{RPC, "-foo"},
+			command: RPC,
+			err:     ErrArgumentParse,
+		},
+		{
+			name:    "failure: rpc command without method",
+			args:    []string{RPC, "-d", shellygen2.Driver, "-p", `{"id":0}`},
+			command: RPC,
+			err:     ErrFlagMissing,
+		},
+		{
+			name:    "success: rpc command with valid flags",
+			args:    []string{RPC, "-d", shellygen2.Driver, "-m", "Switch.GetStatus", "-p", `{"id":0}`, "-f", device.FormatJSON},
+			command: RPC,
+			driver:  shellygen2.Driver,
+		},
+		{
+			name:    "success: rpc command with help flag",
+			args:    []string{RPC, "-h"},
+			command: RPC,
+			err:     flag.ErrHelp,
+		},
+
+		// Get
+		{
+			name:    "failure: get command with invalid document kind",
+			args:    []string{Get, "-k", "foo", "-e", "cloud.enabled"},
+			command: Get,
+			err:     ErrArgumentParse,
+		},
+		{
+			name:    "failure: get command without fields",
+			args:    []string{Get, "-e", " , "},
+			command: Get,
+			err:     ErrFlagMissing,
+		},
+		{
+			name:    "success: get command with valid flags",
+			args:    []string{Get, "-d", shellygen2.Driver, "-k", device.DocumentConfig, "-e", "cloud.enable", "-f", device.FormatJSON},
+			command: Get,
+			driver:  shellygen2.Driver,
+		},
+		{
+			name:    "success: get command with help flag",
+			args:    []string{Get, "-h"},
+			command: Get,
+			err:     flag.ErrHelp,
+		},
+
+		// Switch
+		{
+			name:    "failure: switch command without action",
+			args:    []string{Switch},
+			command: Switch,
+			err:     ErrInvalid,
+		},
+		{
+			name:    "failure: switch command with invalid action",
+			args:    []string{Switch, "flip"},
+			command: Switch,
+			err:     ErrInvalid,
+		},
+		{
+			name:    "failure: switch command with timer and toggle action",
+			args:    []string{Switch, ActionToggle, "-timer", "1m"},
+			command: Switch,
+			err:     ErrFlagConflict,
+		},
+		{
+			name:    "success: switch command with valid flags",
+			args:    []string{Switch, ActionOff, "-d", shellygen1.Driver, "-i", "1", "-n", "^office-", "-f", device.FormatJSON},
+			command: Switch,
+			driver:  shellygen1.Driver,
+		},
+		{
+			name:    "success: switch command with help flag",
+			args:    []string{Switch, ActionToggle, "-h"},
+			command: Switch,
+			err:     flag.ErrHelp,
+		},
+
+		// Merge
+		{
+			name:    "failure: merge command with undefined flag",
+			args:    []string{Merge, "-foo"},
+			command: Merge,
+			err:     ErrArgumentParse,
+		},
+		{
+			name:    "failure: merge command with missing config flag value",
+			args:    []string{Merge, "-c"},
+			command: Merge,
+			err:     ErrArgumentParse,
+		},
+		{
+			name:    "success: merge command with valid flags",
+			args:    []string{Merge, "-c", "config.json"},
+			command: Merge,
+		},
+		{
+			name:    "success: merge command with help flag",
+			args:    []string{Merge, "-h"},
+			command: Merge,
+			err:     flag.ErrHelp,
+		},
+
+		// Schedule
+		{
+			name:    "failure: schedule command without action",
+			args:    []string{Schedule},
+			command: Schedule,
+			err:     ErrInvalid,
+		},
+		{
+			name:    "failure: schedule command with flags but without action",
+			args:    []string{Schedule, "-c", "schedule.json"},
+			command: Schedule,
+			err:     ErrInvalid,
+		},
+		{
+			name:    "failure: schedule command with invalid action",
+			args:    []string{Schedule, "foo"},
+			command: Schedule,
+			err:     ErrInvalid,
+		},
+		{
+			name:    "failure: schedule command with undefined flag",
+			args:    []string{Schedule, ActionList, "-foo"},
+			command: Schedule,
+			err:     ErrArgumentParse,
+		},
+		{
+			name:    "failure: schedule command with invalid format flag value",
+			args:    []string{Schedule, ActionList, "-f", "xml"},
+			command: Schedule,
+			err:     ErrArgumentParse,
+		},
+		{
+			name:    "success: schedule list command with valid flags",
+			args:    []string{Schedule, ActionList, "-d", shellygen2.Driver, "-f", device.FormatJSON, "-o", "jobs.json"},
+			command: Schedule,
+			driver:  shellygen2.Driver,
+		},
+		{
+			name:    "success: schedule apply command with valid flags",
+			args:    []string{Schedule, ActionApply, "-c", "schedule.json", "--prune"},
+			command: Schedule,
+			driver:  device.AllDrivers,
+		},
+		{
+			name:    "success: schedule clear command",
+			args:    []string{Schedule, ActionClear},
+			command: Schedule,
+			driver:  device.AllDrivers,
+		},
+		{
+			name:    "success: schedule command with help flag",
+			args:    []string{Schedule, "-h"},
+			command: Schedule,
+			err:     flag.ErrHelp,
+		},
+
+		// Webhooks
+		{
+			name:    "failure: webhooks command without action",
+			args:    []string{Webhooks},
+			command: Webhooks,
+			err:     ErrInvalid,
+		},
+		{
+			name:    "failure: webhooks command with unsupported action",
+			args:    []string{Webhooks, ActionClear},
+			command: Webhooks,
+			err:     ErrInvalid,
+		},
+		{
+			name:    "success: webhooks list command with valid flags",
+			args:    []string{Webhooks, ActionList, "-d", shellygen1.Driver, "-f", device.FormatJSON},
+			command: Webhooks,
+			driver:  shellygen1.Driver,
+		},
+		{
+			name:    "success: webhooks apply command with valid flags",
+			args:    []string{Webhooks, ActionApply, "-c", "webhooks.json", "-v", "vars.csv", "--prune"},
+			command: Webhooks,
+			driver:  device.AllDrivers,
+		},
+		{
+			name:    "success: webhooks command with help flag",
+			args:    []string{Webhooks, "-h"},
+			command: Webhooks,
+			err:     flag.ErrHelp,
+		},
+
+		// KVS
+		{
+			name:    "failure: kvs command without action",
+			args:    []string{KVS},
+			command: KVS,
+			err:     ErrInvalid,
+		},
+		{
+			name:    "failure: kvs get command without keys",
+			args:    []string{KVS, ActionGet},
+			command: KVS,
+			err:     ErrFlagMissing,
+		},
+		{
+			name:    "failure: kvs delete command with blank keys",
+			args:    []string{KVS, ActionDelete, "-k", " , "},
+			command: KVS,
+			err:     ErrFlagMissing,
+		},
+		{
+			name:    "success: kvs list command with valid flags",
+			args:    []string{KVS, ActionList, "-f", device.FormatJSON, "-o", "kvs.json"},
+			command: KVS,
+			driver:  device.AllDrivers,
+		},
+		{
+			name:    "success: kvs get command with valid flags",
+			args:    []string{KVS, ActionGet, "-d", shellygen2.Driver, "-k", "room,threshold"},
+			command: KVS,
+			driver:  shellygen2.Driver,
+		},
+		{
+			name:    "success: kvs set command with valid flags",
+			args:    []string{KVS, ActionSet, "-c", "kvs.json", "-v", "vars.csv"},
+			command: KVS,
+			driver:  device.AllDrivers,
+		},
+		{
+			name:    "success: kvs command with help flag",
+			args:    []string{KVS, "-h"},
+			command: KVS,
+			err:     flag.ErrHelp,
+		},
+
+		// Virtual
+		{
+			name:    "failure: virtual command without action",
+			args:    []string{Virtual},
+			command: Virtual,
+			err:     ErrInvalid,
+		},
+		{
+			name:    "failure: virtual command with unsupported action",
+			args:    []string{Virtual, ActionDelete},
+			command: Virtual,
+			err:     ErrInvalid,
+		},
+		{
+			name:    "success: virtual list command with valid flags",
+			args:    []string{Virtual, ActionList, "-f", device.FormatJSON, "-o", "virtual.json"},
+			command: Virtual,
+			driver:  device.AllDrivers,
+		},
+		{
+			name:    "success: virtual apply command with valid flags",
+			args:    []string{Virtual, ActionApply, "-d", shellygen2.Driver, "-c", "virtual.json", "-v", "vars.csv", "--prune"},
+			command: Virtual,
+			driver:  shellygen2.Driver,
+		},
+		{
+			name:    "success: virtual command with help flag",
+			args:    []string{Virtual, "-h"},
+			command: Virtual,
+			err:     flag.ErrHelp,
+		},
+
+		// Scripts
+		{
+			name:    "failure: scripts command without action",
+			args:    []string{Scripts},
+			command: Scripts,
+			err:     ErrInvalid,
+		},
+		{
+			name:    "failure: scripts stop command without names",
+			args:    []string{Scripts, ActionStop},
+			command: Scripts,
+			err:     ErrFlagMissing,
+		},
+		{
+			name:    "success: scripts list command with valid flags",
+			args:    []string{Scripts, ActionList, "-f", device.FormatJSON, "-o", "scripts.json"},
+			command: Scripts,
+			driver:  device.AllDrivers,
+		},
+		{
+			name:    "success: scripts remove command with valid flags",
+			args:    []string{Scripts, ActionRemove, "-d", shellygen2.Driver, "-n", "announce.js,helper"},
+			command: Scripts,
+			driver:  shellygen2.Driver,
+		},
+		{
+			name:    "success: scripts pull command without names",
+			args:    []string{Scripts, ActionPull, "-p", "fleet"},
+			command: Scripts,
+			driver:  device.AllDrivers,
+		},
+		{
+			name:    "success: scripts status command with valid flags",
+			args:    []string{Scripts, ActionStatus, "-d", shellygen2.Driver, "-c", "deployment.json", "-f", device.FormatJSON},
+			command: Scripts,
+			driver:  shellygen2.Driver,
+		},
+		{
+			name:    "success: scripts lint command with valid flags",
+			args:    []string{Scripts, ActionLint, "-d", shellygen2.Driver, "-c", "deployment.json", "-prune"},
+			command: Scripts,
+			driver:  shellygen2.Driver,
+		},
+		{
+			name:    "success: scripts command with help flag",
+			args:    []string{Scripts, "-h"},
+			command: Scripts,
+			err:     flag.ErrHelp,
+		},
+
+		// Validate
+		{
+			name:    "failure: validate command with undefined flag",
+			args:    []string{Validate, "-foo"},
+			command: Validate,
+			err:     ErrArgumentParse,
+		},
+		{
+			name:    "failure: validate command with invalid kind flag value",
+			args:    []string{Validate, "-k", "foo"},
+			command: Validate,
+			err:     ErrArgumentParse,
+		},
+		{
+			name:    "success: validate command with valid flags",
+			args:    []string{Validate, "-d", shellygen2.Driver, "-k", device.SchemaConfig, "-c", "config.json"},
+			command: Validate,
+			driver:  shellygen2.Driver,
+		},
+		{
+			name:    "success: validate command with help flag",
+			args:    []string{Validate, "-h"},
+			command: Validate,
+			err:     flag.ErrHelp,
+		},
 	}
 
 	for _, test := range tests {
@@ -1,20 +1,26 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json/v2"
 	"errors"
 	"io"
 	"io/fs"
-	"log"
 	"os"
 	"path/filepath"
 
 	"github.com/quetzyg/IoTap/device"
 )
 
+// files define the default filenames for the IoTap configuration, in order of precedence.
+var files = []string{
+	"iotap.json",
+	"iotap.yaml",
+	"iotap.yml",
+	"iotap.toml",
+}
+
 const (
-	// file defines the default filename for the IoTap configuration.
-	file = "iotap.json"
 
 	// ENV variable names
 	iotapUsername = "IOTAP_USERNAME"
@@ -59,6 +65,7 @@ func LoadFromEnv() (*Values, error) {
 }
 
 // LoadFromConfigDir creates a new *Values instance from a user config directory file.
+// The file can be in the JSON, YAML or TOML format, with the first existing one being used.
 // It returns an error if the file cannot be opened or contains invalid data.
 func LoadFromConfigDir() (*Values, error) {
 	dir, err := os.UserConfigDir()
@@ -66,24 +73,25 @@ func LoadFromConfigDir() (*Values, error) {
 		return nil, fs.ErrNotExist
 	}
 
-	f, err := os.Open(filepath.Join(dir, file))
-	if err != nil {
-		return nil, err
-	}
+	for _, file := range files {
+		data, err := device.ReadFile(filepath.Join(dir, file))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
 
-	defer func() {
-		err = f.Close()
 		if err != nil {
-			log.Printf("Values close error: %v", err)
+			return nil, err
 		}
-	}()
 
-	return NewValues(f)
+		return NewValues(bytes.NewReader(data))
+	}
+
+	return nil, fs.ErrNotExist
 }
 
 // LoadValues creates a new *Values instance from two sources, with order of precedence:
 // 1. Environment variables (IOTAP_*)
-// 2. Configuration file at default location (~/.config/iotap.json)
+// 2. Configuration file at default location (~/.config/iotap.json, or its YAML and TOML counterparts)
 func LoadValues() (*Values, error) {
 	val, err := LoadFromEnv()
 	if err == nil {
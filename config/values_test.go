@@ -142,6 +142,10 @@ func TestLoadFromConfigDir(t *testing.T) {
 			name: "success",
 			dir:  absoluteTestHome(t),
 		},
+		{
+			name: "success: YAML",
+			dir:  filepath.Join(absoluteTestHome(t), "yaml"),
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,134 @@
+package device
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ScriptBudget holds the script limits of an IoT device model.
+type ScriptBudget struct {
+	MaxScripts int // Number of scripts a device can hold
+	MaxSize    int // Size of a single script, in bytes
+}
+
+// ScriptBudgeter is an interface that provides a standard way to get the script limits of IoT devices.
+type ScriptBudgeter interface {
+	ScriptBudget() *ScriptBudget
+}
+
+// Fit checks the Deployment against a script budget, given the scripts installed on a device,
+// returning the reasons it doesn't fit. Installed scripts outside the Deployment count towards
+// the device limit, unless they're pruned. Script sizes include the version marker.
+func (d *Deployment) Fit(budget *ScriptBudget, installed []*InstalledScript, prune bool) []string {
+	var issues []string
+
+	count := len(d.Scripts)
+
+	if !prune {
+		for _, s := range installed {
+			if !slices.ContainsFunc(d.Scripts, func(src *Script) bool { return src.Name() == s.Name }) {
+				count++
+			}
+		}
+	}
+
+	if count > budget.MaxScripts {
+		issues = append(issues, fmt.Sprintf("%d scripts needed, the device holds up to %d", count, budget.MaxScripts))
+	}
+
+	for _, s := range d.Scripts {
+		if size := len(s.Marked(time.Time{})); size > budget.MaxSize {
+			issues = append(issues, fmt.Sprintf("%s is %d bytes, the device holds up to %d", s.Name(), size, budget.MaxSize))
+		}
+	}
+
+	return issues
+}
+
+// checkBudget returns an error if the Deployment doesn't fit the script budget of an IoT device.
+// Devices without a known script budget are assumed to fit.
+func checkBudget(client *http.Client, res Resource, dep *Deployment, prune bool) error {
+	dev, ok := res.(ScriptBudgeter)
+	if !ok {
+		return nil
+	}
+
+	sc, ok := res.(Scripter)
+	if !ok {
+		return nil
+	}
+
+	installed, err := sc.InstalledScripts(client)
+	if err != nil {
+		return err
+	}
+
+	if issues := dep.Fit(dev.ScriptBudget(), installed, prune); len(issues) > 0 {
+		return fmt.Errorf("%w: %s", ErrScriptBudget, strings.Join(issues, "; "))
+	}
+
+	return nil
+}
+
+// LintScripts is a procedure implementation designed to add the reasons the Deployment doesn't fit
+// the script budget of an IoT device to a Report. Devices that fit aren't reported.
+// Templated scripts are rendered for each device beforehand.
+var LintScripts = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(ScriptBudgeter)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: scripts", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	sc, ok := res.(Scripter)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: scripts", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	// Check if a deployment policy is set and enforce it
+	if tap.deployment.Policy != nil && tap.deployment.Policy.IsExcluded(res) {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: ErrPolicyExcluded,
+		}
+		return
+	}
+
+	dep, err := tap.deployment.render(NewTemplateData(res, tap.vars))
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	installed, err := sc.InstalledScripts(&http.Client{
+		Transport: tap.transport,
+	})
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	for _, issue := range dep.Fit(dev.ScriptBudget(), installed, tap.prune) {
+		tap.report.Add(res, issue)
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+	}
+}
@@ -0,0 +1,187 @@
+package device
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type budgeter struct {
+	scripter
+}
+
+func (b *budgeter) ScriptBudget() *ScriptBudget {
+	return &ScriptBudget{
+		MaxScripts: 2,
+		MaxSize:    200,
+	}
+}
+
+func TestDeployment_Fit(t *testing.T) {
+	small := &Script{path: "../testdata/announce.js", code: []byte(`print("announce");`)}
+	large := &Script{path: "../testdata/large.js", code: []byte(strings.Repeat("x", 100))}
+	foo := &Script{path: "../testdata/foo.js", code: []byte(`print("foo");`)}
+
+	installed := []*InstalledScript{
+		{Name: "announce.js", ID: 1},
+		{Name: "other.js", ID: 2},
+	}
+
+	tests := []struct {
+		name    string
+		scripts []*Script
+		issues  []string
+		prune   bool
+	}{
+		{
+			name:    "fits",
+			scripts: []*Script{small},
+		},
+		{
+			name:    "too many scripts",
+			scripts: []*Script{small, foo},
+			issues:  []string{"3 scripts needed, the device holds up to 2"},
+		},
+		{
+			name:    "too many scripts, pruned",
+			scripts: []*Script{small, foo},
+			prune:   true,
+		},
+		{
+			name:    "script too large",
+			scripts: []*Script{large},
+			issues:  []string{"large.js is 228 bytes, the device holds up to 200"},
+			prune:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dep := &Deployment{Scripts: test.scripts}
+
+			issues := dep.Fit(&ScriptBudget{MaxScripts: 2, MaxSize: 200}, installed, test.prune)
+			if !reflect.DeepEqual(issues, test.issues) {
+				t.Fatalf("expected %q, got %q", test.issues, issues)
+			}
+		})
+	}
+}
+
+func TestCheckBudget(t *testing.T) {
+	dep := &Deployment{
+		Scripts: []*Script{
+			{path: "../testdata/foo.js", code: []byte(`print("foo");`)},
+		},
+	}
+
+	tests := []struct {
+		dev   Resource
+		err   error
+		name  string
+		prune bool
+	}{
+		{
+			name: "unknown budget",
+			dev:  &scripter{},
+		},
+		{
+			name: "failure: function error",
+			dev:  &budgeter{scripter{funcError: ErrUnexpected}},
+			err:  ErrUnexpected,
+		},
+		{
+			name: "failure: budget exceeded",
+			dev:  &budgeter{},
+			err:  ErrScriptBudget,
+		},
+		{
+			name:  "success: pruned",
+			dev:   &budgeter{},
+			prune: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkBudget(&http.Client{}, test.dev, dep, test.prune)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestLintScripts(t *testing.T) {
+	dep := &Deployment{
+		Scripts: []*Script{
+			{path: "../testdata/foo.js", code: []byte(`print("foo");`)},
+		},
+	}
+
+	tests := []struct {
+		dev  Resource
+		dep  *Deployment
+		err  error
+		name string
+		rows [][]string
+	}{
+		{
+			name: "failure: unsupported procedure",
+			dev:  &scripter{},
+			dep:  dep,
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: policy exclusion",
+			dev:  &budgeter{},
+			dep: &Deployment{
+				Policy: &Policy{
+					Mode: PolicyModeWhitelist,
+				},
+			},
+			err: ErrPolicyExcluded,
+		},
+		{
+			name: "failure: function error",
+			dev:  &budgeter{scripter{funcError: ErrUnexpected}},
+			dep:  dep,
+			err:  ErrUnexpected,
+		},
+		{
+			name: "success",
+			dev:  &budgeter{},
+			dep:  dep,
+			rows: [][]string{
+				{"3 scripts needed, the device holds up to 2"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tap := &Tapper{
+				deployment: test.dep,
+				report:     NewReport(),
+			}
+
+			ch := make(chan *ProcedureResult, 1)
+
+			LintScripts(tap, test.dev, ch)
+
+			if result := <-ch; !errors.Is(result.err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, result.err)
+			}
+
+			var rows [][]string
+			for _, row := range tap.report.rows {
+				rows = append(rows, row.values[len(reportHeader):])
+			}
+
+			if !reflect.DeepEqual(rows, test.rows) {
+				t.Fatalf("expected %q, got %q", test.rows, rows)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package device
+
+import (
+	"encoding/json/v2"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// RPC holds a method to call on IoT devices, along with its parameters.
+// Parameters can be of any JSON type, with strings being templates (see RenderString).
+type RPC struct {
+	Params map[string]any
+	Method string
+}
+
+// render returns a copy of the parameters, with their templates rendered for a device.
+func (rpc *RPC) render(data *TemplateData) (map[string]any, error) {
+	params, err := render(reflect.ValueOf(rpc.Params), data)
+	if err != nil {
+		return nil, err
+	}
+
+	return params.Interface().(map[string]any), nil
+}
+
+// NewRPC creates a new *RPC instance, parsing its parameters from a JSON object, if any.
+// It returns an error if the method is empty, or if the parameters are invalid or cannot be parsed.
+func NewRPC(method, params string) (*RPC, error) {
+	method = strings.TrimSpace(method)
+	if method == "" {
+		return nil, ErrRPCMethodEmpty
+	}
+
+	rpc := &RPC{
+		Method: method,
+	}
+
+	if strings.TrimSpace(params) == "" {
+		return rpc, nil
+	}
+
+	if err := json.Unmarshal([]byte(params), &rpc.Params); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRPCParams, err)
+	}
+
+	return rpc, nil
+}
+
+// Caller is an interface that provides a standard way to call arbitrary methods on IoT devices,
+// for anything that isn't modelled by other interfaces. The raw JSON response is returned.
+type Caller interface {
+	Call(*http.Client, string, map[string]any) ([]byte, error)
+}
+
+// Call is a procedure implementation designed to call a method on an IoT device, adding its response to a Report.
+// String parameter templates are rendered for each device, prior to the call.
+var Call = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(Caller)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: rpc", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	params, err := tap.rpc.render(NewTemplateData(res, tap.vars))
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	resp, err := dev.Call(&http.Client{
+		Transport: tap.transport,
+	}, tap.rpc.Method, params)
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	tap.report.Add(res, tap.rpc.Method, string(resp))
+
+	ch <- &ProcedureResult{
+		dev: res,
+	}
+}
@@ -0,0 +1,158 @@
+package device
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+	"text/template"
+)
+
+type caller struct {
+	funcError error
+	params    map[string]any
+	resource
+}
+
+func (c *caller) Call(_ *http.Client, _ string, params map[string]any) ([]byte, error) {
+	if c.funcError != nil {
+		return nil, c.funcError
+	}
+
+	c.params = params
+
+	return []byte(`{"output":true}`), nil
+}
+
+func TestNewRPC(t *testing.T) {
+	tests := []struct {
+		rpc    *RPC
+		err    error
+		name   string
+		method string
+		params string
+	}{
+		{
+			name: "failure: empty method",
+			err:  ErrRPCMethodEmpty,
+		},
+		{
+			name:   "failure: params aren't an object",
+			method: "Switch.GetStatus",
+			params: `[0]`,
+			err:    ErrInvalidRPCParams,
+		},
+		{
+			name:   "success: without params",
+			method: " Shelly.GetStatus ",
+			params: " ",
+			rpc: &RPC{
+				Method: "Shelly.GetStatus",
+			},
+		},
+		{
+			name:   "success: with params",
+			method: "Switch.GetStatus",
+			params: `{"id":0}`,
+			rpc: &RPC{
+				Method: "Switch.GetStatus",
+				Params: map[string]any{"id": float64(0)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rpc, err := NewRPC(test.method, test.params)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if !reflect.DeepEqual(rpc, test.rpc) {
+				t.Fatalf("expected %#v, got %#v", test.rpc, rpc)
+			}
+		})
+	}
+}
+
+func TestCall(t *testing.T) {
+	mac := net.HardwareAddr{0xAA, 0xBB, 0xCC, 0xDD, 0xEE, 0x01}
+
+	tests := []struct {
+		dev    Resource
+		err    error
+		name   string
+		params map[string]any
+		rows   [][]string
+	}{
+		{
+			name: "failure: unsupported procedure",
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name:   "failure: template error",
+			dev:    &caller{resource: resource{mac: mac}},
+			params: map[string]any{"code": "{{ .Vars.missing }}"},
+			err:    template.ExecError{},
+		},
+		{
+			name: "failure: function error",
+			dev:  &caller{funcError: ErrUnexpected, resource: resource{mac: mac}},
+			err:  ErrUnexpected,
+		},
+		{
+			name:   "success",
+			dev:    &caller{resource: resource{mac: mac}},
+			params: map[string]any{"id": float64(0), "code": "print('{{ .Vars.room }}')"},
+			rows: [][]string{
+				{"Script.Eval", `{"output":true}`},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tap := &Tapper{
+				rpc: &RPC{
+					Method: "Script.Eval",
+					Params: test.params,
+				},
+				vars:   Variables{mac.String(): {"room": "kitchen"}},
+				report: NewReport(),
+			}
+
+			ch := make(chan *ProcedureResult, 1)
+
+			Call(tap, test.dev, ch)
+
+			result := <-ch
+
+			var execError template.ExecError
+			if errors.As(test.err, &execError) {
+				if !errors.As(result.err, &execError) {
+					t.Fatalf("expected %T, got %#v", test.err, result.err)
+				}
+			} else if !errors.Is(result.err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, result.err)
+			}
+
+			var rows [][]string
+			for _, row := range tap.report.rows {
+				rows = append(rows, row.values[len(reportHeader):])
+			}
+
+			if !reflect.DeepEqual(rows, test.rows) {
+				t.Fatalf("expected %q, got %q", test.rows, rows)
+			}
+
+			if c, ok := test.dev.(*caller); ok && test.rows != nil {
+				expected := map[string]any{"id": float64(0), "code": "print('kitchen')"}
+				if !reflect.DeepEqual(c.params, expected) {
+					t.Fatalf("expected %#v, got %#v", expected, c.params)
+				}
+			}
+		})
+	}
+}
@@ -1,11 +1,11 @@
 package device
 
 import (
+	"bytes"
+	"encoding/json/jsontext"
 	"encoding/json/v2"
 	"fmt"
 	"io"
-	"log"
-	"os"
 )
 
 // Config defines the methods an IoT device configuration instance should implement.
@@ -25,11 +25,17 @@ func RegisterConfig(driver string, prov ConfigProvider) {
 }
 
 // NewConfig creates a Config instance by parsing data from the provided reader and provider function.
+// Environment variable and file references are resolved beforehand (see Interpolate).
 // It returns an error if the data is invalid or cannot be parsed.
 func NewConfig(r io.Reader, factory ConfigProvider) (Config, error) {
+	data, err := interpolateRead(r)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := factory()
 
-	if err := json.UnmarshalRead(r, &cfg); err != nil {
+	if err = json.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
 
@@ -37,14 +43,73 @@ func NewConfig(r io.Reader, factory ConfigProvider) (Config, error) {
 		return nil, ErrConfigurationEmpty
 	}
 
+	if err = validateOverrides(cfg); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
+// MultiConfig holds a Config for each driver, so that a single configuration
+// can be applied to devices of different drivers at once.
+type MultiConfig map[string]Config
+
+// Driver name of this Config implementation.
+func (mc MultiConfig) Driver() string {
+	return AllDrivers
+}
+
+// Empty checks if the MultiConfig holds no driver configurations.
+func (mc MultiConfig) Empty() bool {
+	return len(mc) == 0
+}
+
+// For returns the Config of the given driver section, and whether it exists.
+func (mc MultiConfig) For(driver string) (Config, bool) {
+	cfg, ok := mc[driver]
+
+	return cfg, ok
+}
+
+// NewMultiConfig creates a MultiConfig instance by parsing data from the provided reader.
+// Each top-level key must be a registered driver name, holding the configuration for that driver.
+// It returns an error if the data is invalid or cannot be parsed.
+func NewMultiConfig(r io.Reader) (MultiConfig, error) {
+	var sections map[string]jsontext.Value
+	if err := json.UnmarshalRead(r, &sections); err != nil {
+		return nil, err
+	}
+
+	mc := make(MultiConfig, len(sections))
+
+	for driver, data := range sections {
+		factory, ok := configRegistry[driver]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, driver)
+		}
+
+		cfg, err := NewConfig(bytes.NewReader(data), factory)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", driver, err)
+		}
+
+		mc[driver] = cfg
+	}
+
+	if mc.Empty() {
+		return nil, ErrConfigurationEmpty
+	}
+
+	return mc, nil
+}
+
 // LoadConfig creates a new Config instance from a driver name and a file at the given path.
+// When targeting all drivers, the file is expected to hold a section for each driver (see MultiConfig).
+// Base configuration files listed under the "extends" key are merged beforehand (see ReadFile).
 // It returns an error if the file cannot be opened or contains invalid data.
 func LoadConfig(driver, fp string) (Config, error) {
 	factory, ok := configRegistry[driver]
-	if !ok {
+	if !ok && driver != AllDrivers {
 		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, driver)
 	}
 
@@ -52,17 +117,14 @@ func LoadConfig(driver, fp string) (Config, error) {
 		return nil, ErrFilePathEmpty
 	}
 
-	f, err := os.Open(fp)
+	data, err := ReadFile(fp)
 	if err != nil {
 		return nil, err
 	}
 
-	defer func() {
-		err = f.Close()
-		if err != nil {
-			log.Printf("Config close error: %v", err)
-		}
-	}()
+	if driver == AllDrivers {
+		return NewMultiConfig(bytes.NewReader(data))
+	}
 
-	return NewConfig(f, factory)
+	return NewConfig(bytes.NewReader(data), factory)
 }
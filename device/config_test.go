@@ -133,6 +133,32 @@ func TestLoadConfig(t *testing.T) {
 				Foo: "bar",
 			},
 		},
+		{
+			name:   "success: YAML",
+			driver: "foo",
+			fp:     "../testdata/config.yaml",
+			cfg: &config{
+				Foo: "bar",
+			},
+		},
+		{
+			name:   "success: TOML",
+			driver: "foo",
+			fp:     "../testdata/config.toml",
+			cfg: &config{
+				Foo: "bar",
+			},
+		},
+		{
+			name:   "success: all drivers",
+			driver: AllDrivers,
+			fp:     "../testdata/multiconfig.json",
+			cfg: MultiConfig{
+				"foo": &config{
+					Foo: "bar",
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -168,3 +194,104 @@ func TestLoadConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestMultiConfig(t *testing.T) {
+	mc := MultiConfig{
+		"foo": &config{
+			Foo: "bar",
+		},
+	}
+
+	if mc.Driver() != AllDrivers {
+		t.Fatalf("expected %q, got %q", AllDrivers, mc.Driver())
+	}
+
+	if mc.Empty() {
+		t.Fatal("multi config is empty")
+	}
+
+	if _, ok := mc.For("foo"); !ok {
+		t.Fatal("expected foo configuration section")
+	}
+
+	if _, ok := mc.For("bar"); ok {
+		t.Fatal("unexpected bar configuration section")
+	}
+}
+
+func TestNewMultiConfig(t *testing.T) {
+	tests := []struct {
+		r    io.Reader
+		cfg  Config
+		err  error
+		name string
+	}{
+		{
+			name: "failure: syntactic error",
+			r:    strings.NewReader(`!`),
+			err:  &jsontext.SyntacticError{},
+		},
+		{
+			name: "failure: configuration empty",
+			r:    strings.NewReader(`{}`),
+			err:  ErrConfigurationEmpty,
+		},
+		{
+			name: "failure: unsupported driver",
+			r:    strings.NewReader(`{"bar":{"foo":"bar"}}`),
+			err:  ErrUnsupportedDriver,
+		},
+		{
+			name: "failure: driver configuration empty",
+			r:    strings.NewReader(`{"foo":{}}`),
+			err:  ErrConfigurationEmpty,
+		},
+		{
+			name: "success",
+			r:    strings.NewReader(`{"foo":{"foo":"bar"}}`),
+			cfg: MultiConfig{
+				"foo": &config{
+					Foo: "bar",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				configRegistry = make(map[string]ConfigProvider)
+			})
+
+			configRegistry["foo"] = func() Config {
+				return &config{}
+			}
+
+			cfg, err := NewMultiConfig(test.r)
+
+			if test.cfg == nil && cfg != nil {
+				t.Fatalf("expected nil, got %#v", cfg)
+			}
+
+			if test.cfg != nil && !reflect.DeepEqual(cfg, test.cfg) {
+				t.Fatalf("expected %#v, got %#v", test.cfg, cfg)
+			}
+
+			var syntacticError *jsontext.SyntacticError
+			switch {
+			case errors.As(test.err, &syntacticError):
+				var se *jsontext.SyntacticError
+				if errors.As(err, &se) {
+					return
+				}
+
+			default:
+				if errors.Is(err, test.err) {
+					return
+				}
+			}
+
+			t.Fatalf("expected %#v, got %#v", test.err, err)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package device
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/quetzyg/IoTap/httpclient"
@@ -12,7 +13,29 @@ type Configurer interface {
 	ConfigureRequests(Config) ([]*http.Request, error)
 }
 
+// rollback restores a configuration snapshot on a device, after a failed configuration attempt.
+func rollback(dev Configurer, snap Config, dispatcher *httpclient.Dispatcher, opts []httpclient.DispatchOption, cause error) error {
+	rs, err := dev.ConfigureRequests(snap)
+	if err != nil {
+		return fmt.Errorf("%w: %w (%w)", ErrRollbackFailed, cause, err)
+	}
+
+	for _, r := range rs {
+		if err = dispatcher.Dispatch(r, opts...); err != nil {
+			return fmt.Errorf("%w: %w (%w)", ErrRollbackFailed, cause, err)
+		}
+	}
+
+	return fmt.Errorf("%w: %w", ErrRolledBack, cause)
+}
+
 // Configure is a procedure implementation designed to apply configuration settings to an IoT device.
+// Templates in the configuration settings are rendered for each device, prior to being applied.
+// Devices implementing the Profiler interface have their profile switched first, since it determines
+// which components are available. Profile switches aren't rolled back.
+// Devices implementing the Snapshotter interface have their affected components captured beforehand,
+// so that a configuration sequence failing mid-way can be rolled back. Failing to dispatch the trailing
+// reboot request isn't rolled back, since every setting has been applied by then.
 var Configure = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
 	dev, ok := res.(Configurer)
 	if !ok {
@@ -23,7 +46,16 @@ var Configure = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
 		return
 	}
 
-	rs, err := dev.ConfigureRequests(tap.config)
+	cfg, err := tap.configFor(res)
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	rs, err := dev.ConfigureRequests(cfg)
 	if err != nil {
 		ch <- &ProcedureResult{
 			dev: res,
@@ -32,9 +64,11 @@ var Configure = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
 		return
 	}
 
-	dispatcher := httpclient.NewDispatcher(&http.Client{
+	client := &http.Client{
 		Transport: tap.transport,
-	})
+	}
+
+	dispatcher := httpclient.NewDispatcher(client)
 
 	var opts []httpclient.DispatchOption
 
@@ -42,8 +76,48 @@ var Configure = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
 		opts = append(opts, httpclient.WithChallenger(challenger))
 	}
 
-	for _, r := range rs {
+	if profiler, ok := res.(Profiler); ok {
+		if err = switchProfile(profiler, cfg, dispatcher, opts); err != nil {
+			ch <- &ProcedureResult{
+				dev: res,
+				err: err,
+			}
+			return
+		}
+	}
+
+	var snap Config
+
+	if snapper, ok := res.(Snapshotter); ok && len(rs) > 0 {
+		snap, err = snapper.Snapshot(client, cfg)
+		if err != nil {
+			ch <- &ProcedureResult{
+				dev: res,
+				err: err,
+			}
+			return
+		}
+
+		if tap.snapshotDir != "" {
+			fp, err := SaveSnapshot(tap.snapshotDir, res, snap)
+			if err != nil {
+				ch <- &ProcedureResult{
+					dev: res,
+					err: err,
+				}
+				return
+			}
+
+			log.Printf("Configuration snapshot saved: %s", fp)
+		}
+	}
+
+	for i, r := range rs {
 		if err = dispatcher.Dispatch(r, opts...); err != nil {
+			if snap != nil && !snap.Empty() && i < len(rs)-1 {
+				err = rollback(dev, snap, dispatcher, opts, err)
+			}
+
 			ch <- &ProcedureResult{
 				dev: res,
 				err: err,
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"text/template"
 )
 
 type configurer struct {
@@ -19,11 +20,16 @@ func (c *configurer) ConfigureRequests(Config) ([]*http.Request, error) {
 		return nil, c.funcError
 	}
 
+	// Settings request, followed by the reboot request
 	return []*http.Request{
 		{
 			URL:    &url.URL{},
 			Method: http.MethodGet,
 		},
+		{
+			URL:    &url.URL{},
+			Method: http.MethodGet,
+		},
 	}, nil
 }
 
@@ -39,9 +45,39 @@ func (cc *configChallenger) ChallengeResponse(r *http.Request, _ *http.Response)
 	return r, nil
 }
 
+type configSnapshotter struct {
+	funcError error
+	configurer
+}
+
+func (cs *configSnapshotter) Snapshot(*http.Client, Config) (Config, error) {
+	if cs.funcError != nil {
+		return nil, cs.funcError
+	}
+
+	return &config{Foo: "baz"}, nil
+}
+
+// sequenceRoundTripper is a custom type used for mocking a sequence of HTTP responses.
+type sequenceRoundTripper struct {
+	steps []*roundTripper
+	count int
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (srt *sequenceRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	step := srt.steps[srt.count]
+	srt.count++
+
+	return step.RoundTrip(r)
+}
+
 func TestConfigure(t *testing.T) {
+	invalid := "{{ .Foo }}"
+
 	tests := []struct {
 		rt   http.RoundTripper
+		cfg  Config
 		dev  Resource
 		err  error
 		name string
@@ -51,6 +87,14 @@ func TestConfigure(t *testing.T) {
 			dev:  &resource{},
 			err:  ErrUnsupportedProcedure,
 		},
+		{
+			name: "failure: template error",
+			cfg: &templateConfig{
+				Name: &invalid,
+			},
+			dev: &configurer{},
+			err: template.ExecError{},
+		},
 		{
 			name: "failure: function error",
 			dev: &configurer{
@@ -76,6 +120,80 @@ func TestConfigure(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "failure: profile switch error",
+			dev: &profiler{
+				funcError: ErrUnexpected,
+			},
+			err: ErrUnexpected,
+		},
+		{
+			name: "failure: snapshot error",
+			dev: &configSnapshotter{
+				funcError: ErrUnexpected,
+			},
+			err: ErrUnexpected,
+		},
+		{
+			name: "failure: configuration rolled back",
+			dev:  &configSnapshotter{},
+			rt: &sequenceRoundTripper{
+				steps: []*roundTripper{
+					{
+						err: &url.Error{},
+					},
+					{
+						response: &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader("{}")),
+						},
+					},
+					{
+						response: &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader("{}")),
+						},
+					},
+				},
+			},
+			err: ErrRolledBack,
+		},
+		{
+			name: "failure: reboot not rolled back",
+			dev:  &configSnapshotter{},
+			rt: &sequenceRoundTripper{
+				steps: []*roundTripper{
+					{
+						response: &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader("{}")),
+						},
+					},
+					{
+						err: &url.Error{},
+					},
+				},
+			},
+			err: &url.Error{},
+		},
+		{
+			name: "failure: configuration rollback failed",
+			dev:  &configSnapshotter{},
+			rt: &roundTripper{
+				err: &url.Error{},
+			},
+			err: ErrRollbackFailed,
+		},
+		{
+			name: "success: configuration snapshot saved",
+			dev:  &configSnapshotter{},
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("{}")),
+				},
+			},
+		},
 		{
 			name: "success: challenger implementation",
 			dev:  &configChallenger{},
@@ -91,7 +209,9 @@ func TestConfigure(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			tap := &Tapper{
-				transport: test.rt,
+				config:      test.cfg,
+				transport:   test.rt,
+				snapshotDir: t.TempDir(),
 			}
 
 			ch := make(chan *ProcedureResult, 1)
@@ -100,7 +220,15 @@ func TestConfigure(t *testing.T) {
 
 			result := <-ch
 
-			var urlError *url.Error
+			if errors.Is(result.err, ErrRolledBack) && !errors.Is(test.err, ErrRolledBack) {
+				t.Fatalf("expected %#v, got %#v", test.err, result.err)
+			}
+
+			var (
+				urlError  *url.Error
+				execError template.ExecError
+			)
+
 			switch {
 			case errors.As(test.err, &urlError):
 				var ue *url.Error
@@ -108,6 +236,12 @@ func TestConfigure(t *testing.T) {
 					return
 				}
 
+			case errors.As(test.err, &execError):
+				var ee template.ExecError
+				if errors.As(result.err, &ee) {
+					return
+				}
+
 			case errors.Is(result.err, test.err):
 				return
 
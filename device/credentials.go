@@ -1,10 +1,9 @@
 package device
 
 import (
+	"bytes"
 	"encoding/json/v2"
 	"io"
-	"log"
-	"os"
 )
 
 // Credentials to interact with secured IoT devices.
@@ -20,10 +19,16 @@ type AuthConfig struct {
 }
 
 // NewAuthConfig creates a new *AuthConfig instance by parsing data from the provided reader.
+// Environment variable and file references are resolved beforehand (see Interpolate).
 // It returns an error if the data is invalid or cannot be parsed.
 func NewAuthConfig(r io.Reader) (*AuthConfig, error) {
+	data, err := interpolateRead(r)
+	if err != nil {
+		return nil, err
+	}
+
 	var auth *AuthConfig
-	if err := json.UnmarshalRead(r, &auth); err != nil {
+	if err = json.Unmarshal(data, &auth); err != nil {
 		return nil, err
 	}
 
@@ -46,17 +51,10 @@ func LoadAuthConfig(fp string) (*AuthConfig, error) {
 		return nil, ErrFilePathEmpty
 	}
 
-	f, err := os.Open(fp)
+	data, err := ReadFile(fp)
 	if err != nil {
 		return nil, err
 	}
 
-	defer func() {
-		err = f.Close()
-		if err != nil {
-			log.Printf("Auth configuration close error: %v", err)
-		}
-	}()
-
-	return NewAuthConfig(f)
+	return NewAuthConfig(bytes.NewReader(data))
 }
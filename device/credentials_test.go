@@ -32,6 +32,19 @@ func TestNewAuthConfig(t *testing.T) {
 			r:    strings.NewReader(`{"credentials":{"username":"foo"}}`),
 			err:  ErrMissingCredentials,
 		},
+		{
+			name: "failure: undefined variable",
+			r:    strings.NewReader(`{"credentials":{"password":"${IOTAP_UNDEFINED}"}}`),
+			err:  ErrUndefinedVariable,
+		},
+		{
+			name: "success: interpolated password",
+			r:    strings.NewReader(`{"credentials":{"password":"${IOTAP_PASSWORD}"}}`),
+			auth: &AuthConfig{
+				Policy:      nil,
+				Credentials: &Credentials{Username: "", Password: "P@ssw0rd"},
+			},
+		},
 		{
 			name: "success: only password",
 			r:    strings.NewReader(`{"credentials":{"password":"bar"}}`),
@@ -50,6 +63,12 @@ func TestNewAuthConfig(t *testing.T) {
 		},
 	}
 
+	t.Setenv("IOTAP_PASSWORD", "P@ssw0rd")
+
+	t.Cleanup(func() {
+		secrets.values = make(map[string]struct{})
+	})
+
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			auth, err := NewAuthConfig(test.r)
@@ -102,6 +121,14 @@ func TestLoadAuthConfig(t *testing.T) {
 				Credentials: &Credentials{Username: "admin", Password: "secret"},
 			},
 		},
+		{
+			name: "success: TOML",
+			fp:   "../testdata/authconfig.toml",
+			auth: &AuthConfig{
+				Policy:      nil,
+				Credentials: &Credentials{Username: "admin", Password: "secret"},
+			},
+		},
 	}
 
 	for _, test := range tests {
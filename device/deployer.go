@@ -2,17 +2,21 @@ package device
 
 import (
 	"fmt"
+	"log"
 	"net/http"
-
-	"github.com/quetzyg/IoTap/httpclient"
 )
 
 // Deployer is an interface that provides a standard way to deploy a script on supported IoT devices.
+// Deploy changes the device straight away where a step depends on the device response (e.g. script creation
+// and code uploads), returning the remaining requests needed to bring the device scripts up to date,
+// which remove the device scripts that aren't in the Deployment when pruning is requested.
 type Deployer interface {
-	DeployRequests(*http.Client, *Deployment) ([]*http.Request, error)
+	Deploy(*http.Client, *Deployment, bool) ([]*http.Request, error)
 }
 
 // Deploy is a procedure implementation designed to deploy a script to an IoT device.
+// Devices with up-to-date scripts are logged and skipped, while devices that can't fit the Deployment
+// fail before any script is changed. Templated scripts are rendered for each device beforehand.
 var Deploy = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
 	dev, ok := res.(Deployer)
 	if !ok {
@@ -23,11 +27,16 @@ var Deploy = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
 		return
 	}
 
-	client := &http.Client{
-		Transport: tap.transport,
+	// Check if a deployment policy is set and enforce it, before rendering scripts for excluded devices
+	if tap.deployment.Policy != nil && tap.deployment.Policy.IsExcluded(res) {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: ErrPolicyExcluded,
+		}
+		return
 	}
 
-	rs, err := dev.DeployRequests(client, tap.deployment)
+	dep, err := tap.deployment.render(NewTemplateData(res, tap.vars))
 	if err != nil {
 		ch <- &ProcedureResult{
 			dev: res,
@@ -36,27 +45,39 @@ var Deploy = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
 		return
 	}
 
-	dispatcher := httpclient.NewDispatcher(&http.Client{
+	client := &http.Client{
 		Transport: tap.transport,
-	})
+	}
 
-	var opts []httpclient.DispatchOption
+	if err = checkBudget(client, res, dep, tap.prune); err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
 
-	if challenger, ok := res.(httpclient.Challenger); ok {
-		opts = append(opts, httpclient.WithChallenger(challenger))
+	rs, err := dev.Deploy(client, dep, tap.prune)
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
 	}
 
-	for _, r := range rs {
-		if err = dispatcher.Dispatch(r, opts...); err != nil {
-			ch <- &ProcedureResult{
-				dev: res,
-				err: err,
-			}
-			return
+	if len(rs) == 0 {
+		log.Printf("[%s] %s @ %s: scripts are up to date", res.Driver(), res.ID(), res.IP())
+
+		ch <- &ProcedureResult{
+			dev: res,
+			err: ErrDeviceUnchanged,
 		}
+		return
 	}
 
 	ch <- &ProcedureResult{
 		dev: res,
+		err: dispatch(tap, res, rs),
 	}
 }
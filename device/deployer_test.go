@@ -7,18 +7,24 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"text/template"
 )
 
 type deployer struct {
 	funcError error
+	unchanged bool
 	resource
 }
 
-func (d *deployer) DeployRequests(*http.Client, *Deployment) ([]*http.Request, error) {
+func (d *deployer) Deploy(*http.Client, *Deployment, bool) ([]*http.Request, error) {
 	if d.funcError != nil {
 		return nil, d.funcError
 	}
 
+	if d.unchanged {
+		return nil, nil
+	}
+
 	return []*http.Request{
 		{
 			URL:    &url.URL{},
@@ -43,6 +49,7 @@ func TestDeploy(t *testing.T) {
 	tests := []struct {
 		rt   http.RoundTripper
 		dev  Resource
+		dep  *Deployment
 		err  error
 		name string
 	}{
@@ -51,6 +58,26 @@ func TestDeploy(t *testing.T) {
 			dev:  &resource{},
 			err:  ErrUnsupportedProcedure,
 		},
+		{
+			name: "failure: policy exclusion",
+			dev:  &deployer{},
+			dep: &Deployment{
+				Policy: &Policy{
+					Mode: PolicyModeWhitelist,
+				},
+			},
+			err: ErrPolicyExcluded,
+		},
+		{
+			name: "failure: template error",
+			dev:  &deployer{},
+			dep: &Deployment{
+				Scripts: []*Script{
+					{path: "../testdata/foo.js", code: []byte(`let ip = "{{.Vars.ip}}";`), template: true},
+				},
+			},
+			err: template.ExecError{},
+		},
 		{
 			name: "failure: function error",
 			dev: &deployer{
@@ -66,6 +93,13 @@ func TestDeploy(t *testing.T) {
 			},
 			err: &url.Error{},
 		},
+		{
+			name: "failure: device unchanged",
+			dev: &deployer{
+				unchanged: true,
+			},
+			err: ErrDeviceUnchanged,
+		},
 		{
 			name: "success",
 			dev:  &deployer{},
@@ -90,8 +124,13 @@ func TestDeploy(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			if test.dep == nil {
+				test.dep = &Deployment{}
+			}
+
 			tap := &Tapper{
-				transport: test.rt,
+				transport:  test.rt,
+				deployment: test.dep,
 			}
 
 			ch := make(chan *ProcedureResult, 1)
@@ -100,7 +139,11 @@ func TestDeploy(t *testing.T) {
 
 			result := <-ch
 
-			var urlError *url.Error
+			var (
+				urlError  *url.Error
+				execError template.ExecError
+			)
+
 			switch {
 			case errors.As(test.err, &urlError):
 				var ue *url.Error
@@ -108,6 +151,12 @@ func TestDeploy(t *testing.T) {
 					return
 				}
 
+			case errors.As(test.err, &execError):
+				var ee template.ExecError
+				if errors.As(result.err, &ee) {
+					return
+				}
+
 			case errors.Is(result.err, test.err):
 				return
 
@@ -1,14 +1,40 @@
 package device
 
 import (
+	"bytes"
+	"cmp"
+	"encoding/json/jsontext"
 	"encoding/json/v2"
 	"fmt"
 	"io"
-	"log"
-	"os"
+	"slices"
+	"text/template"
 )
 
+// scriptEntry is a deployment manifest entry, which can also be given as a plain script file path.
+type scriptEntry struct {
+	Enable   *bool          `json:"enable,omitempty"`
+	Start    *bool          `json:"start,omitempty"`
+	KVS      map[string]any `json:"kvs,omitempty"`
+	Path     string         `json:"path"`
+	Name     string         `json:"name,omitempty"`
+	Order    int            `json:"order,omitzero"`
+	Template bool           `json:"template,omitzero"`
+}
+
+// UnmarshalJSON implements the Unmarshaler interface.
+func (se *scriptEntry) UnmarshalJSON(data []byte) error {
+	if jsontext.Value(data).Kind() == '"' {
+		return json.Unmarshal(data, &se.Path)
+	}
+
+	type entry scriptEntry
+
+	return json.Unmarshal(data, (*entry)(se))
+}
+
 // Deployment holds a policy to enforce when deploying scripts to one or more IoT devices.
+// Scripts are kept in deployment order.
 type Deployment struct {
 	Policy  *Policy   `json:"policy,omitempty"`
 	Scripts []*Script `json:"scripts"`
@@ -17,8 +43,8 @@ type Deployment struct {
 // UnmarshalJSON implements the Unmarshaler interface.
 func (d *Deployment) UnmarshalJSON(data []byte) error {
 	var tmp struct {
-		Policy  *Policy  `json:"policy,omitempty"`
-		Scripts []string `json:"scripts"`
+		Policy  *Policy        `json:"policy,omitempty"`
+		Scripts []*scriptEntry `json:"scripts"`
 	}
 
 	err := json.Unmarshal(data, &tmp)
@@ -26,10 +52,69 @@ func (d *Deployment) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	fps := make([]string, 0, len(tmp.Scripts))
+	for _, entry := range tmp.Scripts {
+		fps = append(fps, entry.Path)
+	}
+
+	scripts, err := LoadScripts(fps)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(scripts))
+
+	for i, src := range scripts {
+		entry := tmp.Scripts[i]
+
+		src.name = entry.Name
+		src.enable = entry.Enable
+		src.start = entry.Start
+		src.kvs = entry.KVS
+		src.order = entry.Order
+		src.template = entry.Template
+
+		// Templates are parsed straight away, so syntax errors surface before any device is scanned
+		if src.template {
+			if _, err = template.New(src.Name()).Parse(string(src.code)); err != nil {
+				return err
+			}
+		}
+
+		if names[src.Name()] {
+			return fmt.Errorf("%w: %s", ErrScriptDuplicate, src.Name())
+		}
+
+		names[src.Name()] = true
+	}
+
+	slices.SortStableFunc(scripts, func(a, b *Script) int {
+		return cmp.Compare(a.order, b.order)
+	})
+
 	d.Policy = tmp.Policy
-	d.Scripts, err = LoadScripts(tmp.Scripts)
+	d.Scripts = scripts
 
-	return err
+	return nil
+}
+
+// render returns a copy of the Deployment, with its templated scripts rendered for a device.
+func (d *Deployment) render(data *TemplateData) (*Deployment, error) {
+	scripts := make([]*Script, len(d.Scripts))
+
+	for i, s := range d.Scripts {
+		var err error
+
+		scripts[i], err = s.render(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Deployment{
+		Policy:  d.Policy,
+		Scripts: scripts,
+	}, nil
 }
 
 var deployerRegistry = make(map[string]struct{})
@@ -40,38 +125,44 @@ func RegisterDeployer(driver string) {
 }
 
 // NewDeployment creates a new *Deployment instance by parsing data from the provided reader.
+// Environment variable and file references are resolved beforehand (see Interpolate).
 // It returns an error if the data is invalid or cannot be parsed.
 func NewDeployment(r io.Reader) (*Deployment, error) {
+	data, err := interpolateRead(r)
+	if err != nil {
+		return nil, err
+	}
+
 	var dep Deployment
-	if err := json.UnmarshalRead(r, &dep); err != nil {
+	if err = json.Unmarshal(data, &dep); err != nil {
 		return nil, err
 	}
 
 	return &dep, nil
 }
 
-// LoadDeployment creates a new *Deployment instance from a file at the given path.
-// It returns an error if the file cannot be opened or contains invalid data.
+// LoadDeployment creates a new *Deployment instance from a file at the given path, for a driver able to deploy it.
+// It returns an error if the driver isn't supported, or if the file cannot be opened or contains invalid data.
 func LoadDeployment(driver, fp string) (*Deployment, error) {
 	if _, ok := deployerRegistry[driver]; !ok {
 		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, driver)
 	}
 
+	return ReadDeployment(fp)
+}
+
+// ReadDeployment creates a new *Deployment instance from a file at the given path, regardless of the driver.
+// Base deployment files listed under the "extends" key are merged beforehand (see ReadFile).
+// It returns an error if the file cannot be opened or contains invalid data.
+func ReadDeployment(fp string) (*Deployment, error) {
 	if fp == "" {
 		return nil, ErrFilePathEmpty
 	}
 
-	f, err := os.Open(fp)
+	data, err := ReadFile(fp)
 	if err != nil {
 		return nil, err
 	}
 
-	defer func() {
-		err = f.Close()
-		if err != nil {
-			log.Printf("Deployment close error: %v", err)
-		}
-	}()
-
-	return NewDeployment(f)
+	return NewDeployment(bytes.NewReader(data))
 }
@@ -2,12 +2,16 @@ package device
 
 import (
 	"encoding/json/jsontext"
+	"encoding/json/v2"
 	"errors"
 	"io"
 	"io/fs"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"text/template"
 )
 
 func TestNewDeployment(t *testing.T) {
@@ -21,6 +25,16 @@ func TestNewDeployment(t *testing.T) {
 			r:    strings.NewReader(`}`),
 			err:  &jsontext.SyntacticError{},
 		},
+		{
+			name: "failure: duplicate script name",
+			r:    strings.NewReader(`{"scripts":["../testdata/script1.js",{"path":"../testdata/script2.js","name":"script1.js"}]}`),
+			err:  ErrScriptDuplicate,
+		},
+		{
+			name: "failure: invalid script entry",
+			r:    strings.NewReader(`{"scripts":[1]}`),
+			err:  &json.SemanticError{},
+		},
 		{
 			name: "success: valid deployment data",
 			r:    strings.NewReader(`{"scripts":["../testdata/script1.js"]}`),
@@ -31,6 +45,14 @@ func TestNewDeployment(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			_, err := NewDeployment(test.r)
 
+			var semanticError *json.SemanticError
+			if errors.As(test.err, &semanticError) {
+				var se *json.SemanticError
+				if errors.As(err, &se) {
+					return
+				}
+			}
+
 			var syntacticError *jsontext.SyntacticError
 			switch {
 			case errors.As(test.err, &syntacticError):
@@ -50,6 +72,129 @@ func TestNewDeployment(t *testing.T) {
 	}
 }
 
+func TestNewDeployment_ScriptOptions(t *testing.T) {
+	dep, err := NewDeployment(strings.NewReader(`{"scripts":[` +
+		`"../testdata/script1.js",` +
+		`{"path":"../testdata/script2.js","name":"helper","enable":false,"start":false,"kvs":{"delay":5},"order":-1}` +
+		`]}`))
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	var names []string
+	for _, s := range dep.Scripts {
+		names = append(names, s.Name())
+	}
+
+	if expected := []string{"helper", "script1.js"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected %q, got %q", expected, names)
+	}
+
+	helper, script1 := dep.Scripts[0], dep.Scripts[1]
+
+	if helper.Enabled() || helper.Started() || helper.Order() != -1 {
+		t.Fatalf("expected a disabled and stopped helper script, got %#v", helper)
+	}
+
+	if expected := map[string]any{"delay": float64(5)}; !reflect.DeepEqual(helper.KeyValues(), expected) {
+		t.Fatalf("expected %#v, got %#v", expected, helper.KeyValues())
+	}
+
+	if !script1.Enabled() || !script1.Started() || script1.KeyValues() != nil {
+		t.Fatalf("expected default options, got %#v", script1)
+	}
+}
+
+func TestNewDeployment_Template(t *testing.T) {
+	dep, err := NewDeployment(strings.NewReader(`{"scripts":[` +
+		`"../testdata/script1.js",` +
+		`{"path":"../testdata/template.js","template":true}` +
+		`]}`))
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	tests := []struct {
+		err      error
+		data     *TemplateData
+		name     string
+		expected string
+	}{
+		{
+			name: "failure: missing variable",
+			data: &TemplateData{
+				Vars: map[string]string{"target": "192.168.1.10"},
+			},
+			err: template.ExecError{},
+		},
+		{
+			name: "success: plus device",
+			data: &TemplateData{
+				Vars:  map[string]string{"target": "192.168.1.10", "threshold": "25"},
+				Model: "SNSW-001X16EU",
+				Name:  "porch",
+			},
+			expected: "let target = \"192.168.1.10\";\nlet threshold = 25;\nprint(\"porch relay ready\");\n",
+		},
+		{
+			name: "success: other device",
+			data: &TemplateData{
+				Vars:  map[string]string{"target": "192.168.1.11", "threshold": "30"},
+				Model: "SNPL-00112EU",
+			},
+			expected: "let target = \"192.168.1.11\";\nlet threshold = 30;\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rendered, err := dep.render(test.data)
+			if test.err != nil {
+				var execError template.ExecError
+				if !errors.As(err, &execError) {
+					t.Fatalf("expected %T, got %#v", test.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			if rendered.Scripts[0] != dep.Scripts[0] {
+				t.Fatal("expected the plain script to be left as it is")
+			}
+
+			if code := string(rendered.Scripts[1].Code()); code != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, code)
+			}
+
+			if string(dep.Scripts[1].Code()) == test.expected {
+				t.Fatal("expected the deployment script template to be left untouched")
+			}
+		})
+	}
+}
+
+func TestNewDeployment_TemplateError(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "broken.js")
+	if err := os.WriteFile(fp, []byte(`let ip = "{{ .Vars.ip";`), 0o600); err != nil {
+		t.Fatalf("unable to write script: %v", err)
+	}
+
+	entry, _ := json.Marshal(fp)
+
+	_, err := NewDeployment(strings.NewReader(`{"scripts":[{"path":` + string(entry) + `,"template":true}]}`))
+	if err == nil || !strings.Contains(err.Error(), "template: broken.js") {
+		t.Fatalf("expected template parse error, got %v", err)
+	}
+
+	// The same code is fine when it isn't a template
+	if _, err = NewDeployment(strings.NewReader(`{"scripts":[` + string(entry) + `]}`)); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
 func TestRegisterDeployer(t *testing.T) {
 	if len(deployerRegistry) != 0 {
 		t.Fatal("Deployer registry should be empty")
@@ -106,6 +251,27 @@ func TestLoadDeployment(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "success: YAML",
+			driver: "foo",
+			fp:     "../testdata/deployment.yaml",
+			dep: &Deployment{
+				Policy: &Policy{
+					Mode:   PolicyModeWhitelist,
+					Models: []string{"SHSW-1"},
+				},
+				Scripts: []*Script{
+					{
+						path: "../testdata/script1.js",
+						code: []byte(`var foo = "abc";`),
+					},
+					{
+						path: "../testdata/script2.js",
+						code: []byte(`var bar = 123;`),
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -139,3 +305,40 @@ func TestLoadDeployment(t *testing.T) {
 		})
 	}
 }
+
+func TestReadDeployment(t *testing.T) {
+	if _, err := ReadDeployment(""); !errors.Is(err, ErrFilePathEmpty) {
+		t.Fatalf("expected %#v, got %#v", ErrFilePathEmpty, err)
+	}
+
+	// Deployments are read regardless of the registered deployers
+	dep, err := ReadDeployment("../testdata/deployment.json")
+	if err != nil {
+		t.Fatalf("expected nil, got %#v", err)
+	}
+
+	if len(dep.Scripts) != 2 {
+		t.Fatalf("expected 2 scripts, got %d", len(dep.Scripts))
+	}
+
+	// Base files hold unquoted references and script paths relative to themselves
+	t.Setenv("IOTAP_ORDER", "-1")
+
+	dep, err = ReadDeployment("../testdata/extends/deployment.json")
+	if err != nil {
+		t.Fatalf("expected nil, got %#v", err)
+	}
+
+	var names []string
+	for _, src := range dep.Scripts {
+		names = append(names, src.Name())
+	}
+
+	if expected := []string{"script2.js", "script1.js"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+
+	if dep.Policy.Mode != PolicyModeBlacklist {
+		t.Fatalf("expected %v, got %v", PolicyModeBlacklist, dep.Policy.Mode)
+	}
+}
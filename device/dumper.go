@@ -1,6 +1,7 @@
 package device
 
 import (
+	"bytes"
 	"encoding/json/jsontext"
 	"encoding/json/v2"
 	"fmt"
@@ -67,35 +68,40 @@ func dumpJSON(devices Collection, w io.Writer) error {
 	return json.MarshalWrite(w, devices, jsontext.WithIndentPrefix(""), jsontext.WithIndent("  "))
 }
 
-// ExecDump is a wrapper function to easily dump device scan results to multiple formats and outputs.
-func ExecDump(devices Collection, format string, file string) error {
-	var (
-		w   io.Writer = os.Stdout
-		err error
-	)
-
+// writeOutput renders results with the CSV separator to use, writing them to STDOUT, unless a file path is provided.
+// Results are rendered in full beforehand, so nothing is written when rendering fails.
+func writeOutput(file string, render func(w io.Writer, sep string) error) error {
+	// Use the Tab separator when outputting to a screen
+	sep := "\t"
 	if file != "" {
-		w, err = os.Create(file)
-		if err != nil {
-			return err
-		}
+		sep = ","
 	}
 
-	switch format {
-	case FormatCSV:
-		sep := ","
+	var buf bytes.Buffer
+	if err := render(&buf, sep); err != nil {
+		return err
+	}
 
-		// Use the Tab separator when outputting to a screen
-		if w == os.Stdout {
-			sep = "\t"
-		}
+	if file == "" {
+		_, err := buf.WriteTo(os.Stdout)
+		return err
+	}
+
+	return os.WriteFile(file, buf.Bytes(), 0o666)
+}
 
-		return dumpCSV(devices, w, sep)
+// ExecDump is a wrapper function to easily dump device scan results to multiple formats and outputs.
+func ExecDump(devices Collection, format string, file string) error {
+	return writeOutput(file, func(w io.Writer, sep string) error {
+		switch format {
+		case FormatCSV:
+			return dumpCSV(devices, w, sep)
 
-	case FormatJSON:
-		return dumpJSON(devices, w)
+		case FormatJSON:
+			return dumpJSON(devices, w)
 
-	default:
-		return fmt.Errorf("%w: %s", ErrInvalidDumpFormat, format)
-	}
+		default:
+			return fmt.Errorf("%w: %s", ErrInvalidDumpFormat, format)
+		}
+	})
 }
@@ -24,6 +24,66 @@ var (
 	// resulted in an empty Script instance.
 	ErrScriptEmpty = errors.New("empty IoT script")
 
+	// ErrScriptDuplicate indicates that a deployment has more than one script with the same name.
+	ErrScriptDuplicate = errors.New("duplicate IoT script name")
+
+	// ErrScriptUpload indicates that a script upload failed, or that the stored script doesn't match the uploaded one.
+	ErrScriptUpload = errors.New("IoT script upload failed")
+
+	// ErrScriptLint indicates that a script uses JavaScript the device script language doesn't support.
+	ErrScriptLint = errors.New("IoT script lint failed")
+
+	// ErrScriptBudget indicates that a deployment doesn't fit the script limits of a device.
+	ErrScriptBudget = errors.New("IoT script budget exceeded")
+
+	// ErrOverrideMatchMissing indicates that a configuration override has no matching criteria.
+	ErrOverrideMatchMissing = errors.New("the override match criteria is missing")
+
+	// ErrExtendsCycle indicates that a configuration file extends itself, directly or through its base files.
+	ErrExtendsCycle = errors.New("configuration inheritance cycle")
+
+	// ErrInvalidExtends indicates that the base files of a configuration file are not a path, or a list of paths.
+	ErrInvalidExtends = errors.New("invalid configuration base file")
+
+	// ErrVariablesEmpty indicates that the loaded variables file has no data.
+	ErrVariablesEmpty = errors.New("the variables are empty")
+
+	// ErrUndefinedVariable is returned when a referenced environment variable isn't set.
+	ErrUndefinedVariable = errors.New("undefined environment variable")
+
+	// ErrUnsupportedFileFormat is returned when a file extension doesn't match any supported format.
+	ErrUnsupportedFileFormat = errors.New("unsupported file format")
+
+	// ErrInvalidJob is returned when a scheduled job has invalid values.
+	ErrInvalidJob = errors.New("invalid scheduled job")
+
+	// ErrInvalidHook is returned when a webhook has invalid values.
+	ErrInvalidHook = errors.New("invalid webhook")
+
+	// ErrKeyValuesEmpty is returned when a key-value file doesn't hold any value to set.
+	ErrKeyValuesEmpty = errors.New("key-values are empty")
+
+	// ErrInvalidComponent is returned when a virtual component has an invalid type or ID, or is declared twice.
+	ErrInvalidComponent = errors.New("invalid virtual component")
+
+	// ErrRPCMethodEmpty is returned when an RPC has no method to call.
+	ErrRPCMethodEmpty = errors.New("the RPC method cannot be empty")
+
+	// ErrInvalidRPCParams is returned when RPC parameters aren't a JSON object.
+	ErrInvalidRPCParams = errors.New("invalid RPC parameters")
+
+	// ErrInvalidPath is returned when a field expression can't be parsed.
+	ErrInvalidPath = errors.New("invalid field expression")
+
+	// ErrUnsupportedDocument is returned when a device doesn't have the requested kind of document.
+	ErrUnsupportedDocument = errors.New("unsupported document kind")
+
+	// ErrInvalidSwitch is returned when a switch action, channel or timer is invalid.
+	ErrInvalidSwitch = errors.New("invalid switch")
+
+	// ErrChannelNotFound is returned when a device doesn't have the requested output channel (e.g. relay).
+	ErrChannelNotFound = errors.New("device channel not found")
+
 	// ErrUnexpected is returned when unmarshaling payloads that do not conform
 	// to the anticipated device structure.
 	ErrUnexpected = errors.New("unexpected IoT device")
@@ -43,6 +103,27 @@ var (
 	// if a device is to be excluded from being configured.
 	ErrPolicyExcluded = errors.New("policy excluded device")
 
+	// ErrRolledBack is returned when a device configuration failed mid-way,
+	// and the device was restored to its previous configuration snapshot.
+	ErrRolledBack = errors.New("configuration rolled back")
+
+	// ErrRollbackFailed is returned when a device configuration failed mid-way,
+	// and the previous configuration snapshot could not be restored either.
+	ErrRollbackFailed = errors.New("configuration rollback failed")
+
+	// ErrDeviceNotReady is returned when a device doesn't come back online in time,
+	// after being rebooted by a procedure (e.g. a profile switch).
+	ErrDeviceNotReady = errors.New("device not ready")
+
+	// ErrDeviceUnchanged is returned when a procedure had nothing to change on a device (e.g. scripts up to date).
+	ErrDeviceUnchanged = errors.New("device unchanged")
+
+	// ErrUnsupportedSchema is returned when a file kind has no Schema to validate against.
+	ErrUnsupportedSchema = errors.New("unsupported schema")
+
+	// ErrValidationFailed is returned when a file doesn't match its Schema.
+	ErrValidationFailed = errors.New("validation failed")
+
 	// ErrInvalidSortByField is returned when an attempt is made to
 	// sort by a field that is not supported by the SortBy() method
 	ErrInvalidSortByField = errors.New("invalid field to sort by")
@@ -51,8 +132,9 @@ var (
 	ErrInvalidDumpFormat = errors.New("invalid dump format")
 
 	// Policy error types
-	errPolicyModeUndefined = errors.New("the policy mode is undefined")
-	errPolicyModeInvalid   = errors.New("the policy mode is invalid")
+	errPolicyModeUndefined  = errors.New("the policy mode is undefined")
+	errPolicyModeInvalid    = errors.New("the policy mode is invalid")
+	errPolicyPatternInvalid = errors.New("the policy pattern is invalid")
 )
 
 // ProbeError for an IP address.
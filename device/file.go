@@ -0,0 +1,234 @@
+package device
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// extendsKey is the configuration file key holding the base files to inherit from.
+const extendsKey = "extends"
+
+// overridesKey is the configuration file key holding the per device overrides.
+const overridesKey = "overrides"
+
+// scriptsKey is the deployment file key holding the scripts to deploy.
+const scriptsKey = "scripts"
+
+// inherit returns the result of deep merging src over dst (see Merge).
+// Override lists are the exception, being concatenated instead, so the src overrides take precedence.
+func inherit(dst, src any) any {
+	d, dok := dst.(map[string]any)
+	s, sok := src.(map[string]any)
+
+	if !dok || !sok {
+		return Merge(dst, src)
+	}
+
+	merged := make(map[string]any, len(d)+len(s))
+
+	for key, value := range d {
+		merged[key] = value
+	}
+
+	for key, value := range s {
+		current, ok := merged[key]
+		if !ok {
+			merged[key] = value
+			continue
+		}
+
+		dl, dok := current.([]any)
+		sl, sok := value.([]any)
+
+		if key == overridesKey && dok && sok {
+			merged[key] = slices.Concat(dl, sl)
+			continue
+		}
+
+		merged[key] = inherit(current, value)
+	}
+
+	return merged
+}
+
+// extends returns the base file paths of a configuration file object, resolved relative to its directory.
+func extends(dir string, obj map[string]any) ([]string, error) {
+	var paths []string
+
+	switch value := obj[extendsKey].(type) {
+	case nil:
+		return nil, nil
+
+	case string:
+		paths = []string{value}
+
+	case []any:
+		for _, elem := range value {
+			path, ok := elem.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidExtends, elem)
+			}
+
+			paths = append(paths, path)
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExtends, value)
+	}
+
+	for i, path := range paths {
+		if path == "" {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidExtends, ErrFilePathEmpty)
+		}
+
+		if !filepath.IsAbs(path) {
+			paths[i] = filepath.Join(dir, path)
+		}
+	}
+
+	return paths, nil
+}
+
+// rebaseScripts resolves the relative script paths of a base deployment file against its directory,
+// so they keep pointing to the same scripts once merged.
+func rebaseScripts(dir string, obj map[string]any) {
+	scripts, _ := obj[scriptsKey].([]any)
+
+	rebase := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+
+		return filepath.Join(dir, path)
+	}
+
+	for i, entry := range scripts {
+		switch entry := entry.(type) {
+		case string:
+			scripts[i] = rebase(entry)
+
+		case map[string]any:
+			if path, ok := entry["path"].(string); ok {
+				entry["path"] = rebase(path)
+			}
+		}
+	}
+}
+
+// readFile decodes a configuration file, recursively merging the base files it extends.
+// References are resolved before decoding (see Interpolate), since unquoted ones aren't valid JSON.
+// The chain holds the files being read, to detect inheritance cycles, with base files having
+// their script paths rebased (see rebaseScripts).
+func readFile(fp string, chain []string) (any, error) {
+	abs, err := filepath.Abs(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(chain, abs) {
+		return nil, fmt.Errorf("%w: %s", ErrExtendsCycle, strings.Join(append(chain, abs), " -> "))
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = ToJSON(FileFormat(abs), data); err != nil {
+		return nil, fmt.Errorf("%s: %w", fp, err)
+	}
+
+	if data, err = Interpolate(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", fp, err)
+	}
+
+	var value any
+	if err = json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("%s: %w", fp, err)
+	}
+
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return value, nil
+	}
+
+	if len(chain) > 0 {
+		rebaseScripts(filepath.Dir(abs), obj)
+	}
+
+	bases, err := extends(filepath.Dir(abs), obj)
+	if err != nil || len(bases) == 0 {
+		return obj, err
+	}
+
+	chain = append(chain, abs)
+
+	var merged any = map[string]any{}
+
+	for _, base := range bases {
+		bv, err := readFile(base, chain)
+		if err != nil {
+			return nil, err
+		}
+
+		merged = inherit(merged, bv)
+	}
+
+	delete(obj, extendsKey)
+
+	return inherit(merged, obj), nil
+}
+
+// ReadFile reads a configuration file at the given path, returning its JSON data.
+// YAML and TOML files are converted to JSON, based on their extension (see ToJSON).
+// Configuration files can inherit from one or more base files, listed under the "extends" key,
+// with paths relative to the extending file. Base files are deep merged in order, and the extending
+// file is merged last, so its values take precedence (see Merge). Override lists are concatenated instead.
+// References are resolved in each file (see Interpolate), so base files can hold unquoted ones too,
+// and base deployment files can list script paths relative to themselves.
+// It returns an error if any of the files cannot be read, or if an inheritance cycle is found.
+func ReadFile(fp string) ([]byte, error) {
+	if fp == "" {
+		return nil, ErrFilePathEmpty
+	}
+
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = ToJSON(FileFormat(fp), data); err != nil {
+		return nil, err
+	}
+
+	if data, err = Interpolate(data); err != nil {
+		return nil, err
+	}
+
+	var obj map[string]jsontext.Value
+	if json.Unmarshal(data, &obj) != nil {
+		// Let the caller report invalid data
+		return data, nil
+	}
+
+	if _, ok := obj[extendsKey]; !ok {
+		return data, nil
+	}
+
+	value, err := readFile(fp, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = json.Marshal(value, json.Deterministic(true)); err != nil {
+		return nil, err
+	}
+
+	// Keep the resolved values from being resolved again, once unescaped by decoding
+	return escapeReferences(data), nil
+}
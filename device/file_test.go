@@ -0,0 +1,165 @@
+package device
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"io/fs"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestInherit(t *testing.T) {
+	tests := []struct {
+		name     string
+		dst      any
+		src      any
+		expected any
+	}{
+		{
+			name:     "scalar replaced",
+			dst:      map[string]any{"name": "foo"},
+			src:      map[string]any{"name": "bar"},
+			expected: map[string]any{"name": "bar"},
+		},
+		{
+			name: "objects merged",
+			dst: map[string]any{
+				"mqtt": map[string]any{"enable": true},
+			},
+			src: map[string]any{
+				"mqtt": map[string]any{"server": "192.168.1.254:1883"},
+			},
+			expected: map[string]any{
+				"mqtt": map[string]any{"enable": true, "server": "192.168.1.254:1883"},
+			},
+		},
+		{
+			name: "overrides concatenated",
+			dst: map[string]any{
+				"overrides": []any{map[string]any{"match": "foo"}},
+			},
+			src: map[string]any{
+				"overrides": []any{map[string]any{"match": "bar"}},
+			},
+			expected: map[string]any{
+				"overrides": []any{
+					map[string]any{"match": "foo"},
+					map[string]any{"match": "bar"},
+				},
+			},
+		},
+		{
+			name: "nested overrides concatenated",
+			dst: map[string]any{
+				"shellygen2": map[string]any{
+					"overrides": []any{map[string]any{"match": "foo"}},
+				},
+			},
+			src: map[string]any{
+				"shellygen2": map[string]any{
+					"overrides": []any{map[string]any{"match": "bar"}},
+				},
+			},
+			expected: map[string]any{
+				"shellygen2": map[string]any{
+					"overrides": []any{
+						map[string]any{"match": "foo"},
+						map[string]any{"match": "bar"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := inherit(test.dst, test.src)
+
+			if !reflect.DeepEqual(out, test.expected) {
+				t.Fatalf("expected %#v, got %#v", test.expected, out)
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		fp       string
+		expected string
+	}{
+		{
+			name: "failure: empty file path",
+			fp:   "",
+			err:  ErrFilePathEmpty,
+		},
+		{
+			name: "failure: file path not found",
+			fp:   "foo.bar",
+			err:  &fs.PathError{},
+		},
+		{
+			name: "failure: inheritance cycle",
+			fp:   "../testdata/extends/cycle-a.json",
+			err:  ErrExtendsCycle,
+		},
+		{
+			name: "failure: invalid base file",
+			fp:   "../testdata/extends/invalid.json",
+			err:  ErrInvalidExtends,
+		},
+		{
+			name:     "success: without base files",
+			fp:       "../testdata/config.json",
+			expected: "../testdata/config.json",
+		},
+		{
+			name:     "success: with base files",
+			fp:       "../testdata/extends/site.json",
+			expected: "../testdata/extends/merged.json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := ReadFile(test.fp)
+
+			if test.expected != "" {
+				expected, err := os.ReadFile(test.expected)
+				if err != nil {
+					t.Fatalf("unable to read expected file: %v", err)
+				}
+
+				var want, got any
+				if err = json.Unmarshal(expected, &want); err != nil {
+					t.Fatalf("unable to decode expected file: %v", err)
+				}
+
+				if err = json.Unmarshal(data, &got); err != nil {
+					t.Fatalf("unable to decode data: %v", err)
+				}
+
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("expected %s, got %s", expected, jsontext.Value(data))
+				}
+			}
+
+			var pathError *fs.PathError
+			switch {
+			case errors.As(test.err, &pathError):
+				var pe *fs.PathError
+				if errors.As(err, &pe) {
+					return
+				}
+
+			case errors.Is(err, test.err):
+				return
+			}
+
+			t.Fatalf("expected %#v, got %#v", test.err, err)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package device
+
+import (
+	"encoding/json/v2"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Input file formats
+const (
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// FileFormat returns the format of a file at the given path, based on its extension.
+// Files without a YAML or TOML extension are assumed to be JSON.
+func FileFormat(fp string) string {
+	switch strings.ToLower(filepath.Ext(fp)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+
+	case ".toml":
+		return FormatTOML
+	}
+
+	return FormatJSON
+}
+
+// normalise converts the generic YAML values that have no JSON counterpart (i.e. maps with non-string keys).
+func normalise(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, elem := range v {
+			v[key] = normalise(elem)
+		}
+
+		return v
+
+	case map[any]any:
+		obj := make(map[string]any, len(v))
+		for key, elem := range v {
+			obj[fmt.Sprint(key)] = normalise(elem)
+		}
+
+		return obj
+
+	case []any:
+		for i, elem := range v {
+			v[i] = normalise(elem)
+		}
+
+		return v
+	}
+
+	return value
+}
+
+// ToJSON converts YAML or TOML data into JSON, based on the file format (see FileFormat).
+// Decoding goes through JSON, so that the same structs and custom JSON unmarshalers apply to every format.
+// JSON data is returned unchanged.
+func ToJSON(format string, data []byte) ([]byte, error) {
+	var value any
+
+	switch format {
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+
+	case FormatTOML:
+		var obj map[string]any
+		if err := toml.Unmarshal(data, &obj); err != nil {
+			return nil, err
+		}
+
+		value = obj
+
+	default:
+		return data, nil
+	}
+
+	// Empty documents are treated as empty objects, rather than null
+	if value == nil {
+		value = map[string]any{}
+	}
+
+	return json.Marshal(normalise(value), json.Deterministic(true))
+}
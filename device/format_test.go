@@ -0,0 +1,180 @@
+package device
+
+import (
+	"encoding/json/v2"
+	"reflect"
+	"testing"
+)
+
+func TestFileFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		fp     string
+		format string
+	}{
+		{
+			name:   "JSON",
+			fp:     "config.json",
+			format: FormatJSON,
+		},
+		{
+			name:   "YAML",
+			fp:     "config.yaml",
+			format: FormatYAML,
+		},
+		{
+			name:   "YAML short extension",
+			fp:     "CONFIG.YML",
+			format: FormatYAML,
+		},
+		{
+			name:   "TOML",
+			fp:     "config.toml",
+			format: FormatTOML,
+		},
+		{
+			name:   "no extension",
+			fp:     "config",
+			format: FormatJSON,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if format := FileFormat(test.fp); format != test.format {
+				t.Fatalf("expected %q, got %q", test.format, format)
+			}
+		})
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		data     string
+		expected any
+		failure  bool
+	}{
+		{
+			name:    "failure: invalid YAML",
+			format:  FormatYAML,
+			data:    "foo: [bar",
+			failure: true,
+		},
+		{
+			name:    "failure: invalid TOML",
+			format:  FormatTOML,
+			data:    "foo = ",
+			failure: true,
+		},
+		{
+			name:     "success: JSON",
+			format:   FormatJSON,
+			data:     `{"foo":"bar"}`,
+			expected: map[string]any{"foo": "bar"},
+		},
+		{
+			name:     "success: empty YAML",
+			format:   FormatYAML,
+			data:     "",
+			expected: map[string]any{},
+		},
+		{
+			name:   "success: YAML",
+			format: FormatYAML,
+			data: `
+# MQTT settings
+mqtt:
+  config:
+    enable: true
+    server: 192.168.1.254:1883
+switch:
+  - id: 0
+    config:
+      auto_off_delay: 60
+`,
+			expected: map[string]any{
+				"mqtt": map[string]any{
+					"config": map[string]any{
+						"enable": true,
+						"server": "192.168.1.254:1883",
+					},
+				},
+				"switch": []any{
+					map[string]any{
+						"id": float64(0),
+						"config": map[string]any{
+							"auto_off_delay": float64(60),
+						},
+					},
+				},
+			},
+		},
+		{
+			name:   "success: YAML with non-string keys",
+			format: FormatYAML,
+			data:   "1: foo\n2: bar\n",
+			expected: map[string]any{
+				"1": "foo",
+				"2": "bar",
+			},
+		},
+		{
+			name:   "success: TOML",
+			format: FormatTOML,
+			data: `
+# MQTT settings
+[mqtt.config]
+enable = true
+server = "192.168.1.254:1883"
+
+[[switch]]
+id = 0
+config = { auto_off_delay = 60 }
+`,
+			expected: map[string]any{
+				"mqtt": map[string]any{
+					"config": map[string]any{
+						"enable": true,
+						"server": "192.168.1.254:1883",
+					},
+				},
+				"switch": []any{
+					map[string]any{
+						"id": float64(0),
+						"config": map[string]any{
+							"auto_off_delay": float64(60),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := ToJSON(test.format, []byte(test.data))
+
+			if test.failure {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			var out any
+			if err = json.Unmarshal(data, &out); err != nil {
+				t.Fatalf("unable to decode JSON: %v", err)
+			}
+
+			if !reflect.DeepEqual(out, test.expected) {
+				t.Fatalf("expected %#v, got %#v", test.expected, out)
+			}
+		})
+	}
+}
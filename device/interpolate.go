@@ -0,0 +1,211 @@
+package device
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Redacted is the replacement text for resolved secrets in output.
+const Redacted = "[REDACTED]"
+
+// Reference kinds, set before the name of the environment variable or file.
+const (
+	refFile   = "file:"
+	refSecret = "secret:"
+)
+
+// reference matches ${ENV_VAR}, ${secret:ENV_VAR} and ${file:/path} references.
+var reference = regexp.MustCompile(`\$\{(file:|secret:)?([^}]*)\}`)
+
+// secretRegistry holds the values resolved from secret and file references, so they can be redacted.
+type secretRegistry struct {
+	mu     sync.RWMutex
+	values map[string]struct{}
+}
+
+// register a resolved value as a secret.
+func (sr *secretRegistry) register(value string) {
+	if value == "" {
+		return
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	sr.values[value] = struct{}{}
+}
+
+// redact replaces every registered secret in a string.
+func (sr *secretRegistry) redact(s string) string {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+
+	if len(sr.values) == 0 {
+		return s
+	}
+
+	values := make([]string, 0, len(sr.values))
+	for value := range sr.values {
+		values = append(values, value)
+	}
+
+	// Longer secrets go first, in case one contains another
+	slices.SortFunc(values, func(a, b string) int {
+		return len(b) - len(a)
+	})
+
+	pairs := make([]string, 0, len(values)*2)
+	for _, value := range values {
+		pairs = append(pairs, value, Redacted)
+	}
+
+	return strings.NewReplacer(pairs...).Replace(s)
+}
+
+var secrets = &secretRegistry{
+	values: make(map[string]struct{}),
+}
+
+// Redact replaces every secret resolved by Interpolate in a string.
+func Redact(s string) string {
+	return secrets.redact(s)
+}
+
+// redactJSON replaces every secret resolved by Interpolate in the strings of a JSON value (see Redact),
+// so the value stays valid JSON. Values that aren't valid JSON are redacted as plain strings.
+func redactJSON(value string) string {
+	var buf bytes.Buffer
+
+	dec := jsontext.NewDecoder(strings.NewReader(value))
+	enc := jsontext.NewEncoder(&buf)
+
+	for {
+		tok, err := dec.ReadToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return Redact(value)
+		}
+
+		if tok.Kind() == '"' {
+			tok = jsontext.String(Redact(tok.String()))
+		}
+
+		if err = enc.WriteToken(tok); err != nil {
+			return Redact(value)
+		}
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// redactWriter is an io.Writer that redacts secrets before writing.
+type redactWriter struct {
+	w io.Writer
+}
+
+// Write interface implementation.
+func (rw *redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// NewRedactWriter wraps an io.Writer, so that every secret resolved by Interpolate is redacted from its output.
+func NewRedactWriter(w io.Writer) io.Writer {
+	return &redactWriter{
+		w: w,
+	}
+}
+
+// resolve returns the value of an environment variable or the content of a file, depending on the reference kind.
+// Trailing line breaks are removed from file contents.
+func resolve(kind, name string) (string, error) {
+	if kind != refFile {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrUndefinedVariable, name)
+		}
+
+		return value, nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// Interpolate resolves ${ENV_VAR}, ${secret:ENV_VAR} and ${file:/path} references in JSON data.
+// Resolved values are escaped, so they can be safely referenced within JSON strings, and aren't resolved again.
+// Values resolved from secret and file references are registered as secrets, to be redacted from any output
+// (see Redact), while plain environment variables are meant for values that aren't secret (e.g. ports, flags).
+// It returns an error if an environment variable isn't set, or a file can't be read.
+func Interpolate(data []byte) ([]byte, error) {
+	var err error
+
+	out := reference.ReplaceAllFunc(data, func(ref []byte) []byte {
+		if err != nil {
+			return ref
+		}
+
+		match := reference.FindSubmatch(ref)
+
+		var value string
+		value, err = resolve(string(match[1]), string(match[2]))
+		if err != nil {
+			return ref
+		}
+
+		var quoted []byte
+		quoted, err = jsontext.AppendQuote(nil, value)
+		if err != nil {
+			return ref
+		}
+
+		escaped := escapeReferences(quoted[1 : len(quoted)-1])
+
+		if len(match[1]) > 0 {
+			secrets.register(value)
+			secrets.register(string(escaped))
+		}
+
+		return escaped
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// escapeReferences escapes the references in JSON data, so that Interpolate leaves them alone.
+// Dollar signs can only appear within JSON strings, where they decode the same when escaped.
+func escapeReferences(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte("${"), []byte(`\u0024{`))
+}
+
+// interpolateRead reads all the JSON data from a reader and resolves its references (see Interpolate).
+func interpolateRead(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return Interpolate(data)
+}
@@ -0,0 +1,207 @@
+package device
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		data string
+		out  string
+	}{
+		{
+			name: "failure: undefined variable",
+			data: `{"pass":"${IOTAP_UNDEFINED}"}`,
+			err:  ErrUndefinedVariable,
+		},
+		{
+			name: "failure: file not found",
+			data: `{"pass":"${file:foo.bar}"}`,
+			err:  &fs.PathError{},
+		},
+		{
+			name: "success: no references",
+			data: `{"pass":"secret"}`,
+			out:  `{"pass":"secret"}`,
+		},
+		{
+			name: "success: environment variable",
+			data: `{"user":"admin","pass":"${IOTAP_PASS}"}`,
+			out:  `{"user":"admin","pass":"P@ssw0rd"}`,
+		},
+		{
+			name: "success: escaped environment variable",
+			data: `{"pass":"${IOTAP_QUOTED}"}`,
+			out:  `{"pass":"pa\"ss\\word"}`,
+		},
+		{
+			name: "success: environment variable holding a reference",
+			data: `{"pass":"${IOTAP_NESTED}"}`,
+			out:  `{"pass":"\u0024{IOTAP_UNDEFINED}"}`,
+		},
+		{
+			name: "success: secret environment variable",
+			data: `{"port":${IOTAP_PORT},"pass":"${secret:IOTAP_SECRET}"}`,
+			out:  `{"port":1883,"pass":"Secr3t"}`,
+		},
+		{
+			name: "success: file",
+			data: `{"psk":"${file:../testdata/secret.txt}"}`,
+			out:  `{"psk":"S3cr3tPSK"}`,
+		},
+	}
+
+	t.Setenv("IOTAP_PASS", "P@ssw0rd")
+
+	t.Cleanup(func() {
+		secrets.values = make(map[string]struct{})
+	})
+	t.Setenv("IOTAP_QUOTED", `pa"ss\word`)
+	t.Setenv("IOTAP_NESTED", "${IOTAP_UNDEFINED}")
+	t.Setenv("IOTAP_PORT", "1883")
+	t.Setenv("IOTAP_SECRET", "Secr3t")
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := Interpolate([]byte(test.data))
+
+			if string(out) != test.out {
+				t.Fatalf("expected %q, got %q", test.out, out)
+			}
+
+			var pathError *fs.PathError
+			switch {
+			case errors.As(test.err, &pathError):
+				var pe *fs.PathError
+				if errors.As(err, &pe) {
+					return
+				}
+
+			case errors.Is(err, test.err):
+				return
+			}
+
+			t.Fatalf("expected %#v, got %#v", test.err, err)
+		})
+	}
+
+	// Only values resolved from secret and file references are redacted
+	if out := Redact("P@ssw0rd 1883 Secr3t S3cr3tPSK"); out != "P@ssw0rd 1883 [REDACTED] [REDACTED]" {
+		t.Fatalf("expected %q, got %q", "P@ssw0rd 1883 [REDACTED] [REDACTED]", out)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets []string
+		in      string
+		out     string
+	}{
+		{
+			name: "no secrets",
+			in:   "password P@ssw0rd rejected",
+			out:  "password P@ssw0rd rejected",
+		},
+		{
+			name:    "empty values are ignored",
+			secrets: []string{""},
+			in:      "switch on",
+			out:     "switch on",
+		},
+		{
+			name:    "secret redacted",
+			secrets: []string{"P@ssw0rd"},
+			in:      "password P@ssw0rd rejected",
+			out:     "password [REDACTED] rejected",
+		},
+		{
+			name:    "longest secret first",
+			secrets: []string{"P@ss", "P@ssw0rd"},
+			in:      "P@ssw0rd and P@ss",
+			out:     "[REDACTED] and [REDACTED]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				secrets.values = make(map[string]struct{})
+			})
+
+			for _, s := range test.secrets {
+				secrets.register(s)
+			}
+
+			if out := Redact(test.in); out != test.out {
+				t.Fatalf("expected %q, got %q", test.out, out)
+			}
+		})
+	}
+}
+
+func TestNewRedactWriter(t *testing.T) {
+	t.Cleanup(func() {
+		secrets.values = make(map[string]struct{})
+	})
+
+	secrets.register("P@ssw0rd")
+
+	var sb strings.Builder
+
+	w := NewRedactWriter(&sb)
+
+	n, err := w.Write([]byte("password P@ssw0rd rejected"))
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	if n != 26 {
+		t.Fatalf("expected 26, got %d", n)
+	}
+
+	if out := sb.String(); out != "password [REDACTED] rejected" {
+		t.Fatalf("expected %q, got %q", "password [REDACTED] rejected", out)
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	t.Cleanup(func() {
+		secrets.values = make(map[string]struct{})
+	})
+
+	secrets.register("true")
+
+	tests := []struct {
+		name  string
+		value string
+		out   string
+	}{
+		{
+			name:  "strings redacted",
+			value: `{"enable":true,"pass":"true","list":["true",1]}`,
+			out:   `{"enable":true,"pass":"[REDACTED]","list":["[REDACTED]",1]}`,
+		},
+		{
+			name: "empty value",
+		},
+		{
+			name:  "invalid JSON",
+			value: `{"pass":true`,
+			out:   `{"pass":[REDACTED]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if out := redactJSON(test.value); out != test.out {
+				t.Fatalf("expected %q, got %q", test.out, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package device
+
+import (
+	"bytes"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+)
+
+// KeyValues holds the key-value store entries to set on one or more IoT devices, along with a policy to enforce.
+// Values can be of any JSON type, with strings being templates (see RenderString).
+type KeyValues struct {
+	Policy *Policy        `json:"policy,omitempty"`
+	Values map[string]any `json:"values"`
+}
+
+// render returns a copy of the values, with their templates rendered for a device.
+func (kv *KeyValues) render(data *TemplateData) (map[string]any, error) {
+	values, err := render(reflect.ValueOf(kv.Values), data)
+	if err != nil {
+		return nil, err
+	}
+
+	return values.Interface().(map[string]any), nil
+}
+
+// KeyValueStore is an interface that provides a standard way to manage the key-value store of IoT devices.
+type KeyValueStore interface {
+	KeyValues(*http.Client) (map[string]any, error)
+	KeyValue(*http.Client, string) (any, bool, error)
+	SetKeyValueRequests(map[string]any) ([]*http.Request, error)
+	DeleteKeyValueRequests([]string) ([]*http.Request, error)
+}
+
+// NewKeyValues creates a new *KeyValues instance by parsing data from the provided reader.
+// Environment variable and file references are resolved beforehand (see Interpolate).
+// It returns an error if the data is invalid or cannot be parsed.
+func NewKeyValues(r io.Reader) (*KeyValues, error) {
+	data, err := interpolateRead(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var kv KeyValues
+	if err = json.Unmarshal(data, &kv); err != nil {
+		return nil, err
+	}
+
+	if len(kv.Values) == 0 {
+		return nil, ErrKeyValuesEmpty
+	}
+
+	return &kv, nil
+}
+
+// LoadKeyValues creates a new *KeyValues instance from a file at the given path.
+// Base key-value files listed under the "extends" key are merged beforehand (see ReadFile).
+// It returns an error if the file cannot be opened or contains invalid data.
+func LoadKeyValues(fp string) (*KeyValues, error) {
+	data, err := ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeyValues(bytes.NewReader(data))
+}
+
+// formatValue returns the JSON representation of a key-value store entry.
+func formatValue(value any) string {
+	data, err := json.Marshal(value, json.Deterministic(true))
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
+
+// ListKeyValues is a procedure implementation designed to add the key-value store entries of an IoT device to a Report.
+var ListKeyValues = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(KeyValueStore)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: kvs", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	values, err := dev.KeyValues(&http.Client{
+		Transport: tap.transport,
+	})
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		tap.report.Add(res, key, formatValue(values[key]))
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+	}
+}
+
+// GetKeyValues is a procedure implementation designed to add specific key-value store entries of an IoT device
+// to a Report, so they can be compared across devices. Keys the device doesn't hold are added without a value.
+var GetKeyValues = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(KeyValueStore)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: kvs", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	client := &http.Client{
+		Transport: tap.transport,
+	}
+
+	for _, key := range tap.keys {
+		value, found, err := dev.KeyValue(client, key)
+		if err != nil {
+			ch <- &ProcedureResult{
+				dev: res,
+				err: err,
+			}
+			return
+		}
+
+		if !found {
+			tap.report.Add(res, key, "")
+			continue
+		}
+
+		tap.report.Add(res, key, formatValue(value))
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+	}
+}
+
+// SetKeyValues is a procedure implementation designed to set KeyValues on an IoT device.
+// String value templates are rendered for each device, prior to being set.
+var SetKeyValues = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(KeyValueStore)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: kvs", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	// Check if a key-value policy is set and enforce it
+	if tap.kvs.Policy != nil && tap.kvs.Policy.IsExcluded(res) {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: ErrPolicyExcluded,
+		}
+		return
+	}
+
+	values, err := tap.kvs.render(NewTemplateData(res, tap.vars))
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	rs, err := dev.SetKeyValueRequests(values)
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+		err: dispatch(tap, res, rs),
+	}
+}
+
+// DeleteKeyValues is a procedure implementation designed to delete key-value store entries from an IoT device.
+// The KeyValues Policy is enforced when a key-value file is set.
+var DeleteKeyValues = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(KeyValueStore)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: kvs", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	if tap.kvs != nil && tap.kvs.Policy != nil && tap.kvs.Policy.IsExcluded(res) {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: ErrPolicyExcluded,
+		}
+		return
+	}
+
+	rs, err := dev.DeleteKeyValueRequests(tap.keys)
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+		err: dispatch(tap, res, rs),
+	}
+}
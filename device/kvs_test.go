@@ -0,0 +1,351 @@
+package device
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+type keyValueStore struct {
+	funcError error
+	values    map[string]any
+	resource
+}
+
+func (kvs *keyValueStore) KeyValues(*http.Client) (map[string]any, error) {
+	if kvs.funcError != nil {
+		return nil, kvs.funcError
+	}
+
+	return map[string]any{
+		"room":      "kitchen",
+		"threshold": 21.5,
+	}, nil
+}
+
+func (kvs *keyValueStore) KeyValue(_ *http.Client, key string) (any, bool, error) {
+	if kvs.funcError != nil {
+		return nil, false, kvs.funcError
+	}
+
+	if key != "room" {
+		return nil, false, nil
+	}
+
+	return "kitchen", true, nil
+}
+
+func (kvs *keyValueStore) SetKeyValueRequests(values map[string]any) ([]*http.Request, error) {
+	if kvs.funcError != nil {
+		return nil, kvs.funcError
+	}
+
+	kvs.values = values
+
+	return []*http.Request{
+		{
+			URL:    &url.URL{},
+			Method: http.MethodGet,
+		},
+	}, nil
+}
+
+func (kvs *keyValueStore) DeleteKeyValueRequests([]string) ([]*http.Request, error) {
+	if kvs.funcError != nil {
+		return nil, kvs.funcError
+	}
+
+	return []*http.Request{
+		{
+			URL:    &url.URL{},
+			Method: http.MethodGet,
+		},
+	}, nil
+}
+
+func TestNewKeyValues(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		data   string
+		values int
+	}{
+		{
+			name: "failure: invalid JSON",
+			data: `{`,
+			err:  io.ErrUnexpectedEOF,
+		},
+		{
+			name: "failure: empty values",
+			data: `{"values":{}}`,
+			err:  ErrKeyValuesEmpty,
+		},
+		{
+			name:   "success",
+			data:   `{"values":{"room":"kitchen","threshold":21.5,"modes":["eco","boost"]}}`,
+			values: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kv, err := NewKeyValues(strings.NewReader(test.data))
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if kv != nil && len(kv.Values) != test.values {
+				t.Fatalf("expected %d values, got %d", test.values, len(kv.Values))
+			}
+		})
+	}
+}
+
+func TestLoadKeyValues(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		fp     string
+		values int
+	}{
+		{
+			name: "failure: empty file path",
+			err:  ErrFilePathEmpty,
+		},
+		{
+			name: "failure: file not found",
+			fp:   "../testdata/missing.json",
+			err:  fs.ErrNotExist,
+		},
+		{
+			name:   "success",
+			fp:     "../testdata/kvs.json",
+			values: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kv, err := LoadKeyValues(test.fp)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if kv != nil && len(kv.Values) != test.values {
+				t.Fatalf("expected %d values, got %d", test.values, len(kv.Values))
+			}
+		})
+	}
+}
+
+func TestKeyValueProcedures(t *testing.T) {
+	ok := &roundTripper{
+		response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("{}")),
+		},
+	}
+
+	mac, _ := net.ParseMAC("AA:BB:CC:DD:EE:01")
+
+	tests := []struct {
+		proc   procedure
+		rt     http.RoundTripper
+		dev    Resource
+		kv     *KeyValues
+		err    error
+		name   string
+		rows   [][]string
+		values map[string]any
+	}{
+		{
+			name: "failure: list unsupported procedure",
+			proc: ListKeyValues,
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: list function error",
+			proc: ListKeyValues,
+			dev: &keyValueStore{
+				funcError: ErrUnexpected,
+			},
+			err: ErrUnexpected,
+		},
+		{
+			name: "success: list",
+			proc: ListKeyValues,
+			dev:  &keyValueStore{},
+			rows: [][]string{
+				{"room", `"kitchen"`},
+				{"threshold", "21.5"},
+			},
+		},
+		{
+			name: "failure: get unsupported procedure",
+			proc: GetKeyValues,
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: get function error",
+			proc: GetKeyValues,
+			dev: &keyValueStore{
+				funcError: ErrUnexpected,
+			},
+			err: ErrUnexpected,
+		},
+		{
+			name: "success: get",
+			proc: GetKeyValues,
+			dev:  &keyValueStore{},
+			rows: [][]string{
+				{"room", `"kitchen"`},
+				{"missing", ""},
+			},
+		},
+		{
+			name: "failure: set unsupported procedure",
+			proc: SetKeyValues,
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: set policy exclusion",
+			proc: SetKeyValues,
+			dev:  &keyValueStore{},
+			kv: &KeyValues{
+				Policy: &Policy{
+					Mode: PolicyModeWhitelist,
+				},
+			},
+			err: ErrPolicyExcluded,
+		},
+		{
+			name: "failure: set template error",
+			proc: SetKeyValues,
+			dev:  &keyValueStore{},
+			kv: &KeyValues{
+				Values: map[string]any{"room": "{{ .Foo }}"},
+			},
+			err: template.ExecError{},
+		},
+		{
+			name: "failure: set function error",
+			proc: SetKeyValues,
+			dev: &keyValueStore{
+				funcError: ErrUnexpected,
+			},
+			kv:  &KeyValues{},
+			err: ErrUnexpected,
+		},
+		{
+			name: "success: set",
+			proc: SetKeyValues,
+			dev: &keyValueStore{
+				resource: resource{
+					mac: mac,
+				},
+			},
+			rt: ok,
+			kv: &KeyValues{
+				Values: map[string]any{
+					"room":      "{{ .Vars.room }}",
+					"threshold": 21.5,
+					"modes":     []any{"eco", "{{ .Vars.room }}"},
+				},
+			},
+			values: map[string]any{
+				"room":      "kitchen",
+				"threshold": 21.5,
+				"modes":     []any{"eco", "kitchen"},
+			},
+		},
+		{
+			name: "failure: delete unsupported procedure",
+			proc: DeleteKeyValues,
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: delete policy exclusion",
+			proc: DeleteKeyValues,
+			dev:  &keyValueStore{},
+			kv: &KeyValues{
+				Policy: &Policy{
+					Mode: PolicyModeWhitelist,
+				},
+			},
+			err: ErrPolicyExcluded,
+		},
+		{
+			name: "failure: delete function error",
+			proc: DeleteKeyValues,
+			dev: &keyValueStore{
+				funcError: ErrUnexpected,
+			},
+			err: ErrUnexpected,
+		},
+		{
+			name: "success: delete",
+			proc: DeleteKeyValues,
+			dev:  &keyValueStore{},
+			rt:   ok,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tap := &Tapper{
+				transport: test.rt,
+				kvs:       test.kv,
+				keys:      []string{"room", "missing"},
+				report:    NewReport(),
+				vars: Variables{
+					"aa:bb:cc:dd:ee:01": {"room": "kitchen"},
+				},
+			}
+
+			ch := make(chan *ProcedureResult, 1)
+
+			test.proc(tap, test.dev, ch)
+
+			result := <-ch
+
+			var execError template.ExecError
+			if errors.As(test.err, &execError) {
+				if !errors.As(result.err, &execError) {
+					t.Fatalf("expected %#v, got %#v", test.err, result.err)
+				}
+
+				return
+			}
+
+			if !errors.Is(result.err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, result.err)
+			}
+
+			var rows [][]string
+			for _, row := range tap.report.rows {
+				rows = append(rows, row.values[3:])
+			}
+
+			if !reflect.DeepEqual(rows, test.rows) {
+				t.Fatalf("expected %q, got %q", test.rows, rows)
+			}
+
+			if kvs, ok := test.dev.(*keyValueStore); ok && test.values != nil {
+				if !reflect.DeepEqual(kvs.values, test.values) {
+					t.Fatalf("expected %v, got %v", test.values, kvs.values)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package device
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// templateAction matches the actions of a script template, which aren't JavaScript.
+var templateAction = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+
+// unsupportedKeywords maps the keywords of JavaScript features the Shelly Script Language doesn't support to their issue.
+// See: https://shelly-api-docs.shelly.cloud/gen2/Scripts/ShellyScriptLanguageFeatures
+var unsupportedKeywords = map[string]string{
+	"class":   "classes aren't supported",
+	"extends": "classes aren't supported",
+	"async":   "async functions aren't supported",
+	"await":   "async functions aren't supported",
+	"yield":   "generators aren't supported",
+	"import":  "modules aren't supported",
+	"export":  "modules aren't supported",
+	"Promise": "promises aren't supported",
+}
+
+// closing maps opening brackets to their closing ones.
+var closing = map[byte]byte{
+	'(': ')',
+	'[': ']',
+	'{': '}',
+}
+
+// LintIssue describes a problem found in a script, at a given position.
+type LintIssue struct {
+	Message string
+	Line    int
+	Column  int
+}
+
+// Error interface implementation.
+func (li *LintIssue) Error() string {
+	return fmt.Sprintf("%d:%d: %s", li.Line, li.Column, li.Message)
+}
+
+// token is a lexical element of a script.
+type token struct {
+	value  string
+	kind   byte // 'i' for identifiers and keywords, 'n' for numbers, 's' for string, template and regex literals, 'p' otherwise
+	line   int
+	column int
+}
+
+// operand checks if the token ends an expression, in which case a following slash is a division rather than a regex.
+func (t *token) operand() bool {
+	switch t.kind {
+	case 'n', 's':
+		return true
+
+	case 'i':
+		return !slices.Contains([]string{"return", "typeof", "case", "do", "else", "in", "of", "new", "delete", "void", "throw"}, t.value)
+	}
+
+	return t.value == ")" || t.value == "]" || t.value == "}"
+}
+
+// lexer splits script code into tokens, skipping whitespace and comments.
+type lexer struct {
+	code    string
+	issues  []*LintIssue
+	pos     int
+	line    int
+	column  int
+	operand bool
+}
+
+// issue records a problem at the given position.
+func (l *lexer) issue(line, column int, format string, args ...any) {
+	l.issues = append(l.issues, &LintIssue{
+		Message: fmt.Sprintf(format, args...),
+		Line:    line,
+		Column:  column,
+	})
+}
+
+// advance moves the lexer n bytes forward, keeping track of the position.
+func (l *lexer) advance(n int) {
+	for range n {
+		if l.code[l.pos] == '\n' {
+			l.line++
+			l.column = 0
+		}
+
+		l.pos++
+		l.column++
+	}
+}
+
+// quoted skips a string or template literal, returning false if it's unterminated.
+func (l *lexer) quoted(quote byte) bool {
+	l.advance(1)
+
+	for l.pos < len(l.code) {
+		switch c := l.code[l.pos]; {
+		case c == '\\' && l.pos+1 < len(l.code):
+			l.advance(2)
+
+		case c == quote:
+			l.advance(1)
+			return true
+
+		case c == '\n' && quote != '`':
+			return false
+
+		default:
+			l.advance(1)
+		}
+	}
+
+	return false
+}
+
+// regex skips a regex literal, along with its flags, returning false if it's unterminated.
+func (l *lexer) regex() bool {
+	l.advance(1)
+
+	class := false
+
+	for l.pos < len(l.code) {
+		switch c := l.code[l.pos]; {
+		case c == '\\' && l.pos+1 < len(l.code):
+			l.advance(2)
+
+		case c == '\n':
+			return false
+
+		case c == '[':
+			class = true
+			l.advance(1)
+
+		case c == ']':
+			class = false
+			l.advance(1)
+
+		case c == '/' && !class:
+			l.advance(1)
+
+			for l.pos < len(l.code) && isIdentChar(l.code[l.pos]) {
+				l.advance(1)
+			}
+
+			return true
+
+		default:
+			l.advance(1)
+		}
+	}
+
+	return false
+}
+
+// isIdentChar checks if a byte can be part of an identifier or a number.
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// tokens returns the script tokens.
+func (l *lexer) tokens() []*token {
+	var tokens []*token
+
+	for l.pos < len(l.code) {
+		c := l.code[l.pos]
+		line, column := l.line, l.column
+		start := l.pos
+
+		var kind byte = 'p'
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			l.advance(1)
+			continue
+
+		case strings.HasPrefix(l.code[l.pos:], "//"):
+			for l.pos < len(l.code) && l.code[l.pos] != '\n' {
+				l.advance(1)
+			}
+			continue
+
+		case strings.HasPrefix(l.code[l.pos:], "/*"):
+			end := strings.Index(l.code[l.pos+2:], "*/")
+			if end < 0 {
+				l.issue(line, column, "unterminated comment")
+				l.advance(len(l.code) - l.pos)
+				continue
+			}
+
+			l.advance(end + 4)
+			continue
+
+		case c == '"' || c == '\'' || c == '`':
+			kind = 's'
+
+			if !l.quoted(c) {
+				l.issue(line, column, "unterminated string")
+			}
+
+		case c == '/' && !l.operand:
+			kind = 's'
+
+			if !l.regex() {
+				l.issue(line, column, "unterminated regular expression")
+			}
+
+		case isIdentChar(c):
+			kind = 'i'
+			if c >= '0' && c <= '9' {
+				kind = 'n'
+			}
+
+			for l.pos < len(l.code) && (isIdentChar(l.code[l.pos]) || kind == 'n' && l.code[l.pos] == '.') {
+				l.advance(1)
+			}
+
+		default:
+			n := 1
+			for _, p := range []string{"...", "=>", "?.", "??", "++", "--"} {
+				if strings.HasPrefix(l.code[l.pos:], p) {
+					n = len(p)
+					break
+				}
+			}
+
+			l.advance(n)
+		}
+
+		t := &token{
+			value:  l.code[start:l.pos],
+			kind:   kind,
+			line:   line,
+			column: column,
+		}
+
+		// Increments and decrements leave the expression state as it is, so a slash following a postfix one
+		// (e.g. i++ / 2) is still a division
+		if t.value != "++" && t.value != "--" {
+			l.operand = t.operand()
+		}
+
+		tokens = append(tokens, t)
+	}
+
+	return tokens
+}
+
+// LintScript checks script code against the subset of JavaScript supported by the Shelly Script Language,
+// returning every issue found: unbalanced brackets, unterminated literals, unsupported features
+// (e.g. classes, async functions) and let/const declarations reusing a name declared in the same block,
+// including by a var declaration.
+// It's a heuristic check on the script tokens rather than a parser, so it doesn't report syntax errors
+// other than the ones above. A slash is taken as a division after a token ending an expression,
+// and as a regex literal otherwise, which misreads a regex following a block (e.g. `if (ok) {} /a/.test(s)`).
+// Only the names declared first in a let, const or var statement are checked (e.g. the b in `let a, b` isn't).
+func LintScript(code []byte) []*LintIssue {
+	l := &lexer{
+		code:   string(code),
+		line:   1,
+		column: 1,
+	}
+
+	tokens := l.tokens()
+
+	var (
+		brackets []*token
+		scopes   = []map[string]string{{}}
+	)
+
+	for i, t := range tokens {
+		if t.kind == 'i' {
+			// Keywords are allowed as property names (e.g. obj.class, {class: 1})
+			if msg, ok := unsupportedKeywords[t.value]; ok && (i == 0 || tokens[i-1].value != "." && tokens[i-1].value != "?.") &&
+				(i+1 == len(tokens) || tokens[i+1].value != ":") {
+				l.issue(t.line, t.column, "%s (%s)", msg, t.value)
+			}
+
+			if t.value == "function" && i+1 < len(tokens) && tokens[i+1].value == "*" {
+				l.issue(t.line, t.column, "generators aren't supported (function*)")
+			}
+
+			// Declarations within parentheses (e.g. for loops) are scoped to the following block
+			// Names declared by var statements may be declared again by other var statements only
+			if (t.value == "let" || t.value == "const" || t.value == "var") && i+1 < len(tokens) &&
+				tokens[i+1].kind == 'i' && (len(brackets) == 0 || brackets[len(brackets)-1].value == "{") {
+				name := tokens[i+1]
+
+				scope := scopes[len(scopes)-1]
+				if declared, ok := scope[name.value]; ok && (declared != "var" || t.value != "var") {
+					l.issue(name.line, name.column, "%q is already declared in this block", name.value)
+				} else if !ok {
+					scope[name.value] = t.value
+				}
+			}
+
+			continue
+		}
+
+		if t.kind != 'p' {
+			continue
+		}
+
+		switch t.value {
+		case "(", "[", "{":
+			brackets = append(brackets, t)
+
+			if t.value == "{" {
+				scopes = append(scopes, map[string]string{})
+			}
+
+		case ")", "]", "}":
+			if len(brackets) == 0 {
+				l.issue(t.line, t.column, "unexpected %q", t.value)
+				continue
+			}
+
+			open := brackets[len(brackets)-1]
+			if closing[open.value[0]] != t.value[0] {
+				l.issue(t.line, t.column, "expected %q to close %q at %d:%d, got %q", string(closing[open.value[0]]), open.value, open.line, open.column, t.value)
+			}
+
+			brackets = brackets[:len(brackets)-1]
+
+			if open.value == "{" {
+				scopes = scopes[:len(scopes)-1]
+			}
+		}
+	}
+
+	for _, open := range brackets {
+		l.issue(open.line, open.column, "unclosed %q", open.value)
+	}
+
+	slices.SortStableFunc(l.issues, func(a, b *LintIssue) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+
+		return a.Column - b.Column
+	})
+
+	return l.issues
+}
+
+// Lint checks the script code (see LintScript).
+// Template actions are replaced by a placeholder value beforehand, keeping the position of the code around them.
+func (s *Script) Lint() []*LintIssue {
+	if !s.template {
+		return LintScript(s.code)
+	}
+
+	code := templateAction.ReplaceAllFunc(s.code, func(action []byte) []byte {
+		placeholder := make([]byte, len(action))
+
+		for i, c := range action {
+			switch {
+			case i == 0:
+				placeholder[i] = '0'
+
+			case c == '\n':
+				placeholder[i] = c
+
+			default:
+				placeholder[i] = ' '
+			}
+		}
+
+		return placeholder
+	})
+
+	return LintScript(code)
+}
+
+// Lint checks every Deployment script (see LintScript), returning the issues found, prefixed with the script name.
+func (d *Deployment) Lint() []error {
+	var errs []error
+
+	for _, s := range d.Scripts {
+		for _, issue := range s.Lint() {
+			errs = append(errs, fmt.Errorf("%s:%w", s.Name(), issue))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,141 @@
+package device
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLintScript(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		issues []string
+	}{
+		{
+			name: "valid script",
+			code: `let count = 0;
+const url = "http://example.com/a//b"; // comment with class
+/* async block comment */
+for (let i = 0; i < 3; i++) { print(i / 2); }
+for (let i = 0; i < 3; i++) { let x = /[)}]/g; }
+function tick(a) { let count = a; return count; }
+Shelly.call("Switch.Set", {id: 0, on: true}, function (res) { print(JSON.stringify(res)); });
+let o = {class: 1}; print(o.class, ` + "`${count}`" + `);`,
+		},
+		{
+			name: "unsupported features",
+			code: "class Foo extends Bar {}\nasync function f() { await g(); }\nfunction* gen() { yield 1; }\nlet p = new Promise();",
+			issues: []string{
+				"1:1: classes aren't supported (class)",
+				"1:11: classes aren't supported (extends)",
+				"2:1: async functions aren't supported (async)",
+				"2:22: async functions aren't supported (await)",
+				"3:1: generators aren't supported (function*)",
+				"3:19: generators aren't supported (yield)",
+				"4:13: promises aren't supported (Promise)",
+			},
+		},
+		{
+			name: "redeclared names",
+			code: "let a = 1;\nconst a = 2;\nif (a) { let a = 3; const b = a; let b; }",
+			issues: []string{
+				`2:7: "a" is already declared in this block`,
+				`3:38: "b" is already declared in this block`,
+			},
+		},
+		{
+			name: "var mixed with let and const",
+			code: "var a = 1;\nvar a = 2;\nlet a = 3;\nconst b = 4;\nvar b = 5;\nfunction f() { var a = 6; }",
+			issues: []string{
+				`3:5: "a" is already declared in this block`,
+				`5:5: "b" is already declared in this block`,
+			},
+		},
+		{
+			name: "divisions and regex literals",
+			code: `let i = 0, a = [4], b = 2, s = "x";
+let x = i++ / 2 / (b--) / 2;
+let y = a[0]-- / 2;
+let z = x / b / 2;
+if (/x/.test(s)) { print(s); }
+function f() { return /[)]/.test(s); }
+print(typeof /x/);
+print(a?.class, {async: 1}.async);`,
+		},
+		{
+			name: "unbalanced brackets",
+			code: "function f() {\n  print((1);\n}\n]\n)\n[",
+			issues: []string{
+				`3:1: expected ")" to close "(" at 2:8, got "}"`,
+				`4:1: expected "}" to close "{" at 1:14, got "]"`,
+				`5:1: unexpected ")"`,
+				`6:1: unclosed "["`,
+			},
+		},
+		{
+			name: "unterminated literals",
+			code: "let s = \"abc\nlet r = /abc\n/* open",
+			issues: []string{
+				"1:9: unterminated string",
+				"2:9: unterminated regular expression",
+				"3:1: unterminated comment",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var issues []string
+			for _, issue := range LintScript([]byte(test.code)) {
+				issues = append(issues, issue.Error())
+			}
+
+			if !reflect.DeepEqual(issues, test.issues) {
+				t.Fatalf("expected %q, got %q", test.issues, issues)
+			}
+		})
+	}
+}
+
+func TestDeployment_Lint(t *testing.T) {
+	dep := &Deployment{
+		Scripts: []*Script{
+			{path: "../testdata/ok.js", code: []byte(`print("ok");`)},
+			{path: "../testdata/bad.js", name: "bad", code: []byte(`class Foo {}`)},
+		},
+	}
+
+	errs := dep.Lint()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 issue, got %d", len(errs))
+	}
+
+	expected := "bad:1:1: classes aren't supported (class)"
+	if errs[0].Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, errs[0].Error())
+	}
+
+	var issue *LintIssue
+	if !errors.As(errs[0], &issue) {
+		t.Fatalf("expected %T, got %T", issue, errs[0])
+	}
+}
+
+func TestScript_LintTemplate(t *testing.T) {
+	s := &Script{
+		path:     "../testdata/template.js",
+		code:     []byte("let max = {{ .Vars.max }}; class Foo {}\nlet s = \"{{ .Name }}\";\n{{ if .Vars.on }}\nlet {{\n.Vars.name }} = 1;\n{{ end }}"),
+		template: true,
+	}
+
+	var issues []string
+	for _, issue := range s.Lint() {
+		issues = append(issues, issue.Error())
+	}
+
+	// Template actions are blanked, so the code around them keeps its position
+	if expected := []string{"1:28: classes aren't supported (class)"}; !reflect.DeepEqual(issues, expected) {
+		t.Fatalf("expected %q, got %q", expected, issues)
+	}
+}
@@ -0,0 +1,104 @@
+package device
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Script statuses, as reported by comparing a device script with its deployment manifest entry.
+const (
+	ScriptCurrent  = "current"
+	ScriptOutdated = "outdated"
+	ScriptMissing  = "missing"
+	ScriptModified = "modified"
+	ScriptUnmarked = "unmarked"
+)
+
+// markerPattern matches the version marker line on top of a deployed script.
+var markerPattern = regexp.MustCompile(`\A// iotap: hash=([0-9a-f]{64}) source=("(?:[^"\\]|\\.)*") deployed=(\S+)\n`)
+
+// ScriptMarker is the version marker recorded on top of a deployed script, as a header comment.
+type ScriptMarker struct {
+	Deployed time.Time
+	Hash     string
+	Source   string
+}
+
+// String returns the header comment of the marker.
+func (m *ScriptMarker) String() string {
+	return fmt.Sprintf("// iotap: hash=%s source=%q deployed=%s\n", m.Hash, m.Source, m.Deployed.Format(time.RFC3339))
+}
+
+// ParseScriptMarker splits deployed script code into its version marker and its body.
+// A nil marker is returned, along with the whole code, if the script isn't marked (e.g. installed by hand).
+func ParseScriptMarker(code []byte) (*ScriptMarker, []byte) {
+	m := markerPattern.FindSubmatch(code)
+	if m == nil {
+		return nil, code
+	}
+
+	source, err := strconv.Unquote(string(m[2]))
+	if err != nil {
+		return nil, code
+	}
+
+	deployed, err := time.Parse(time.RFC3339, string(m[3]))
+	if err != nil {
+		return nil, code
+	}
+
+	return &ScriptMarker{
+		Hash:     string(m[1]),
+		Source:   source,
+		Deployed: deployed,
+	}, code[len(m[0]):]
+}
+
+// hashCode returns the hex encoded SHA-256 hash of script code.
+func hashCode(code []byte) string {
+	sum := sha256.Sum256(code)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Marked returns the script code, with a version marker on top.
+func (s *Script) Marked(deployed time.Time) []byte {
+	marker := &ScriptMarker{
+		Hash:     s.Hash(),
+		Source:   s.Source(),
+		Deployed: deployed,
+	}
+
+	return append([]byte(marker.String()), s.code...)
+}
+
+// Status compares deployed script code with the script, returning its status along with the version marker, if any.
+// Scripts are current when their marker and body match the script, outdated when their marker doesn't,
+// modified when their body doesn't match their marker, and unmarked when they have no marker.
+func (s *Script) Status(code []byte) (string, *ScriptMarker) {
+	marker, body := ParseScriptMarker(code)
+
+	switch {
+	case marker == nil:
+		return ScriptUnmarked, nil
+
+	case hashCode(body) != marker.Hash:
+		return ScriptModified, marker
+
+	case marker.Hash != s.Hash():
+		return ScriptOutdated, marker
+	}
+
+	return ScriptCurrent, marker
+}
+
+// Current checks if deployed script code is marked, and matches the script.
+func (s *Script) Current(code []byte) bool {
+	status, _ := s.Status(code)
+
+	return status == ScriptCurrent
+}
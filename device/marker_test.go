@@ -0,0 +1,111 @@
+package device
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseScriptMarker(t *testing.T) {
+	deployed := time.Date(2026, time.March, 1, 10, 30, 0, 0, time.UTC)
+
+	s := &Script{
+		path: "../testdata/my \"script\".js",
+		code: []byte(`var foo = "abc";`),
+	}
+
+	tests := []struct {
+		marker *ScriptMarker
+		name   string
+		code   []byte
+		body   []byte
+	}{
+		{
+			name: "unmarked code",
+			code: []byte(`var foo = "abc";`),
+			body: []byte(`var foo = "abc";`),
+		},
+		{
+			name: "invalid timestamp",
+			code: []byte("// iotap: hash=" + s.Hash() + " source=\"a.js\" deployed=yesterday\nvar foo;"),
+			body: []byte("// iotap: hash=" + s.Hash() + " source=\"a.js\" deployed=yesterday\nvar foo;"),
+		},
+		{
+			name: "marked code",
+			code: s.Marked(deployed),
+			body: []byte(`var foo = "abc";`),
+			marker: &ScriptMarker{
+				Hash:     s.Hash(),
+				Source:   `my "script".js`,
+				Deployed: deployed,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			marker, body := ParseScriptMarker(test.code)
+
+			if !reflect.DeepEqual(marker, test.marker) {
+				t.Fatalf("expected %#v, got %#v", test.marker, marker)
+			}
+
+			if string(body) != string(test.body) {
+				t.Fatalf("expected %q, got %q", test.body, body)
+			}
+		})
+	}
+}
+
+func TestScript_Status(t *testing.T) {
+	deployed := time.Now()
+
+	s := &Script{
+		path: "../testdata/script1.js",
+		code: []byte(`var foo = "abc";`),
+	}
+
+	old := &Script{
+		path: "../testdata/script1.js",
+		code: []byte(`var foo = "xyz";`),
+	}
+
+	tests := []struct {
+		name   string
+		status string
+		code   []byte
+	}{
+		{
+			name:   "unmarked",
+			code:   s.Code(),
+			status: ScriptUnmarked,
+		},
+		{
+			name:   "modified",
+			code:   append(s.Marked(deployed), "\nprint(foo);"...),
+			status: ScriptModified,
+		},
+		{
+			name:   "outdated",
+			code:   old.Marked(deployed),
+			status: ScriptOutdated,
+		},
+		{
+			name:   "current",
+			code:   s.Marked(deployed),
+			status: ScriptCurrent,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if status, _ := s.Status(test.code); status != test.status {
+				t.Fatalf("expected %s, got %s", test.status, status)
+			}
+
+			if current := s.Current(test.code); current != (test.status == ScriptCurrent) {
+				t.Fatalf("expected %t, got %t", test.status == ScriptCurrent, current)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package device
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// objects converts a slice into a slice of objects, returning false if any of its elements isn't one.
+func objects(list []any) ([]map[string]any, bool) {
+	objs := make([]map[string]any, len(list))
+
+	for i, elem := range list {
+		obj, ok := elem.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		objs[i] = obj
+	}
+
+	return objs, true
+}
+
+// indexOf returns the position of the object matching the "id" of another, or -1 if there's no match.
+func indexOf(objs []map[string]any, obj map[string]any) int {
+	id, ok := obj["id"]
+	if !ok {
+		return -1
+	}
+
+	for i, o := range objs {
+		if oid, ok := o["id"]; ok && fmt.Sprint(oid) == fmt.Sprint(id) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// mergeObjects returns a new object, holding the result of deep merging src over dst.
+func mergeObjects(dst, src map[string]any) map[string]any {
+	merged := make(map[string]any, len(dst)+len(src))
+
+	for key, value := range dst {
+		merged[key] = value
+	}
+
+	for key, value := range src {
+		if current, ok := merged[key]; ok {
+			merged[key] = Merge(current, value)
+			continue
+		}
+
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// mergeArrays returns a new array, holding the result of merging the src objects over the dst objects.
+// Objects are matched by their "id" when both have one, and by their position otherwise.
+// Objects in src without a match are appended.
+func mergeArrays(dst, src []map[string]any) []any {
+	merged := make([]map[string]any, len(dst))
+	copy(merged, dst)
+
+	for i, obj := range src {
+		pos := indexOf(merged, obj)
+
+		// Fall back to the element position, unless both elements are identified
+		if pos < 0 && i < len(dst) && (obj["id"] == nil || dst[i]["id"] == nil) {
+			pos = i
+		}
+
+		if pos < 0 {
+			merged = append(merged, obj)
+			continue
+		}
+
+		merged[pos] = mergeObjects(merged[pos], obj)
+	}
+
+	list := make([]any, len(merged))
+	for i, obj := range merged {
+		list[i] = obj
+	}
+
+	return list
+}
+
+// Merge returns the result of deep merging src over dst, without modifying either of them.
+// Objects are merged key by key and arrays of objects are merged element by element,
+// matched by "id" when both elements have one, or by position otherwise.
+// Any other src value (including arrays of scalars) replaces the dst value.
+func Merge(dst, src any) any {
+	switch s := src.(type) {
+	case map[string]any:
+		if d, ok := dst.(map[string]any); ok {
+			return mergeObjects(d, s)
+		}
+
+	case []any:
+		d, ok := dst.([]any)
+		if !ok {
+			break
+		}
+
+		dobjs, dok := objects(d)
+		sobjs, sok := objects(s)
+
+		if dok && sok {
+			return mergeArrays(dobjs, sobjs)
+		}
+	}
+
+	return src
+}
+
+var mapType = reflect.TypeFor[map[string]any]()
+
+// toGeneric converts a settings value (i.e. a pointer to a map, or a pointer to a slice of map pointers)
+// into its generic representation. It returns false for any other kind of value.
+func toGeneric(v reflect.Value) (any, bool) {
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return nil, false
+	}
+
+	elem := v.Elem()
+
+	switch {
+	case elem.Kind() == reflect.Map && elem.Type().ConvertibleTo(mapType):
+		return elem.Convert(mapType).Interface(), true
+
+	case elem.Kind() == reflect.Slice &&
+		elem.Type().Elem().Kind() == reflect.Pointer &&
+		elem.Type().Elem().Elem().ConvertibleTo(mapType) &&
+		elem.Type().Elem().Elem().Kind() == reflect.Map:
+		list := make([]any, elem.Len())
+
+		for i := range elem.Len() {
+			if elem.Index(i).IsNil() {
+				list[i] = map[string]any{}
+				continue
+			}
+
+			list[i] = elem.Index(i).Elem().Convert(mapType).Interface()
+		}
+
+		return list, true
+	}
+
+	return nil, false
+}
+
+// fromGeneric converts a generic value back into a settings value of the given (pointer) type.
+func fromGeneric(value any, typ reflect.Type) reflect.Value {
+	ptr := reflect.New(typ.Elem())
+
+	switch v := value.(type) {
+	case map[string]any:
+		ptr.Elem().Set(reflect.ValueOf(v).Convert(typ.Elem()))
+
+	case []any:
+		list := reflect.MakeSlice(typ.Elem(), len(v), len(v))
+
+		for i, elem := range v {
+			obj, _ := elem.(map[string]any)
+
+			item := reflect.New(typ.Elem().Elem().Elem())
+			item.Elem().Set(reflect.ValueOf(obj).Convert(typ.Elem().Elem().Elem()))
+
+			list.Index(i).Set(item)
+		}
+
+		ptr.Elem().Set(list)
+	}
+
+	return ptr
+}
+
+// MergeConfig returns a new Config, holding the result of deep merging the src settings over the dst ones.
+// Both Config values must be of the same type. Pointers to plain values (e.g. strings) set in src replace
+// the dst ones, while any other field (e.g. Policy) is kept from dst.
+func MergeConfig(dst, src Config) (Config, error) {
+	dv := reflect.ValueOf(dst)
+	sv := reflect.ValueOf(src)
+
+	if dv.Type() != sv.Type() {
+		return nil, fmt.Errorf("%w: expected %q, got %q", ErrDriverMismatch, dst.Driver(), src.Driver())
+	}
+
+	if dv.Kind() != reflect.Pointer || dv.Elem().Kind() != reflect.Struct {
+		return src, nil
+	}
+
+	merged := reflect.New(dv.Elem().Type())
+	merged.Elem().Set(dv.Elem())
+
+	for i := range sv.Elem().NumField() {
+		field := merged.Elem().Field(i)
+		value := sv.Elem().Field(i)
+
+		if !field.CanSet() || value.Kind() != reflect.Pointer || value.IsNil() {
+			continue
+		}
+
+		override, ok := toGeneric(value)
+		if !ok {
+			if value.Elem().Kind() != reflect.Struct {
+				field.Set(value)
+			}
+			continue
+		}
+
+		current, ok := toGeneric(field)
+		if !ok {
+			field.Set(value)
+			continue
+		}
+
+		field.Set(fromGeneric(Merge(current, override), field.Type()))
+	}
+
+	return merged.Interface().(Config), nil
+}
@@ -0,0 +1,191 @@
+package device
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		dst      any
+		src      any
+		expected any
+		name     string
+	}{
+		{
+			name:     "scalar replaces scalar",
+			dst:      "foo",
+			src:      "bar",
+			expected: "bar",
+		},
+		{
+			name:     "object replaces scalar",
+			dst:      "foo",
+			src:      map[string]any{"foo": "bar"},
+			expected: map[string]any{"foo": "bar"},
+		},
+		{
+			name: "objects are deep merged",
+			dst: map[string]any{
+				"enable": true,
+				"device": map[string]any{
+					"name": "plug",
+					"eco":  true,
+				},
+			},
+			src: map[string]any{
+				"device": map[string]any{
+					"name": "server",
+				},
+				"server": "mqtt.local",
+			},
+			expected: map[string]any{
+				"enable": true,
+				"device": map[string]any{
+					"name": "server",
+					"eco":  true,
+				},
+				"server": "mqtt.local",
+			},
+		},
+		{
+			name:     "arrays of scalars are replaced",
+			dst:      []any{"0700-0123456-on", "2300-0123456-off"},
+			src:      []any{"0800-56-on"},
+			expected: []any{"0800-56-on"},
+		},
+		{
+			name: "arrays of objects are merged by position",
+			dst: []any{
+				map[string]any{"auto_off": 3, "name": "a"},
+				map[string]any{"auto_off": 3, "name": "b"},
+			},
+			src: []any{
+				map[string]any{"auto_off": 10},
+				map[string]any{},
+				map[string]any{"name": "c"},
+			},
+			expected: []any{
+				map[string]any{"auto_off": 10, "name": "a"},
+				map[string]any{"auto_off": 3, "name": "b"},
+				map[string]any{"name": "c"},
+			},
+		},
+		{
+			name: "arrays of objects are merged by id",
+			dst: []any{
+				map[string]any{"id": 0, "config": map[string]any{"name": "a", "in_mode": "flip"}},
+				map[string]any{"id": 1, "config": map[string]any{"name": "b"}},
+			},
+			src: []any{
+				map[string]any{"id": float64(1), "config": map[string]any{"name": "z"}},
+				map[string]any{"id": 2, "config": map[string]any{"name": "c"}},
+			},
+			expected: []any{
+				map[string]any{"id": 0, "config": map[string]any{"name": "a", "in_mode": "flip"}},
+				map[string]any{"id": float64(1), "config": map[string]any{"name": "z"}},
+				map[string]any{"id": 2, "config": map[string]any{"name": "c"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged := Merge(test.dst, test.src)
+
+			if !reflect.DeepEqual(merged, test.expected) {
+				t.Fatalf("expected %#v, got %#v", test.expected, merged)
+			}
+		})
+	}
+}
+
+func TestMergeConfig(t *testing.T) {
+	name := "server"
+	policy := &Policy{
+		Mode: PolicyModeWhitelist,
+	}
+
+	dst := &templateConfig{
+		Policy: policy,
+		Sys: &settings{
+			"config": map[string]any{
+				"device": map[string]any{
+					"name":     "plug",
+					"eco_mode": true,
+				},
+			},
+		},
+		Switch: &[]*settings{
+			{
+				"id": 0,
+				"config": map[string]any{
+					"auto_off": false,
+				},
+			},
+		},
+	}
+
+	src := &templateConfig{
+		Policy: &Policy{
+			Mode: PolicyModeBlacklist,
+		},
+		Name: &name,
+		Sys: &settings{
+			"config": map[string]any{
+				"device": map[string]any{
+					"name": "server",
+				},
+			},
+		},
+		Switch: &[]*settings{
+			{
+				"id": 0,
+				"config": map[string]any{
+					"auto_off": true,
+				},
+			},
+		},
+	}
+
+	merged, err := MergeConfig(dst, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &templateConfig{
+		Policy: policy,
+		Name:   &name,
+		Sys: &settings{
+			"config": map[string]any{
+				"device": map[string]any{
+					"name":     "server",
+					"eco_mode": true,
+				},
+			},
+		},
+		Switch: &[]*settings{
+			{
+				"id": 0,
+				"config": map[string]any{
+					"auto_off": true,
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, merged)
+	}
+
+	// The original Config must remain untouched
+	if (*dst.Sys)["config"].(map[string]any)["device"].(map[string]any)["name"] != "plug" {
+		t.Fatal("original config was modified")
+	}
+
+	_, err = MergeConfig(dst, &config{})
+	if !errors.Is(err, ErrDriverMismatch) {
+		t.Fatalf("expected %#v, got %#v", ErrDriverMismatch, err)
+	}
+}
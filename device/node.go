@@ -0,0 +1,325 @@
+package device
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Node kinds, named after their JSON Schema types
+const (
+	KindObject  = "object"
+	KindArray   = "array"
+	KindString  = "string"
+	KindNumber  = "number"
+	KindBoolean = "boolean"
+	KindNull    = "null"
+)
+
+// Member is an object key, along with its value and position.
+type Member struct {
+	Key    string
+	Value  *Node
+	Line   int
+	Column int
+}
+
+// Node is a decoded file value, along with its position in the file.
+// Positions are 1-based, with zero meaning the position is unknown (e.g. TOML files).
+type Node struct {
+	Kind    string
+	Value   any
+	Members []*Member
+	Items   []*Node
+	Line    int
+	Column  int
+}
+
+// Integer checks if the Node holds a number without a fractional part.
+func (n *Node) Integer() bool {
+	f, ok := n.Value.(float64)
+
+	return ok && n.Kind == KindNumber && f == math.Trunc(f)
+}
+
+// positions resolves byte offsets into line and column numbers.
+type positions []int
+
+// newPositions creates a new positions instance from the data line breaks.
+func newPositions(data []byte) positions {
+	pos := positions{0}
+
+	for i, b := range data {
+		if b == '\n' {
+			pos = append(pos, i+1)
+		}
+	}
+
+	return pos
+}
+
+// resolve the line and column of a byte offset.
+func (p positions) resolve(offset int) (int, int) {
+	line := sort.Search(len(p), func(i int) bool {
+		return p[i] > offset
+	})
+
+	return line, offset - p[line-1] + 1
+}
+
+// jsonParser builds a Node tree from JSON data.
+type jsonParser struct {
+	dec  *jsontext.Decoder
+	data []byte
+	pos  positions
+}
+
+// position of the next token, skipping any whitespace and separators.
+func (jp *jsonParser) position() (int, int) {
+	offset := int(jp.dec.InputOffset())
+
+	for offset < len(jp.data) && bytes.IndexByte([]byte(" \t\r\n,:"), jp.data[offset]) >= 0 {
+		offset++
+	}
+
+	return jp.pos.resolve(offset)
+}
+
+// parse the next JSON value.
+func (jp *jsonParser) parse() (*Node, error) {
+	line, col := jp.position()
+
+	tok, err := jp.dec.ReadToken()
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Node{
+		Line:   line,
+		Column: col,
+	}
+
+	switch tok.Kind() {
+	case '{':
+		n.Kind = KindObject
+
+		for jp.dec.PeekKind() != '}' {
+			line, col = jp.position()
+
+			tok, err := jp.dec.ReadToken()
+			if err != nil {
+				return nil, err
+			}
+
+			// Tokens are voided by subsequent reads
+			key := tok.String()
+
+			value, err := jp.parse()
+			if err != nil {
+				return nil, err
+			}
+
+			n.Members = append(n.Members, &Member{
+				Key:    key,
+				Value:  value,
+				Line:   line,
+				Column: col,
+			})
+		}
+
+		if _, err = jp.dec.ReadToken(); err != nil {
+			return nil, err
+		}
+
+	case '[':
+		n.Kind = KindArray
+
+		for jp.dec.PeekKind() != ']' {
+			item, err := jp.parse()
+			if err != nil {
+				return nil, err
+			}
+
+			n.Items = append(n.Items, item)
+		}
+
+		if _, err = jp.dec.ReadToken(); err != nil {
+			return nil, err
+		}
+
+	case '"':
+		n.Kind = KindString
+		n.Value = tok.String()
+
+	case '0':
+		f, err := strconv.ParseFloat(tok.String(), 64)
+		if err != nil {
+			return nil, err
+		}
+
+		n.Kind = KindNumber
+		n.Value = f
+
+	case 't', 'f':
+		n.Kind = KindBoolean
+		n.Value = tok.Bool()
+
+	default:
+		n.Kind = KindNull
+	}
+
+	return n, nil
+}
+
+// parseJSON builds a Node tree from JSON data.
+func parseJSON(data []byte) (*Node, error) {
+	jp := &jsonParser{
+		dec:  jsontext.NewDecoder(bytes.NewReader(data)),
+		data: data,
+		pos:  newPositions(data),
+	}
+
+	return jp.parse()
+}
+
+// fromYAML builds a Node tree from a YAML node.
+func fromYAML(yn *yaml.Node) (*Node, error) {
+	n := &Node{
+		Line:   yn.Line,
+		Column: yn.Column,
+	}
+
+	switch yn.Kind {
+	case yaml.DocumentNode:
+		if len(yn.Content) == 0 {
+			n.Kind = KindObject
+			return n, nil
+		}
+
+		return fromYAML(yn.Content[0])
+
+	case yaml.AliasNode:
+		return fromYAML(yn.Alias)
+
+	case yaml.MappingNode:
+		n.Kind = KindObject
+
+		for i := 0; i+1 < len(yn.Content); i += 2 {
+			value, err := fromYAML(yn.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+
+			n.Members = append(n.Members, &Member{
+				Key:    yn.Content[i].Value,
+				Value:  value,
+				Line:   yn.Content[i].Line,
+				Column: yn.Content[i].Column,
+			})
+		}
+
+	case yaml.SequenceNode:
+		n.Kind = KindArray
+
+		for _, elem := range yn.Content {
+			item, err := fromYAML(elem)
+			if err != nil {
+				return nil, err
+			}
+
+			n.Items = append(n.Items, item)
+		}
+
+	case yaml.ScalarNode:
+		var value any
+		if err := yn.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		switch v := value.(type) {
+		case nil:
+			n.Kind = KindNull
+
+		case bool:
+			n.Kind = KindBoolean
+			n.Value = v
+
+		case int:
+			n.Kind = KindNumber
+			n.Value = float64(v)
+
+		case int64:
+			n.Kind = KindNumber
+			n.Value = float64(v)
+
+		case uint64:
+			n.Kind = KindNumber
+			n.Value = float64(v)
+
+		case float64:
+			n.Kind = KindNumber
+			n.Value = v
+
+		default:
+			n.Kind = KindString
+			n.Value = fmt.Sprint(v)
+		}
+	}
+
+	return n, nil
+}
+
+// ParseNode builds a Node tree from data in the given format (see FileFormat).
+// TOML data is converted to JSON beforehand, so its Node positions are unknown.
+func ParseNode(format string, data []byte) (*Node, error) {
+	switch format {
+	case FormatYAML:
+		var yn yaml.Node
+		if err := yaml.Unmarshal(data, &yn); err != nil {
+			return nil, err
+		}
+
+		// Empty documents are treated as empty objects
+		if yn.Kind == 0 {
+			return &Node{Kind: KindObject}, nil
+		}
+
+		return fromYAML(&yn)
+
+	case FormatTOML:
+		data, err := ToJSON(format, data)
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := parseJSON(data)
+		if err != nil {
+			return nil, err
+		}
+
+		n.clearPositions()
+
+		return n, nil
+	}
+
+	return parseJSON(data)
+}
+
+// clearPositions resets the positions of a Node tree, when they don't match the source file.
+func (n *Node) clearPositions() {
+	n.Line, n.Column = 0, 0
+
+	for _, m := range n.Members {
+		m.Line, m.Column = 0, 0
+		m.Value.clearPositions()
+	}
+
+	for _, item := range n.Items {
+		item.clearPositions()
+	}
+}
@@ -0,0 +1,168 @@
+package device
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNode(t *testing.T) {
+	tests := []struct {
+		expected *Node
+		name     string
+		format   string
+		data     string
+		failure  bool
+	}{
+		{
+			name:    "failure: invalid JSON",
+			format:  FormatJSON,
+			data:    `{"foo":}`,
+			failure: true,
+		},
+		{
+			name:    "failure: invalid YAML",
+			format:  FormatYAML,
+			data:    "foo: [bar",
+			failure: true,
+		},
+		{
+			name:    "failure: invalid TOML",
+			format:  FormatTOML,
+			data:    "foo = ",
+			failure: true,
+		},
+		{
+			name:   "success: JSON",
+			format: FormatJSON,
+			data:   "{\n  \"foo\": [1.5, true],\n  \"bar\": null\n}",
+			expected: &Node{
+				Kind: KindObject,
+				Members: []*Member{
+					{
+						Key: "foo",
+						Value: &Node{
+							Kind: KindArray,
+							Items: []*Node{
+								{Kind: KindNumber, Value: 1.5, Line: 2, Column: 11},
+								{Kind: KindBoolean, Value: true, Line: 2, Column: 16},
+							},
+							Line:   2,
+							Column: 10,
+						},
+						Line:   2,
+						Column: 3,
+					},
+					{
+						Key:    "bar",
+						Value:  &Node{Kind: KindNull, Line: 3, Column: 10},
+						Line:   3,
+						Column: 3,
+					},
+				},
+				Line:   1,
+				Column: 1,
+			},
+		},
+		{
+			name:   "success: YAML",
+			format: FormatYAML,
+			data:   "foo:\n  - 1\n  - bar\n",
+			expected: &Node{
+				Kind: KindObject,
+				Members: []*Member{
+					{
+						Key: "foo",
+						Value: &Node{
+							Kind: KindArray,
+							Items: []*Node{
+								{Kind: KindNumber, Value: float64(1), Line: 2, Column: 5},
+								{Kind: KindString, Value: "bar", Line: 3, Column: 5},
+							},
+							Line:   2,
+							Column: 3,
+						},
+						Line:   1,
+						Column: 1,
+					},
+				},
+				Line:   1,
+				Column: 1,
+			},
+		},
+		{
+			name:   "success: empty YAML",
+			format: FormatYAML,
+			data:   "",
+			expected: &Node{
+				Kind: KindObject,
+			},
+		},
+		{
+			name:   "success: TOML",
+			format: FormatTOML,
+			data:   "foo = \"bar\"\n",
+			expected: &Node{
+				Kind: KindObject,
+				Members: []*Member{
+					{
+						Key:   "foo",
+						Value: &Node{Kind: KindString, Value: "bar"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := ParseNode(test.format, []byte(test.data))
+
+			if test.failure {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			if !reflect.DeepEqual(n, test.expected) {
+				t.Fatalf("expected %#v, got %#v", test.expected, n)
+			}
+		})
+	}
+}
+
+func TestNode_Integer(t *testing.T) {
+	tests := []struct {
+		node     *Node
+		name     string
+		expected bool
+	}{
+		{
+			name:     "integer",
+			node:     &Node{Kind: KindNumber, Value: float64(3)},
+			expected: true,
+		},
+		{
+			name:     "fraction",
+			node:     &Node{Kind: KindNumber, Value: 3.5},
+			expected: false,
+		},
+		{
+			name:     "string",
+			node:     &Node{Kind: KindString, Value: "3"},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.node.Integer() != test.expected {
+				t.Fatalf("expected %t, got %t", test.expected, test.node.Integer())
+			}
+		})
+	}
+}
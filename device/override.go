@@ -0,0 +1,79 @@
+package device
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"reflect"
+)
+
+// Override holds partial configuration settings, to be merged over
+// a base Config for the IoT devices matching its criteria.
+type Override struct {
+	Match    *Matcher       `json:"match"`
+	Settings jsontext.Value `json:"settings"`
+}
+
+// Overrider is implemented by Config values that support per device overrides.
+type Overrider interface {
+	DeviceOverrides() []*Override
+}
+
+// decodeOverride decodes the partial settings of an Override into a Config of the same type as the base.
+func decodeOverride(base Config, ovr *Override) (Config, error) {
+	partial := reflect.New(reflect.TypeOf(base).Elem()).Interface().(Config)
+
+	if err := json.Unmarshal(ovr.Settings, partial); err != nil {
+		return nil, err
+	}
+
+	return partial, nil
+}
+
+// validateOverrides ensures the partial settings of every Override in a Config can be decoded.
+func validateOverrides(cfg Config) error {
+	ovr, ok := cfg.(Overrider)
+	if !ok {
+		return nil
+	}
+
+	for _, o := range ovr.DeviceOverrides() {
+		if o.Match == nil {
+			return ErrOverrideMatchMissing
+		}
+
+		if _, err := decodeOverride(cfg, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ApplyOverrides returns a Config holding the base settings, with the settings of every
+// Override matching the device merged over them, in the order they were defined.
+func ApplyOverrides(cfg Config, res Resource) (Config, error) {
+	ovr, ok := cfg.(Overrider)
+	if !ok {
+		return cfg, nil
+	}
+
+	merged := cfg
+
+	for _, o := range ovr.DeviceOverrides() {
+		if o.Match == nil || !o.Match.Contains(res) {
+			continue
+		}
+
+		partial, err := decodeOverride(cfg, o)
+		if err != nil {
+			return nil, err
+		}
+
+		merged, err = MergeConfig(merged, partial)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return merged, nil
+}
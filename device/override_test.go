@@ -0,0 +1,161 @@
+package device
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	base := &templateConfig{
+		Sys: &settings{
+			"config": map[string]any{
+				"device": map[string]any{
+					"name": "plug",
+				},
+			},
+		},
+		Overrides: &[]*Override{
+			{
+				Match: &Matcher{
+					Models: []string{"^SNPL"},
+				},
+				Settings: jsontext.Value(`{"sys":{"config":{"device":{"name":"server"}}}}`),
+			},
+			{
+				Match: &Matcher{
+					Devices: []net.HardwareAddr{
+						{0xAA, 0xBB, 0xCC, 0xDD, 0xEE, 0x01},
+					},
+				},
+				Settings: jsontext.Value(`{"switch":[{"id":0,"config":{"auto_off":true}}]}`),
+			},
+			{
+				Match: &Matcher{
+					Names: []string{"^kitchen"},
+				},
+				Settings: jsontext.Value(`{"sys":{"config":{"device":{"name":"kitchen"}}}}`),
+			},
+		},
+	}
+
+	tests := []struct {
+		cfg      Config
+		expected Config
+		err      error
+		name     string
+	}{
+		{
+			name:     "success: config without overrides",
+			cfg:      &config{Foo: "bar"},
+			expected: &config{Foo: "bar"},
+		},
+		{
+			name: "failure: invalid override settings",
+			cfg: &templateConfig{
+				Overrides: &[]*Override{
+					{
+						Match:    &Matcher{Models: []string{"SNPL"}},
+						Settings: jsontext.Value(`{"sys":"foo"}`),
+					},
+				},
+			},
+			err: &json.SemanticError{},
+		},
+		{
+			name: "success: matching overrides merged in order",
+			cfg:  base,
+			expected: &templateConfig{
+				Sys: &settings{
+					"config": map[string]any{
+						"device": map[string]any{
+							"name": "server",
+						},
+					},
+				},
+				Switch: &[]*settings{
+					{
+						"id": float64(0),
+						"config": map[string]any{
+							"auto_off": true,
+						},
+					},
+				},
+				Overrides: base.Overrides,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg, err := ApplyOverrides(test.cfg, templateResource)
+
+			if !reflect.DeepEqual(cfg, test.expected) {
+				t.Fatalf("expected %#v, got %#v", test.expected, cfg)
+			}
+
+			var semanticError *json.SemanticError
+			switch {
+			case errors.As(test.err, &semanticError):
+				var se *json.SemanticError
+				if errors.As(err, &se) {
+					return
+				}
+
+			case errors.Is(err, test.err):
+				return
+			}
+
+			t.Fatalf("expected %#v, got %#v", test.err, err)
+		})
+	}
+}
+
+func TestNewConfig_Overrides(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		data string
+	}{
+		{
+			name: "failure: override match missing",
+			data: `{"sys":{},"overrides":[{"settings":{"sys":{}}}]}`,
+			err:  ErrOverrideMatchMissing,
+		},
+		{
+			name: "failure: invalid override settings",
+			data: `{"sys":{},"overrides":[{"match":{"models":["SNPL"]},"settings":{"switch":{}}}]}`,
+			err:  &json.SemanticError{},
+		},
+		{
+			name: "success",
+			data: `{"sys":{},"overrides":[{"match":{"devices":["AA:BB:CC:DD:EE:01"]},"settings":{"sys":{}}}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewConfig(strings.NewReader(test.data), func() Config {
+				return &templateConfig{}
+			})
+
+			var semanticError *json.SemanticError
+			switch {
+			case errors.As(test.err, &semanticError):
+				var se *json.SemanticError
+				if errors.As(err, &se) {
+					return
+				}
+
+			case errors.Is(err, test.err):
+				return
+			}
+
+			t.Fatalf("expected %#v, got %#v", test.err, err)
+		})
+	}
+}
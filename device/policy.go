@@ -54,25 +54,56 @@ type Policy struct {
 
 // Contains checks whether a device model or MAC address exists in the Policy.
 func (p *Policy) Contains(dev Resource) bool {
-	for _, name := range p.Names {
-		if regexp.MustCompile(name).MatchString(dev.Name()) {
-			return true
-		}
+	m := &Matcher{
+		Names:   p.Names,
+		Models:  p.Models,
+		Devices: p.Devices,
 	}
 
-	for _, model := range p.Models {
-		if regexp.MustCompile(model).MatchString(dev.Model()) {
-			return true
+	return m.Contains(dev)
+}
+
+// checkPatterns checks that every name and model pattern is a valid regular expression,
+// so an invalid one is reported on load, rather than failing when a device is matched.
+func checkPatterns(patterns ...[]string) error {
+	for _, list := range patterns {
+		for _, pattern := range list {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("%w: %w", errPolicyPatternInvalid, err)
+			}
 		}
 	}
 
-	for _, mac := range p.Devices {
-		if bytes.Equal(mac, dev.MAC()) {
-			return true
+	return nil
+}
+
+// NewPolicy creates a new *Policy instance, parsing the device MAC addresses.
+// It returns an error if the mode is undefined, or any of the patterns or MAC addresses is invalid.
+func NewPolicy(mode PolicyMode, names, models, devices []string) (*Policy, error) {
+	if mode == PolicyModeUndefined {
+		return nil, errPolicyModeUndefined
+	}
+
+	if err := checkPatterns(names, models); err != nil {
+		return nil, err
+	}
+
+	p := &Policy{
+		Names:  names,
+		Models: models,
+		Mode:   mode,
+	}
+
+	for _, dev := range devices {
+		mac, err := net.ParseMAC(dev)
+		if err != nil {
+			return nil, err
 		}
+
+		p.Devices = append(p.Devices, mac)
 	}
 
-	return false
+	return p, nil
 }
 
 // IsExcluded determines whether a device should be excluded based on the Policy mode
@@ -107,6 +138,10 @@ func (p *Policy) UnmarshalJSON(data []byte) error {
 		return errPolicyModeUndefined
 	}
 
+	if err := checkPatterns(tmp.Names, tmp.Models); err != nil {
+		return err
+	}
+
 	p.Mode = tmp.Type
 	p.Names = tmp.Names
 	p.Models = tmp.Models
@@ -125,3 +160,64 @@ func (p *Policy) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// Matcher holds the policy-style criteria (name, model and MAC address) used to match IoT devices.
+type Matcher struct {
+	Names   []string           `json:"names"`
+	Models  []string           `json:"models"`
+	Devices []net.HardwareAddr `json:"devices"`
+}
+
+// Contains checks whether a device name, model or MAC address matches the Matcher criteria.
+func (m *Matcher) Contains(dev Resource) bool {
+	for _, name := range m.Names {
+		if regexp.MustCompile(name).MatchString(dev.Name()) {
+			return true
+		}
+	}
+
+	for _, model := range m.Models {
+		if regexp.MustCompile(model).MatchString(dev.Model()) {
+			return true
+		}
+	}
+
+	for _, mac := range m.Devices {
+		if bytes.Equal(mac, dev.MAC()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UnmarshalJSON implements the Unmarshaler interface.
+func (m *Matcher) UnmarshalJSON(data []byte) error {
+	var tmp struct {
+		Names   []string `json:"names"`
+		Models  []string `json:"models"`
+		Devices []string `json:"devices"`
+	}
+
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if err := checkPatterns(tmp.Names, tmp.Models); err != nil {
+		return err
+	}
+
+	m.Names = tmp.Names
+	m.Models = tmp.Models
+
+	for _, dev := range tmp.Devices {
+		mac, err := net.ParseMAC(dev)
+		if err != nil {
+			return err
+		}
+
+		m.Devices = append(m.Devices, mac)
+	}
+
+	return nil
+}
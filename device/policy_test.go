@@ -53,6 +53,12 @@ func TestPolicy_UnmarshalJSON(t *testing.T) {
 				Mode: PolicyModeBlacklist,
 			},
 		},
+		{
+			name:   "failure: invalid name pattern",
+			data:   `{"mode":"whitelist","names":["kitchen("]}`,
+			policy: &Policy{},
+			err:    errPolicyPatternInvalid,
+		},
 		{
 			name: "failure: invalid device MAC address",
 			data: `{
@@ -137,6 +143,64 @@ func TestPolicy_UnmarshalJSON(t *testing.T) {
 
 var macAddr = net.HardwareAddr{20, 6, 18, 220, 122, 240}
 
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		err     error
+		policy  *Policy
+		name    string
+		models  []string
+		devices []string
+		mode    PolicyMode
+	}{
+		{
+			name: "failure: undefined policy mode",
+			err:  errPolicyModeUndefined,
+		},
+		{
+			name:   "failure: invalid model pattern",
+			mode:   PolicyModeWhitelist,
+			models: []string{"SHSW-["},
+			err:    errPolicyPatternInvalid,
+		},
+		{
+			name:    "failure: invalid device MAC address",
+			mode:    PolicyModeWhitelist,
+			devices: []string{"foo"},
+			err:     &net.AddrError{},
+		},
+		{
+			name:    "success",
+			mode:    PolicyModeBlacklist,
+			models:  []string{"SNSW-001X16EU"},
+			devices: []string{"14:06:12:DC:7A:F0"},
+			policy: &Policy{
+				Mode:    PolicyModeBlacklist,
+				Models:  []string{"SNSW-001X16EU"},
+				Devices: []net.HardwareAddr{macAddr},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := NewPolicy(test.mode, nil, test.models, test.devices)
+
+			var addrError *net.AddrError
+			if errors.As(test.err, &addrError) {
+				if !errors.As(err, &addrError) {
+					t.Fatalf("expected %T, got %#v", test.err, err)
+				}
+			} else if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if !reflect.DeepEqual(policy, test.policy) {
+				t.Fatalf("expected %#v, got %#v", test.policy, policy)
+			}
+		})
+	}
+}
+
 func TestPolicy_Contains(t *testing.T) {
 	tests := []struct {
 		dev       Resource
@@ -341,3 +405,119 @@ func TestPolicy_IsExcluded(t *testing.T) {
 		})
 	}
 }
+
+func TestMatcher_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		err     error
+		matcher *Matcher
+		name    string
+		data    string
+	}{
+		{
+			name:    "failure: invalid JSON",
+			data:    `[`,
+			matcher: &Matcher{},
+			err:     &jsontext.SyntacticError{},
+		},
+		{
+			name:    "failure: invalid model pattern",
+			data:    `{"names":["^kitchen"],"models":["SHSW-["]}`,
+			matcher: &Matcher{},
+			err:     errPolicyPatternInvalid,
+		},
+		{
+			name: "failure: invalid device MAC address",
+			data: `{"names":["^kitchen"],"devices":["foo"]}`,
+			matcher: &Matcher{
+				Names: []string{"^kitchen"},
+			},
+			err: &net.AddrError{},
+		},
+		{
+			name: "success",
+			data: `{"models":["SNSW"],"devices":["14:06:12:DC:7A:F0"]}`,
+			matcher: &Matcher{
+				Models:  []string{"SNSW"},
+				Devices: []net.HardwareAddr{macAddr},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matcher := &Matcher{}
+
+			err := json.Unmarshal([]byte(test.data), matcher)
+
+			if !reflect.DeepEqual(matcher, test.matcher) {
+				t.Fatalf("expected %#v, got %#v", test.matcher, matcher)
+			}
+
+			var syntaxError *jsontext.SyntacticError
+			var addrError *net.AddrError
+			switch {
+			case errors.As(test.err, &syntaxError):
+				var se *jsontext.SyntacticError
+				if errors.As(err, &se) {
+					return
+				}
+
+			case errors.As(test.err, &addrError):
+				var ae *net.AddrError
+				if errors.As(err, &ae) {
+					return
+				}
+
+			case errors.Is(err, test.err):
+				return
+
+			default:
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestMatcher_Contains(t *testing.T) {
+	tests := []struct {
+		matcher  *Matcher
+		name     string
+		contains bool
+	}{
+		{
+			name:     "empty matcher",
+			matcher:  &Matcher{},
+			contains: false,
+		},
+		{
+			name:     "match by name",
+			matcher:  &Matcher{Names: []string{"^plug"}},
+			contains: true,
+		},
+		{
+			name:     "match by model",
+			matcher:  &Matcher{Models: []string{"SNPL-.+"}},
+			contains: true,
+		},
+		{
+			name: "match by MAC address",
+			matcher: &Matcher{Devices: []net.HardwareAddr{
+				{0xAA, 0xBB, 0xCC, 0xDD, 0xEE, 0x01},
+			}},
+			contains: true,
+		},
+		{
+			name:     "no match",
+			matcher:  &Matcher{Names: []string{"^kitchen"}, Models: []string{"SHSW"}},
+			contains: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.matcher.Contains(templateResource) != test.contains {
+				t.Fatalf("expected %t, got %t", test.contains, !test.contains)
+			}
+		})
+	}
+}
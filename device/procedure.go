@@ -1,6 +1,11 @@
 package device
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/quetzyg/IoTap/httpclient"
+)
 
 // procedure is a function type that encapsulates operations to be carried out on IoT devices.
 type procedure func(tap *Tapper, res Resource, ch chan<- *ProcedureResult)
@@ -18,16 +23,38 @@ func (pr *ProcedureResult) Failed() bool {
 }
 
 // Error interface implementation for ProcedureResult.
+// Resolved secrets are redacted from the error message.
 func (pr *ProcedureResult) Error() string {
 	if pr.dev == nil {
-		return pr.err.Error()
+		return Redact(pr.err.Error())
 	}
 
-	return fmt.Sprintf(
+	return Redact(fmt.Sprintf(
 		"[%s] %s @ %s: %v",
 		pr.dev.Driver(),
 		pr.dev.ID(),
 		pr.dev.IP(),
 		pr.err,
-	)
+	))
+}
+
+// dispatch a sequence of requests to an IoT device, stopping at the first failure.
+func dispatch(tap *Tapper, res Resource, rs []*http.Request) error {
+	dispatcher := httpclient.NewDispatcher(&http.Client{
+		Transport: tap.transport,
+	})
+
+	var opts []httpclient.DispatchOption
+
+	if challenger, ok := res.(httpclient.Challenger); ok {
+		opts = append(opts, httpclient.WithChallenger(challenger))
+	}
+
+	for _, r := range rs {
+		if err := dispatcher.Dispatch(r, opts...); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
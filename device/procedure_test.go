@@ -8,10 +8,11 @@ import (
 
 func TestProcedureResult_Error(t *testing.T) {
 	tests := []struct {
-		name string
-		dev  Resource
-		err  error
-		out  string
+		name    string
+		dev     Resource
+		err     error
+		out     string
+		secrets []string
 	}{
 		{
 			name: "error without device details",
@@ -28,10 +29,24 @@ func TestProcedureResult_Error(t *testing.T) {
 			err: errors.New("some error"),
 			out: "[driver] 14:06:12:dc:7a:f0 @ 192.168.146.123: some error",
 		},
+		{
+			name:    "error with redacted secret",
+			err:     errors.New("invalid password P@ssw0rd"),
+			secrets: []string{"P@ssw0rd"},
+			out:     "invalid password [REDACTED]",
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				secrets.values = make(map[string]struct{})
+			})
+
+			for _, s := range test.secrets {
+				secrets.register(s)
+			}
+
 			perr := &ProcedureResult{
 				dev: test.dev,
 				err: test.err,
@@ -0,0 +1,77 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/quetzyg/IoTap/httpclient"
+)
+
+var (
+	// readyTimeout is the maximum amount of time to wait for a device to come back online after a reboot.
+	readyTimeout = time.Minute * 2
+
+	// readyInterval is the amount of time between device readiness checks.
+	readyInterval = time.Second * 3
+)
+
+// Profiler is an interface that provides a standard way to switch the profile of IoT devices.
+// The profile determines which components a device has, so it must be switched, and the device
+// rebooted, before the remaining configuration is applied. A nil request means no switch is needed.
+type Profiler interface {
+	Rebooter
+	ProfileRequest(Config) (*http.Request, error)
+	ReadyRequest() (*http.Request, error)
+}
+
+// awaitReady waits for a device to come back online, after being rebooted.
+func awaitReady(dev Profiler, dispatcher *httpclient.Dispatcher) error {
+	r, err := dev.ReadyRequest()
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(readyTimeout)
+
+	for {
+		// Give the device time to go offline, before checking on it
+		time.Sleep(readyInterval)
+
+		// Bound each check by the time left, since a rebooting device may accept connections, yet never answer
+		ctx, cancel := context.WithDeadline(r.Context(), deadline)
+		err = dispatcher.Dispatch(r.WithContext(ctx))
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: waited %s", ErrDeviceNotReady, readyTimeout)
+		}
+	}
+}
+
+// switchProfile switches the profile of a device, if needed, and waits for it to reboot.
+func switchProfile(dev Profiler, cfg Config, dispatcher *httpclient.Dispatcher, opts []httpclient.DispatchOption) error {
+	r, err := dev.ProfileRequest(cfg)
+	if err != nil || r == nil {
+		return err
+	}
+
+	if err = dispatcher.Dispatch(r, opts...); err != nil {
+		return err
+	}
+
+	if r, err = dev.RebootRequest(); err != nil {
+		return err
+	}
+
+	if err = dispatcher.Dispatch(r, opts...); err != nil {
+		return err
+	}
+
+	return awaitReady(dev, dispatcher)
+}
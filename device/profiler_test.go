@@ -0,0 +1,134 @@
+package device
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quetzyg/IoTap/httpclient"
+)
+
+type profiler struct {
+	funcError error
+	unchanged bool
+	configurer
+}
+
+func (p *profiler) ProfileRequest(Config) (*http.Request, error) {
+	if p.funcError != nil {
+		return nil, p.funcError
+	}
+
+	if p.unchanged {
+		return nil, nil
+	}
+
+	return &http.Request{
+		URL:    &url.URL{},
+		Method: http.MethodPost,
+	}, nil
+}
+
+func (p *profiler) RebootRequest() (*http.Request, error) {
+	return &http.Request{
+		URL:    &url.URL{},
+		Method: http.MethodPost,
+	}, nil
+}
+
+func (p *profiler) ReadyRequest() (*http.Request, error) {
+	return &http.Request{
+		URL:    &url.URL{},
+		Method: http.MethodGet,
+	}, nil
+}
+
+func TestSwitchProfile(t *testing.T) {
+	timeout, interval := readyTimeout, readyInterval
+
+	t.Cleanup(func() {
+		readyTimeout, readyInterval = timeout, interval
+	})
+
+	readyTimeout, readyInterval = time.Millisecond*5, time.Millisecond
+
+	ok := func() *roundTripper {
+		return &roundTripper{
+			response: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("{}")),
+			},
+		}
+	}
+
+	down := &roundTripper{
+		err: &url.Error{Err: errors.New("connection refused")},
+	}
+
+	tests := []struct {
+		rt   http.RoundTripper
+		dev  *profiler
+		err  error
+		name string
+	}{
+		{
+			name: "failure: function error",
+			dev: &profiler{
+				funcError: ErrDriverMismatch,
+			},
+			err: ErrDriverMismatch,
+		},
+		{
+			name: "failure: profile switch error",
+			dev:  &profiler{},
+			rt:   down,
+			err:  down.err,
+		},
+		{
+			name: "failure: device not ready",
+			dev:  &profiler{},
+			rt: &sequenceRoundTripper{
+				steps: []*roundTripper{ok(), ok(), down, down, down, down, down, down, down, down, down, down},
+			},
+			err: ErrDeviceNotReady,
+		},
+		{
+			name: "failure: device unresponsive",
+			dev:  &profiler{},
+			rt: &sequenceRoundTripper{
+				steps: []*roundTripper{ok(), ok(), {hang: true}},
+			},
+			err: ErrDeviceNotReady,
+		},
+		{
+			name: "success: profile unchanged",
+			dev: &profiler{
+				unchanged: true,
+			},
+		},
+		{
+			name: "success: device ready",
+			dev:  &profiler{},
+			rt: &sequenceRoundTripper{
+				steps: []*roundTripper{ok(), ok(), down, ok()},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dispatcher := httpclient.NewDispatcher(&http.Client{
+				Transport: test.rt,
+			})
+
+			err := switchProfile(test.dev, &config{}, dispatcher, nil)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+		})
+	}
+}
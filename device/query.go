@@ -0,0 +1,229 @@
+package device
+
+import (
+	"encoding/json/v2"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Document kinds, as fetched from IoT devices.
+const (
+	DocumentStatus = "status"
+	DocumentConfig = "config"
+)
+
+// Path is a field expression selecting a value within a JSON document, as a sequence of object keys
+// and array indices (e.g. wifi.sta.ssid, relays[0].ison, ["switch:0"].output).
+type Path struct {
+	expr  string
+	steps []string
+}
+
+// String returns the field expression of the Path.
+func (p *Path) String() string {
+	return p.expr
+}
+
+// ParsePath creates a new *Path instance by parsing a field expression.
+// Keys are separated by dots, with an optional leading dot, while brackets hold array indices,
+// or quoted keys holding dots or brackets (e.g. ["a.b"]). It returns an error if the expression is invalid.
+func ParsePath(expr string) (*Path, error) {
+	p := &Path{
+		expr: strings.TrimSpace(expr),
+	}
+
+	rest := strings.TrimPrefix(p.expr, ".")
+	if rest == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPath, expr)
+	}
+
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, `["`):
+			quoted, err := strconv.QuotedPrefix(rest[1:])
+			if err != nil || !strings.HasPrefix(rest[1+len(quoted):], "]") {
+				return nil, fmt.Errorf("%w: %q: invalid quoted key", ErrInvalidPath, expr)
+			}
+
+			key, _ := strconv.Unquote(quoted)
+
+			p.steps = append(p.steps, key)
+			rest = rest[len(quoted)+2:]
+
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: %q: unclosed bracket", ErrInvalidPath, expr)
+			}
+
+			if _, err := strconv.Atoi(rest[1:end]); err != nil {
+				return nil, fmt.Errorf("%w: %q: invalid index %q", ErrInvalidPath, expr, rest[1:end])
+			}
+
+			p.steps = append(p.steps, rest[1:end])
+			rest = rest[end+1:]
+
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+
+			p.steps = append(p.steps, rest[:end])
+			rest = rest[end:]
+		}
+
+		// Steps are followed by a bracket, a dot and a key, or nothing at all
+		if strings.HasPrefix(rest, ".") {
+			rest = rest[1:]
+
+			if rest == "" || rest[0] == '.' || rest[0] == '[' {
+				return nil, fmt.Errorf("%w: %q: empty key", ErrInvalidPath, expr)
+			}
+		} else if rest != "" && rest[0] != '[' {
+			return nil, fmt.Errorf("%w: %q: expected '.' or '[' before %q", ErrInvalidPath, expr, rest)
+		}
+	}
+
+	return p, nil
+}
+
+// Lookup returns the value the Path selects within a decoded JSON document, and whether it was found.
+// Numeric keys also select array elements (e.g. relays.0.ison).
+func (p *Path) Lookup(doc any) (any, bool) {
+	value := doc
+
+	for _, step := range p.steps {
+		switch v := value.(type) {
+		case map[string]any:
+			elem, ok := v[step]
+			if !ok {
+				return nil, false
+			}
+
+			value = elem
+
+		case []any:
+			i, err := strconv.Atoi(step)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+
+			value = v[i]
+
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// Query holds the kind of document to fetch from IoT devices, along with the fields to select within it.
+type Query struct {
+	Document string
+	Paths    []*Path
+}
+
+// NewQuery creates a new *Query instance, parsing the given field expressions.
+// It returns an error if no expressions are given, or if any of them is invalid.
+func NewQuery(document string, exprs []string) (*Query, error) {
+	if len(exprs) == 0 {
+		return nil, fmt.Errorf("%w: no field expressions", ErrInvalidPath)
+	}
+
+	q := &Query{
+		Document: document,
+	}
+
+	for _, expr := range exprs {
+		p, err := ParsePath(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		q.Paths = append(q.Paths, p)
+	}
+
+	return q, nil
+}
+
+// Select returns the values the Query fields select within a JSON document, in field order.
+// Strings are returned as they are and other values as compact JSON, while missing fields are left empty.
+func (q *Query) Select(data []byte) ([]string, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0, len(q.Paths))
+
+	for _, p := range q.Paths {
+		value, ok := p.Lookup(doc)
+		if !ok {
+			values = append(values, "")
+			continue
+		}
+
+		if s, ok := value.(string); ok {
+			values = append(values, s)
+			continue
+		}
+
+		b, err := json.Marshal(value, json.Deterministic(true))
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, string(b))
+	}
+
+	return values, nil
+}
+
+// Documenter is an interface that provides a standard way to fetch the status or configuration
+// document of IoT devices, as raw JSON.
+type Documenter interface {
+	Document(*http.Client, string) ([]byte, error)
+}
+
+// QueryDocument is a procedure implementation designed to add the Query field values of an IoT device
+// document to a Report, in a single row.
+var QueryDocument = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(Documenter)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: get", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	data, err := dev.Document(&http.Client{
+		Transport: tap.transport,
+	}, tap.query.Document)
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	values, err := tap.query.Select(data)
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	tap.report.Add(res, values...)
+
+	ch <- &ProcedureResult{
+		dev: res,
+	}
+}
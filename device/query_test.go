@@ -0,0 +1,177 @@
+package device
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const statusDocument = `{
+  "cloud": {"connected": true},
+  "switch:0": {"id": 0, "output": false, "aenergy": {"total": 12.5}},
+  "wifi": {"sta_ip": "192.168.1.10", "ssid": null},
+  "relays": [{"ison": true}, {"ison": false}],
+  "a.b": {"c": 1}
+}`
+
+type documenter struct {
+	funcError error
+	kind      string
+	resource
+}
+
+func (d *documenter) Document(_ *http.Client, kind string) ([]byte, error) {
+	if d.funcError != nil {
+		return nil, d.funcError
+	}
+
+	d.kind = kind
+
+	return []byte(statusDocument), nil
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		err   error
+		name  string
+		expr  string
+		steps []string
+	}{
+		{name: "failure: empty", expr: " . ", err: ErrInvalidPath},
+		{name: "failure: empty key", expr: "cloud..connected", err: ErrInvalidPath},
+		{name: "failure: trailing dot", expr: "cloud.", err: ErrInvalidPath},
+		{name: "failure: dot before bracket", expr: "relays.[0]", err: ErrInvalidPath},
+		{name: "failure: unclosed bracket", expr: "relays[0", err: ErrInvalidPath},
+		{name: "failure: invalid index", expr: "relays[first]", err: ErrInvalidPath},
+		{name: "failure: missing separator", expr: "relays[0]ison", err: ErrInvalidPath},
+		{name: "failure: invalid quoted key", expr: `["a.b]`, err: ErrInvalidPath},
+		{name: "success: keys", expr: "cloud.connected", steps: []string{"cloud", "connected"}},
+		{name: "success: leading dot", expr: " .wifi.sta_ip ", steps: []string{"wifi", "sta_ip"}},
+		{name: "success: colon key", expr: "switch:0.output", steps: []string{"switch:0", "output"}},
+		{name: "success: indices", expr: "relays[1].ison", steps: []string{"relays", "1", "ison"}},
+		{name: "success: quoted key", expr: `["a.b"].c`, steps: []string{"a.b", "c"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := ParsePath(test.expr)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(p.steps, test.steps) {
+				t.Fatalf("expected %q, got %q", test.steps, p.steps)
+			}
+		})
+	}
+}
+
+func TestQuery_Select(t *testing.T) {
+	q, err := NewQuery(DocumentStatus, []string{
+		"cloud.connected",
+		"wifi.sta_ip",
+		"wifi.ssid",
+		"switch:0",
+		"switch:0.aenergy.total",
+		"relays.1.ison",
+		"relays[5].ison",
+		`["a.b"].c`,
+		"cloud.connected.foo",
+	})
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	values, err := q.Select([]byte(statusDocument))
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := []string{
+		"true",
+		"192.168.1.10",
+		"null",
+		`{"aenergy":{"total":12.5},"id":0,"output":false}`,
+		"12.5",
+		"false",
+		"",
+		"1",
+		"",
+	}
+
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected %q, got %q", expected, values)
+	}
+
+	if _, err = q.Select([]byte("<html>")); err == nil {
+		t.Fatal("expected an error for an invalid document")
+	}
+
+	if _, err = NewQuery(DocumentStatus, nil); !errors.Is(err, ErrInvalidPath) {
+		t.Fatalf("expected %#v, got %#v", ErrInvalidPath, err)
+	}
+}
+
+func TestQueryDocument(t *testing.T) {
+	tests := []struct {
+		dev  Resource
+		err  error
+		name string
+		rows [][]string
+	}{
+		{
+			name: "failure: unsupported procedure",
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: function error",
+			dev:  &documenter{funcError: ErrUnexpected},
+			err:  ErrUnexpected,
+		},
+		{
+			name: "success",
+			dev:  &documenter{},
+			rows: [][]string{
+				{"true", "192.168.1.10"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, _ := NewQuery(DocumentConfig, []string{"cloud.connected", "wifi.sta_ip"})
+
+			tap := &Tapper{
+				query:  q,
+				report: NewReport(),
+			}
+
+			ch := make(chan *ProcedureResult, 1)
+
+			QueryDocument(tap, test.dev, ch)
+
+			if result := <-ch; !errors.Is(result.err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, result.err)
+			}
+
+			var rows [][]string
+			for _, row := range tap.report.rows {
+				rows = append(rows, row.values[len(reportHeader):])
+			}
+
+			if !reflect.DeepEqual(rows, test.rows) {
+				t.Fatalf("expected %q, got %q", test.rows, rows)
+			}
+
+			if d, ok := test.dev.(*documenter); ok && test.rows != nil && d.kind != DocumentConfig {
+				t.Fatalf("expected %q, got %q", DocumentConfig, d.kind)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package device
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// reportHeader holds the columns identifying the device of each Report row.
+var reportHeader = []string{
+	"IP",
+	"MAC Address",
+	"Name",
+}
+
+// reportRow is a Report entry, along with the IP of the device it belongs to.
+type reportRow struct {
+	ip     net.IP
+	values []string
+}
+
+// Report holds the tabular results of a procedure, with any number of rows per device.
+// Rows can be added concurrently, and are output in IP order, regardless of the order they were added in.
+type Report struct {
+	raw    map[string]bool
+	header []string
+	rows   []*reportRow
+	mu     sync.Mutex
+	keyed  bool
+}
+
+// NewReport creates a new *Report instance with the given columns.
+func NewReport(header ...string) *Report {
+	return &Report{
+		header: slices.Concat(reportHeader, header),
+	}
+}
+
+// Raw marks the columns holding JSON documents (e.g. RPC responses), which are written as they are
+// in JSON format, rather than as strings. Empty values are written as null.
+func (r *Report) Raw(columns ...string) *Report {
+	if r.raw == nil {
+		r.raw = make(map[string]bool, len(columns))
+	}
+
+	for _, column := range columns {
+		r.raw[column] = true
+	}
+
+	return r
+}
+
+// Keyed lays the Report out as an object keyed by device MAC address in JSON format, instead of a list.
+// It's meant for Reports holding a single row per device, since duplicate keys are rejected.
+func (r *Report) Keyed() *Report {
+	r.keyed = true
+
+	return r
+}
+
+// Add a row of values for a device.
+func (r *Report) Add(res Resource, values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rows = append(r.rows, &reportRow{
+		ip:     res.IP(),
+		values: slices.Concat([]string{res.IP().String(), res.ID(), res.Name()}, values),
+	})
+}
+
+// Len returns the number of rows in the Report.
+func (r *Report) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.rows)
+}
+
+// sorted returns the Report rows, ordered by IP address first and by values next.
+func (r *Report) sorted() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	slices.SortStableFunc(r.rows, func(a, b *reportRow) int {
+		if c := bytes.Compare(a.ip.To16(), b.ip.To16()); c != 0 {
+			return c
+		}
+
+		return slices.Compare(a.values, b.values)
+	})
+
+	rows := make([][]string, 0, len(r.rows))
+	for _, row := range r.rows {
+		rows = append(rows, row.values)
+	}
+
+	return rows
+}
+
+// output returns the sorted Report rows, with every secret resolved by Interpolate redacted from their values.
+// Raw values are redacted within their JSON strings only (see redactJSON), so they remain valid JSON.
+func (r *Report) output() [][]string {
+	rows := r.sorted()
+
+	for i, row := range rows {
+		redacted := make([]string, len(row))
+
+		for j, value := range row {
+			if j < len(r.header) && r.raw[r.header[j]] {
+				redacted[j] = redactJSON(value)
+				continue
+			}
+
+			redacted[j] = Redact(value)
+		}
+
+		rows[i] = redacted
+	}
+
+	return rows
+}
+
+// writeCSV writes the Report to the provided io.Writer in CSV format.
+// Comma separated values are quoted as needed (e.g. JSON responses), while any other separator
+// is meant for the screen, with the values aligned in columns instead.
+func (r *Report) writeCSV(w io.Writer, sep string) error {
+	if sep != "," {
+		return r.writeTable(w, sep)
+	}
+
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(r.header); err != nil {
+		return err
+	}
+
+	if err := writer.WriteAll(r.output()); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+// writeTable writes the Report to the provided io.Writer, with its values aligned in columns.
+func (r *Report) writeTable(w io.Writer, sep string) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	defer func() {
+		if err := writer.Flush(); err != nil {
+			log.Printf("Writer flush error: %v", err)
+		}
+	}()
+
+	if _, err := fmt.Fprintln(writer, strings.Join(r.header, sep)); err != nil {
+		return err
+	}
+
+	for _, row := range r.output() {
+		if _, err := fmt.Fprintln(writer, strings.Join(row, sep)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeJSON writes the Report to the provided io.Writer in JSON format,
+// as a list of objects keyed by column, in column order (see Keyed and Raw).
+func (r *Report) writeJSON(w io.Writer) error {
+	enc := jsontext.NewEncoder(w, jsontext.WithIndent("  "))
+
+	begin, end := jsontext.BeginArray, jsontext.EndArray
+	if r.keyed {
+		begin, end = jsontext.BeginObject, jsontext.EndObject
+	}
+
+	if err := enc.WriteToken(begin); err != nil {
+		return err
+	}
+
+	for _, row := range r.output() {
+		// The MAC address column keys the device rows, rather than being one of their values
+		if r.keyed {
+			if err := enc.WriteToken(jsontext.String(row[1])); err != nil {
+				return err
+			}
+		}
+
+		if err := enc.WriteToken(jsontext.BeginObject); err != nil {
+			return err
+		}
+
+		for i, column := range r.header {
+			if r.keyed && i == 1 {
+				continue
+			}
+
+			if err := enc.WriteToken(jsontext.String(column)); err != nil {
+				return err
+			}
+
+			if err := r.writeValue(enc, column, row[i]); err != nil {
+				return err
+			}
+		}
+
+		if err := enc.WriteToken(jsontext.EndObject); err != nil {
+			return err
+		}
+	}
+
+	return enc.WriteToken(end)
+}
+
+// writeValue writes a Report value, as it is for raw columns, or as a string otherwise.
+func (r *Report) writeValue(enc *jsontext.Encoder, column, value string) error {
+	if !r.raw[column] {
+		return enc.WriteToken(jsontext.String(value))
+	}
+
+	if value == "" {
+		return enc.WriteToken(jsontext.Null)
+	}
+
+	return enc.WriteValue(jsontext.Value(value))
+}
+
+// ExecReport is a wrapper function to easily output a Report to multiple formats and outputs (see ExecDump).
+func ExecReport(rep *Report, format string, file string) error {
+	return writeOutput(file, func(w io.Writer, sep string) error {
+		switch format {
+		case FormatCSV:
+			return rep.writeCSV(w, sep)
+
+		case FormatJSON:
+			return rep.writeJSON(w)
+
+		default:
+			return fmt.Errorf("%w: %s", ErrInvalidDumpFormat, format)
+		}
+	})
+}
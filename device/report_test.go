@@ -0,0 +1,235 @@
+package device
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestReport returns a Report with rows added out of order, across two devices.
+func newTestReport() *Report {
+	rep := NewReport("ID", "Enabled")
+
+	storage := &resource{
+		ip:   net.ParseIP("192.168.146.123"),
+		mac:  net.HardwareAddr{00, 17, 34, 51, 68, 85},
+		name: "Storage",
+	}
+
+	kitchen := &resource{
+		ip:   net.ParseIP("192.168.146.99"),
+		mac:  net.HardwareAddr{00, 17, 34, 51, 68, 86},
+		name: "Kitchen",
+	}
+
+	rep.Add(storage, "2", "false")
+	rep.Add(kitchen, "1", "true")
+	rep.Add(storage, "1", "true")
+
+	return rep
+}
+
+func TestReport_writeCSV(t *testing.T) {
+	tests := []struct {
+		rep  func() *Report
+		name string
+		sep  string
+		out  string
+	}{
+		{
+			name: "success: comma separator",
+			sep:  ",",
+			out: "IP,MAC Address,Name,ID,Enabled\n" +
+				"192.168.146.99,00:11:22:33:44:56,Kitchen,1,true\n" +
+				"192.168.146.123,00:11:22:33:44:55,Storage,1,true\n" +
+				"192.168.146.123,00:11:22:33:44:55,Storage,2,false\n",
+		},
+		{
+			name: "success: comma separator with quoted values",
+			rep: func() *Report {
+				rep := NewReport("Method", "Response")
+				rep.Add(&resource{ip: net.ParseIP("192.168.146.99"), name: "Kitchen, 1st floor"}, "Switch.GetStatus", `{"id":0,"output":true}`)
+
+				return rep
+			},
+			sep: ",",
+			out: "IP,MAC Address,Name,Method,Response\n" +
+				`192.168.146.99,,"Kitchen, 1st floor",Switch.GetStatus,"{""id"":0,""output"":true}"` + "\n",
+		},
+		{
+			name: "success: tab separator",
+			sep:  "\t",
+			out: "IP               MAC Address        Name     ID  Enabled\n" +
+				"192.168.146.99   00:11:22:33:44:56  Kitchen  1   true\n" +
+				"192.168.146.123  00:11:22:33:44:55  Storage  1   true\n" +
+				"192.168.146.123  00:11:22:33:44:55  Storage  2   false\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			rep := newTestReport
+			if test.rep != nil {
+				rep = test.rep
+			}
+
+			if err := rep().writeCSV(&buf, test.sep); err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			if buf.String() != test.out {
+				t.Fatalf("expected %q, got %q", test.out, buf.String())
+			}
+		})
+	}
+}
+
+func TestReport_writeJSON(t *testing.T) {
+	const expected = `[
+  {
+    "IP": "192.168.146.99",
+    "MAC Address": "00:11:22:33:44:56",
+    "Name": "Kitchen",
+    "ID": "1",
+    "Enabled": "true"
+  },
+  {
+    "IP": "192.168.146.123",
+    "MAC Address": "00:11:22:33:44:55",
+    "Name": "Storage",
+    "ID": "1",
+    "Enabled": "true"
+  },
+  {
+    "IP": "192.168.146.123",
+    "MAC Address": "00:11:22:33:44:55",
+    "Name": "Storage",
+    "ID": "2",
+    "Enabled": "false"
+  }
+]
+`
+
+	var buf bytes.Buffer
+
+	if err := newTestReport().writeJSON(&buf); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestReport_writeJSON_Keyed(t *testing.T) {
+	const expected = `{
+  "00:11:22:33:44:56": {
+    "IP": "192.168.146.99",
+    "Name": "Kitchen",
+    "Method": "Switch.GetStatus",
+    "Response": {
+      "id": 0,
+      "output": true
+    }
+  },
+  "00:11:22:33:44:55": {
+    "IP": "192.168.146.123",
+    "Name": "Storage",
+    "Method": "Switch.GetStatus",
+    "Response": null
+  }
+}
+`
+
+	rep := NewReport("Method", "Response").Raw("Response").Keyed()
+
+	rep.Add(&resource{
+		ip:   net.ParseIP("192.168.146.123"),
+		mac:  net.HardwareAddr{00, 17, 34, 51, 68, 85},
+		name: "Storage",
+	}, "Switch.GetStatus", "")
+
+	rep.Add(&resource{
+		ip:   net.ParseIP("192.168.146.99"),
+		mac:  net.HardwareAddr{00, 17, 34, 51, 68, 86},
+		name: "Kitchen",
+	}, "Switch.GetStatus", `{"id":0,"output":true}`)
+
+	var buf bytes.Buffer
+
+	if err := rep.writeJSON(&buf); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestExecReport(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		format string
+		file   string
+	}{
+		{
+			name:   "failure: invalid format",
+			format: "foo",
+			err:    ErrInvalidDumpFormat,
+		},
+		{
+			name:   "success: csv to file",
+			format: FormatCSV,
+			file:   "report.csv",
+		},
+		{
+			name:   "success: json to file",
+			format: FormatJSON,
+			file:   "report.json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := test.file
+			if file != "" {
+				file = filepath.Join(t.TempDir(), file)
+			}
+
+			err := ExecReport(newTestReport(), test.format, file)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestExecReport_redacted(t *testing.T) {
+	t.Cleanup(func() {
+		secrets.values = make(map[string]struct{})
+	})
+
+	secrets.register("Kitchen")
+
+	file := filepath.Join(t.TempDir(), "report.json")
+
+	if err := ExecReport(newTestReport(), FormatJSON, file); err != nil {
+		t.Fatalf("expected nil, got %#v", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("expected nil, got %#v", err)
+	}
+
+	if strings.Contains(string(data), "Kitchen") || !strings.Contains(string(data), Redacted) {
+		t.Fatalf("expected the secret to be redacted, got %s", data)
+	}
+}
@@ -0,0 +1,262 @@
+package device
+
+import (
+	"bytes"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Job actions
+const (
+	ActionOn  = "on"
+	ActionOff = "off"
+)
+
+// clockLayout is the time of day format of a Job.
+const clockLayout = "15:04"
+
+// Weekdays holds the Job day names, starting on Monday.
+var Weekdays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// Job is a scheduled output action, in a driver agnostic form.
+// It turns an output channel (i.e. relay, switch) on or off at a given time of day,
+// on the given days of the week, or every day if none are set.
+type Job struct {
+	Channel int      `json:"channel"`
+	Time    string   `json:"time"`
+	Days    []string `json:"days,omitempty"`
+	Action  string   `json:"action"`
+}
+
+// validate the Job values.
+func (j *Job) validate() error {
+	if j.Channel < 0 {
+		return fmt.Errorf("%w: negative channel %d", ErrInvalidJob, j.Channel)
+	}
+
+	if _, err := time.Parse(clockLayout, j.Time); err != nil {
+		return fmt.Errorf("%w: time %q must be in HH:MM format", ErrInvalidJob, j.Time)
+	}
+
+	for _, day := range j.Days {
+		if !slices.Contains(Weekdays, day) {
+			return fmt.Errorf("%w: day %q must be one of: %s", ErrInvalidJob, day, strings.Join(Weekdays, ", "))
+		}
+	}
+
+	if j.Action != ActionOn && j.Action != ActionOff {
+		return fmt.Errorf("%w: action %q must be one of: %s, %s", ErrInvalidJob, j.Action, ActionOn, ActionOff)
+	}
+
+	return nil
+}
+
+// Clock returns the hour and minute the Job runs at.
+func (j *Job) Clock() (int, int) {
+	t, _ := time.Parse(clockLayout, j.Time)
+
+	return t.Hour(), t.Minute()
+}
+
+// Weekdays returns the indexes of the days the Job runs on, starting on Monday (0).
+// All the days of the week are returned when none are set.
+func (j *Job) Weekdays() []int {
+	var days []int
+
+	for i, day := range Weekdays {
+		if len(j.Days) == 0 || slices.Contains(j.Days, day) {
+			days = append(days, i)
+		}
+	}
+
+	return days
+}
+
+// On checks if the Job turns the output channel on.
+func (j *Job) On() bool {
+	return j.Action == ActionOn
+}
+
+// Schedule holds the jobs to sync on one or more IoT devices, along with a policy to enforce.
+type Schedule struct {
+	Policy *Policy `json:"policy,omitempty"`
+	Jobs   []*Job  `json:"jobs"`
+}
+
+// ScheduledJob is a job found on a device, in a display friendly form.
+type ScheduledJob struct {
+	ID      string
+	Spec    string
+	Action  string
+	Enabled bool
+}
+
+// Scheduler is an interface that provides a standard way to manage scheduled jobs on IoT devices.
+// Schedule requests only add the missing jobs, returning the device jobs that aren't in the Schedule,
+// unless pruning is requested, in which case they're removed.
+type Scheduler interface {
+	ScheduledJobs(*http.Client) ([]*ScheduledJob, error)
+	ScheduleRequests(*http.Client, *Schedule, bool) ([]*http.Request, []*ScheduledJob, error)
+	ClearScheduleRequests(*http.Client) ([]*http.Request, error)
+}
+
+// NewSchedule creates a new *Schedule instance by parsing data from the provided reader.
+// Environment variable and file references are resolved beforehand (see Interpolate).
+// It returns an error if the data is invalid or cannot be parsed.
+func NewSchedule(r io.Reader) (*Schedule, error) {
+	data, err := interpolateRead(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var sch Schedule
+	if err = json.Unmarshal(data, &sch); err != nil {
+		return nil, err
+	}
+
+	for i, job := range sch.Jobs {
+		if err = job.validate(); err != nil {
+			return nil, fmt.Errorf("job %d: %w", i, err)
+		}
+	}
+
+	return &sch, nil
+}
+
+// LoadSchedule creates a new *Schedule instance from a file at the given path.
+// Base schedule files listed under the "extends" key are merged beforehand (see ReadFile).
+// It returns an error if the file cannot be opened or contains invalid data.
+func LoadSchedule(fp string) (*Schedule, error) {
+	data, err := ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSchedule(bytes.NewReader(data))
+}
+
+// ListSchedule is a procedure implementation designed to add the scheduled jobs of an IoT device to a Report.
+var ListSchedule = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(Scheduler)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: schedule", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	jobs, err := dev.ScheduledJobs(&http.Client{
+		Transport: tap.transport,
+	})
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	for _, job := range jobs {
+		tap.report.Add(res, job.ID, strconv.FormatBool(job.Enabled), job.Spec, job.Action)
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+	}
+}
+
+// ApplySchedule is a procedure implementation designed to sync a Schedule to an IoT device.
+// Device jobs that aren't in the Schedule are logged, unless pruning is enabled, in which case they're removed.
+var ApplySchedule = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(Scheduler)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: schedule", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	// Check if a schedule policy is set and enforce it
+	if tap.schedule.Policy != nil && tap.schedule.Policy.IsExcluded(res) {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: ErrPolicyExcluded,
+		}
+		return
+	}
+
+	rs, extra, err := dev.ScheduleRequests(&http.Client{
+		Transport: tap.transport,
+	}, tap.schedule, tap.prune)
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	if !tap.prune {
+		for _, job := range extra {
+			log.Printf("[%s] %s @ %s: job %s (%s %s) isn't in the schedule, use --prune to remove it", res.Driver(), res.ID(), res.IP(), job.ID, job.Spec, job.Action)
+		}
+	}
+
+	if len(rs) == 0 {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: ErrDeviceUnchanged,
+		}
+		return
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+		err: dispatch(tap, res, rs),
+	}
+}
+
+// ClearSchedule is a procedure implementation designed to remove every scheduled job from an IoT device.
+var ClearSchedule = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(Scheduler)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: schedule", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	rs, err := dev.ClearScheduleRequests(&http.Client{
+		Transport: tap.transport,
+	})
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	if len(rs) == 0 {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: ErrDeviceUnchanged,
+		}
+		return
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+		err: dispatch(tap, res, rs),
+	}
+}
@@ -0,0 +1,314 @@
+package device
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type scheduler struct {
+	funcError error
+	resource
+	unchanged bool
+}
+
+func (s *scheduler) ScheduledJobs(*http.Client) ([]*ScheduledJob, error) {
+	if s.funcError != nil {
+		return nil, s.funcError
+	}
+
+	return []*ScheduledJob{
+		{ID: "1", Enabled: true, Spec: "0 0 8 * * *", Action: "Switch.Set"},
+	}, nil
+}
+
+func (s *scheduler) ScheduleRequests(*http.Client, *Schedule, bool) ([]*http.Request, []*ScheduledJob, error) {
+	if s.funcError != nil {
+		return nil, nil, s.funcError
+	}
+
+	if s.unchanged {
+		return nil, nil, nil
+	}
+
+	return []*http.Request{
+		{
+			URL:    &url.URL{},
+			Method: http.MethodGet,
+		},
+	}, []*ScheduledJob{
+		{ID: "2", Enabled: false, Spec: "0 0 9 * * *", Action: "Switch.Set"},
+	}, nil
+}
+
+func (s *scheduler) ClearScheduleRequests(*http.Client) ([]*http.Request, error) {
+	if s.funcError != nil {
+		return nil, s.funcError
+	}
+
+	if s.unchanged {
+		return nil, nil
+	}
+
+	return []*http.Request{
+		{
+			URL:    &url.URL{},
+			Method: http.MethodGet,
+		},
+	}, nil
+}
+
+func TestJob(t *testing.T) {
+	job := &Job{
+		Time:   "07:05",
+		Days:   []string{"sun", "wed", "mon"},
+		Action: ActionOn,
+	}
+
+	if hour, minute := job.Clock(); hour != 7 || minute != 5 {
+		t.Fatalf("expected 7:5, got %d:%d", hour, minute)
+	}
+
+	if days := job.Weekdays(); !reflect.DeepEqual(days, []int{0, 2, 6}) {
+		t.Fatalf("expected [0 2 6], got %v", days)
+	}
+
+	if !job.On() {
+		t.Fatal("expected the job to turn the channel on")
+	}
+
+	job.Days = nil
+
+	if days := job.Weekdays(); !reflect.DeepEqual(days, []int{0, 1, 2, 3, 4, 5, 6}) {
+		t.Fatalf("expected every day, got %v", days)
+	}
+}
+
+func TestNewSchedule(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		data string
+		jobs int
+	}{
+		{
+			name: "failure: invalid JSON",
+			data: `{`,
+			err:  io.ErrUnexpectedEOF,
+		},
+		{
+			name: "failure: negative channel",
+			data: `{"jobs":[{"channel":-1,"time":"08:00","action":"on"}]}`,
+			err:  ErrInvalidJob,
+		},
+		{
+			name: "failure: invalid time",
+			data: `{"jobs":[{"time":"8am","action":"on"}]}`,
+			err:  ErrInvalidJob,
+		},
+		{
+			name: "failure: invalid day",
+			data: `{"jobs":[{"time":"08:00","days":["monday"],"action":"on"}]}`,
+			err:  ErrInvalidJob,
+		},
+		{
+			name: "failure: invalid action",
+			data: `{"jobs":[{"time":"08:00","action":"toggle"}]}`,
+			err:  ErrInvalidJob,
+		},
+		{
+			name: "success",
+			data: `{"jobs":[{"channel":1,"time":"08:00","days":["sat","sun"],"action":"on"}]}`,
+			jobs: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sch, err := NewSchedule(strings.NewReader(test.data))
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if sch != nil && len(sch.Jobs) != test.jobs {
+				t.Fatalf("expected %d jobs, got %d", test.jobs, len(sch.Jobs))
+			}
+		})
+	}
+}
+
+func TestLoadSchedule(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		fp   string
+		jobs int
+	}{
+		{
+			name: "failure: empty file path",
+			err:  ErrFilePathEmpty,
+		},
+		{
+			name: "failure: file not found",
+			fp:   "../testdata/missing.json",
+			err:  fs.ErrNotExist,
+		},
+		{
+			name: "success",
+			fp:   "../testdata/schedule.json",
+			jobs: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sch, err := LoadSchedule(test.fp)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if sch != nil && len(sch.Jobs) != test.jobs {
+				t.Fatalf("expected %d jobs, got %d", test.jobs, len(sch.Jobs))
+			}
+		})
+	}
+}
+
+func TestScheduleProcedures(t *testing.T) {
+	ok := &roundTripper{
+		response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("{}")),
+		},
+	}
+
+	tests := []struct {
+		proc procedure
+		rt   http.RoundTripper
+		dev  Resource
+		sch  *Schedule
+		err  error
+		name string
+		rows int
+	}{
+		{
+			name: "failure: list unsupported procedure",
+			proc: ListSchedule,
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: list function error",
+			proc: ListSchedule,
+			dev: &scheduler{
+				funcError: ErrUnexpected,
+			},
+			err: ErrUnexpected,
+		},
+		{
+			name: "success: list",
+			proc: ListSchedule,
+			dev:  &scheduler{},
+			rows: 1,
+		},
+		{
+			name: "failure: apply unsupported procedure",
+			proc: ApplySchedule,
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: apply policy exclusion",
+			proc: ApplySchedule,
+			dev:  &scheduler{},
+			sch: &Schedule{
+				Policy: &Policy{
+					Mode: PolicyModeWhitelist,
+				},
+			},
+			err: ErrPolicyExcluded,
+		},
+		{
+			name: "failure: apply function error",
+			proc: ApplySchedule,
+			dev: &scheduler{
+				funcError: ErrUnexpected,
+			},
+			sch: &Schedule{},
+			err: ErrUnexpected,
+		},
+		{
+			name: "success: apply without changes",
+			proc: ApplySchedule,
+			dev: &scheduler{
+				unchanged: true,
+			},
+			sch: &Schedule{},
+			err: ErrDeviceUnchanged,
+		},
+		{
+			name: "success: apply",
+			proc: ApplySchedule,
+			dev:  &scheduler{},
+			rt:   ok,
+			sch:  &Schedule{},
+		},
+		{
+			name: "failure: clear unsupported procedure",
+			proc: ClearSchedule,
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: clear function error",
+			proc: ClearSchedule,
+			dev: &scheduler{
+				funcError: ErrUnexpected,
+			},
+			err: ErrUnexpected,
+		},
+		{
+			name: "success: clear without jobs",
+			proc: ClearSchedule,
+			dev: &scheduler{
+				unchanged: true,
+			},
+			err: ErrDeviceUnchanged,
+		},
+		{
+			name: "success: clear",
+			proc: ClearSchedule,
+			dev:  &scheduler{},
+			rt:   ok,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tap := &Tapper{
+				transport: test.rt,
+				schedule:  test.sch,
+				report:    NewReport(),
+			}
+
+			ch := make(chan *ProcedureResult, 1)
+
+			test.proc(tap, test.dev, ch)
+
+			result := <-ch
+
+			if !errors.Is(result.err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, result.err)
+			}
+
+			if tap.report.Len() != test.rows {
+				t.Fatalf("expected %d report rows, got %d", test.rows, tap.report.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,553 @@
+package device
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Schema kinds
+const (
+	SchemaConfig     = "config"
+	SchemaAuth       = "auth"
+	SchemaDeployment = "deployment"
+	SchemaSchedule   = "schedule"
+	SchemaWebhooks   = "webhooks"
+	SchemaKVS        = "kvs"
+	SchemaVirtual    = "virtual"
+)
+
+// dialect is the JSON Schema version used when exporting schemas.
+const dialect = "https://json-schema.org/draft/2020-12/schema"
+
+// typeInteger is the Schema type of numbers without a fractional part.
+const typeInteger = "integer"
+
+// Schema describes the expected structure of a file value, as a subset of JSON Schema.
+// Objects reject unknown keys, unless they are open (i.e. without declared properties).
+type Schema struct {
+	Type        string
+	Description string
+	Properties  map[string]*Schema
+	Required    []string
+	Items       *Schema
+	AnyOf       []*Schema
+	Enum        []string
+	Minimum     *float64
+	Maximum     *float64
+	Null        bool
+}
+
+// AnySchema returns a new *Schema instance matching any value.
+func AnySchema() *Schema {
+	return &Schema{}
+}
+
+// ObjectSchema returns a new *Schema instance matching objects with the given properties.
+// Objects without properties are open, and match any keys.
+func ObjectSchema(props map[string]*Schema, required ...string) *Schema {
+	return &Schema{
+		Type:       KindObject,
+		Properties: props,
+		Required:   required,
+	}
+}
+
+// ArraySchema returns a new *Schema instance matching arrays of the given items.
+func ArraySchema(items *Schema) *Schema {
+	return &Schema{
+		Type:  KindArray,
+		Items: items,
+	}
+}
+
+// StringSchema returns a new *Schema instance matching strings, optionally restricted to a set of values.
+func StringSchema(enum ...string) *Schema {
+	return &Schema{
+		Type: KindString,
+		Enum: enum,
+	}
+}
+
+// NumberSchema returns a new *Schema instance matching numbers.
+func NumberSchema() *Schema {
+	return &Schema{
+		Type: KindNumber,
+	}
+}
+
+// IntegerSchema returns a new *Schema instance matching numbers without a fractional part.
+func IntegerSchema() *Schema {
+	return &Schema{
+		Type: typeInteger,
+	}
+}
+
+// BooleanSchema returns a new *Schema instance matching booleans.
+func BooleanSchema() *Schema {
+	return &Schema{
+		Type: KindBoolean,
+	}
+}
+
+// AnyOfSchema returns a new *Schema instance matching values of any of the given schemas (e.g. a string or an object).
+// Values are validated against the first Schema matching their type.
+func AnyOfSchema(schemas ...*Schema) *Schema {
+	return &Schema{
+		AnyOf: schemas,
+	}
+}
+
+// Between restricts a number Schema to an inclusive range.
+func (s *Schema) Between(minimum, maximum float64) *Schema {
+	s.Minimum = &minimum
+	s.Maximum = &maximum
+
+	return s
+}
+
+// AtLeast restricts a number Schema to an inclusive minimum.
+func (s *Schema) AtLeast(minimum float64) *Schema {
+	s.Minimum = &minimum
+
+	return s
+}
+
+// Nullable allows a Schema to also match null values.
+func (s *Schema) Nullable() *Schema {
+	s.Null = true
+
+	return s
+}
+
+// Describe sets the Schema description.
+func (s *Schema) Describe(description string) *Schema {
+	s.Description = description
+
+	return s
+}
+
+// jsonSchema is the JSON Schema representation of a Schema.
+type jsonSchema struct {
+	Dialect              string             `json:"$schema,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// toJSONSchema converts a Schema into its JSON Schema representation.
+func (s *Schema) toJSONSchema() *jsonSchema {
+	js := &jsonSchema{
+		Description: s.Description,
+		Properties:  s.Properties,
+		Required:    s.Required,
+		Items:       s.Items,
+		AnyOf:       s.AnyOf,
+		Minimum:     s.Minimum,
+		Maximum:     s.Maximum,
+	}
+
+	if s.Type != "" {
+		js.Type = s.Type
+
+		if s.Null {
+			js.Type = []string{s.Type, KindNull}
+		}
+	}
+
+	if len(s.Properties) > 0 {
+		js.AdditionalProperties = new(bool)
+	}
+
+	for _, value := range s.Enum {
+		js.Enum = append(js.Enum, value)
+	}
+
+	if len(js.Enum) > 0 && s.Null {
+		js.Enum = append(js.Enum, nil)
+	}
+
+	return js
+}
+
+// MarshalJSON implements the Marshaler interface.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.toJSONSchema(), json.Deterministic(true))
+}
+
+// ExportSchema writes a Schema to an io.Writer, as an indented JSON Schema document.
+func ExportSchema(w io.Writer, s *Schema) error {
+	js := s.toJSONSchema()
+	js.Dialect = dialect
+
+	return json.MarshalWrite(w, js, json.Deterministic(true), jsontext.WithIndent("  "))
+}
+
+// ValidationError describes a value that doesn't match its Schema.
+type ValidationError struct {
+	Path    string
+	Message string
+	Line    int
+	Column  int
+}
+
+// Error interface implementation.
+func (ve *ValidationError) Error() string {
+	if ve.Line == 0 {
+		return fmt.Sprintf("%s: %s", ve.Path, ve.Message)
+	}
+
+	return fmt.Sprintf("%d:%d: %s: %s", ve.Line, ve.Column, ve.Path, ve.Message)
+}
+
+// joinPath appends an object key to a value path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// templated checks if a string holds a template or a reference, which can only be checked once resolved.
+func templated(value any) bool {
+	s, ok := value.(string)
+
+	return ok && (strings.Contains(s, "{{") || strings.Contains(s, "${"))
+}
+
+// matchesType checks if a Node kind matches the Schema type.
+func (s *Schema) matchesType(n *Node) bool {
+	switch {
+	case s.Type == "":
+		return true
+
+	case n.Kind == KindNull:
+		return s.Null
+
+	case s.Type == typeInteger:
+		return n.Integer()
+	}
+
+	return s.Type == n.Kind
+}
+
+// Validate checks a Node tree against the Schema, returning every mismatch found.
+// Objects holding an "extends" key at the root are partial, since the keys they lack can be inherited
+// from their base files. Required keys are only checked for the objects within their lists, which
+// replace the inherited ones as a whole.
+func (s *Schema) Validate(n *Node) []*ValidationError {
+	partial := n.Kind == KindObject && slices.ContainsFunc(n.Members, func(m *Member) bool { return m.Key == extendsKey })
+
+	return s.validate(n, "", partial)
+}
+
+// validate a Node at the given path, skipping the required key checks of partial objects.
+func (s *Schema) validate(n *Node, path string, partial bool) []*ValidationError {
+	mismatch := func(format string, args ...any) []*ValidationError {
+		p := path
+		if p == "" {
+			p = "(root)"
+		}
+
+		return []*ValidationError{
+			{
+				Path:    p,
+				Message: fmt.Sprintf(format, args...),
+				Line:    n.Line,
+				Column:  n.Column,
+			},
+		}
+	}
+
+	if len(s.AnyOf) > 0 {
+		var types []string
+
+		for _, alt := range s.AnyOf {
+			if alt.matchesType(n) {
+				return alt.validate(n, path, partial)
+			}
+
+			types = append(types, alt.Type)
+		}
+
+		return mismatch("expected %s, got %s", strings.Join(types, " or "), n.Kind)
+	}
+
+	if !s.matchesType(n) {
+		expected := s.Type
+		if s.Null {
+			expected += " or null"
+		}
+
+		return mismatch("expected %s, got %s", expected, n.Kind)
+	}
+
+	var errs []*ValidationError
+
+	switch n.Kind {
+	case KindObject:
+		for _, m := range n.Members {
+			mpath := joinPath(path, m.Key)
+
+			prop, ok := s.Properties[m.Key]
+			if !ok && len(s.Properties) > 0 {
+				errs = append(errs, &ValidationError{
+					Path:    mpath,
+					Message: "unknown key",
+					Line:    m.Line,
+					Column:  m.Column,
+				})
+				continue
+			}
+
+			if prop != nil {
+				errs = append(errs, prop.validate(m.Value, mpath, partial)...)
+			}
+		}
+
+		if partial {
+			break
+		}
+
+		for _, key := range s.Required {
+			if !slices.ContainsFunc(n.Members, func(m *Member) bool { return m.Key == key }) {
+				errs = append(errs, mismatch("missing required key %q", key)...)
+			}
+		}
+
+	case KindArray:
+		if s.Items == nil {
+			break
+		}
+
+		for i, item := range n.Items {
+			errs = append(errs, s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), false)...)
+		}
+
+	case KindString:
+		if len(s.Enum) > 0 && !templated(n.Value) && !slices.Contains(s.Enum, n.Value.(string)) {
+			return mismatch("expected one of: %s, got %q", strings.Join(s.Enum, ", "), n.Value)
+		}
+
+	case KindNumber:
+		value := n.Value.(float64)
+
+		if s.Minimum != nil && value < *s.Minimum {
+			return mismatch("value %v is below the minimum of %v", value, *s.Minimum)
+		}
+
+		if s.Maximum != nil && value > *s.Maximum {
+			return mismatch("value %v is above the maximum of %v", value, *s.Maximum)
+		}
+	}
+
+	return errs
+}
+
+// PolicySchema returns the Schema of a Policy.
+func PolicySchema() *Schema {
+	return ObjectSchema(map[string]*Schema{
+		"mode":    StringSchema("blacklist", "whitelist"),
+		"names":   ArraySchema(StringSchema()),
+		"models":  ArraySchema(StringSchema()),
+		"devices": ArraySchema(StringSchema()),
+	}, "mode").Describe("Devices to include or exclude")
+}
+
+// MatcherSchema returns the Schema of a Matcher.
+func MatcherSchema() *Schema {
+	return ObjectSchema(map[string]*Schema{
+		"names":   ArraySchema(StringSchema()),
+		"models":  ArraySchema(StringSchema()),
+		"devices": ArraySchema(StringSchema()),
+	}).Describe("Devices to match")
+}
+
+// fileSchema returns the Schema of a file, with the keys common to every file type.
+func fileSchema(props map[string]*Schema, required ...string) *Schema {
+	all := map[string]*Schema{
+		"$schema":  StringSchema().Describe("JSON Schema of the file"),
+		extendsKey: AnySchema().Describe("Base files to inherit from"),
+		"meta":     ObjectSchema(nil).Describe("Free form metadata"),
+		"policy":   PolicySchema(),
+	}
+
+	for key, prop := range props {
+		all[key] = prop
+	}
+
+	return ObjectSchema(all, required...)
+}
+
+// ConfigSchema returns the Schema of a driver configuration, made of its component Schemas.
+func ConfigSchema(components map[string]*Schema) *Schema {
+	props := map[string]*Schema{
+		overridesKey: ArraySchema(ObjectSchema(map[string]*Schema{
+			"match":    MatcherSchema(),
+			"settings": ObjectSchema(components),
+		}, "match")).Describe("Per device overrides"),
+	}
+
+	for key, component := range components {
+		props[key] = component
+	}
+
+	return fileSchema(props)
+}
+
+// AuthConfigSchema returns the Schema of an AuthConfig.
+func AuthConfigSchema() *Schema {
+	return fileSchema(map[string]*Schema{
+		"credentials": ObjectSchema(map[string]*Schema{
+			"username": StringSchema(),
+			"password": StringSchema(),
+		}, "password"),
+	}, "credentials")
+}
+
+// DeploymentSchema returns the Schema of a Deployment.
+func DeploymentSchema() *Schema {
+	return fileSchema(map[string]*Schema{
+		"scripts": ArraySchema(AnyOfSchema(
+			StringSchema().Describe("Script file path"),
+			ObjectSchema(map[string]*Schema{
+				"path":   StringSchema().Describe("Script file path"),
+				"name":   StringSchema().Describe("Script name on the device, the file name if unset"),
+				"enable": BooleanSchema().Describe("Run the script when the device boots, enabled by default"),
+				"start":  BooleanSchema().Describe("Start the script once deployed, enabled by default"),
+				"kvs":    ObjectSchema(nil).Describe("Key-value store entries to set alongside the script, by key"),
+				"order":  IntegerSchema().Describe("Deployment order, lower values being deployed first"),
+				"template": BooleanSchema().Describe(
+					"Render the script code as a template for each device, disabled by default",
+				),
+			}, "path"),
+		)).Describe("Scripts to deploy, as file paths or objects with per-script options"),
+	}, "scripts")
+}
+
+// ScheduleSchema returns the Schema of a Schedule.
+func ScheduleSchema() *Schema {
+	return fileSchema(map[string]*Schema{
+		"jobs": ArraySchema(ObjectSchema(map[string]*Schema{
+			"channel": IntegerSchema().AtLeast(0).Describe("Output channel (e.g. relay, switch) index"),
+			"time":    StringSchema().Describe("Time of day, in HH:MM format"),
+			"days":    ArraySchema(StringSchema(Weekdays...)).Describe("Days of the week, every day if unset"),
+			"action":  StringSchema(ActionOn, ActionOff),
+		}, "time", "action")).Describe("Scheduled jobs"),
+	}, "jobs")
+}
+
+// WebhooksSchema returns the Schema of Webhooks.
+func WebhooksSchema() *Schema {
+	return fileSchema(map[string]*Schema{
+		"hooks": ArraySchema(ObjectSchema(map[string]*Schema{
+			"enable":  BooleanSchema().Describe("Enabled by default"),
+			"event":   StringSchema().Describe("Driver specific event (e.g. out_on_url, switch.on)"),
+			"name":    StringSchema().Describe("Hook name, where supported"),
+			"urls":    ArraySchema(StringSchema()).Describe("URLs to call, which can be templated"),
+			"channel": IntegerSchema().AtLeast(0).Describe("Component (e.g. relay, switch) index"),
+		}, "event", "urls")).Describe("Event webhooks"),
+	}, "hooks")
+}
+
+// KeyValuesSchema returns the Schema of KeyValues.
+func KeyValuesSchema() *Schema {
+	return fileSchema(map[string]*Schema{
+		"values": ObjectSchema(nil).Describe("Key-value store entries, by key"),
+	}, "values")
+}
+
+// VirtualComponentsSchema returns the Schema of VirtualComponents.
+func VirtualComponentsSchema() *Schema {
+	return fileSchema(map[string]*Schema{
+		"virtual": ArraySchema(ObjectSchema(map[string]*Schema{
+			"type":   StringSchema(VirtualTypes...),
+			"id":     IntegerSchema().Between(VirtualMinID, VirtualMaxID),
+			"config": ObjectSchema(nil).Describe("Component configuration (e.g. name, default_value, meta)"),
+		}, "type", "id")).Describe("Virtual components"),
+	}, "virtual")
+}
+
+var schemaRegistry = make(map[string]*Schema)
+
+// RegisterSchema registers the configuration Schema for a specified driver.
+func RegisterSchema(driver string, s *Schema) {
+	schemaRegistry[driver] = s
+}
+
+// GetSchema returns the Schema of a file kind. Configuration Schemas depend on the driver,
+// with the Schema for all drivers holding a section for each of them (see MultiConfig).
+func GetSchema(kind, driver string) (*Schema, error) {
+	switch kind {
+	case SchemaAuth:
+		return AuthConfigSchema(), nil
+
+	case SchemaDeployment:
+		return DeploymentSchema(), nil
+
+	case SchemaSchedule:
+		return ScheduleSchema(), nil
+
+	case SchemaWebhooks:
+		return WebhooksSchema(), nil
+
+	case SchemaKVS:
+		return KeyValuesSchema(), nil
+
+	case SchemaVirtual:
+		return VirtualComponentsSchema(), nil
+
+	case SchemaConfig:
+		if driver == AllDrivers {
+			props := make(map[string]*Schema, len(schemaRegistry))
+			for drv, s := range schemaRegistry {
+				props[drv] = s
+			}
+
+			return ObjectSchema(props), nil
+		}
+
+		s, ok := schemaRegistry[driver]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, driver)
+		}
+
+		return s, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedSchema, kind)
+}
+
+// ValidateFile checks a file at the given path against a Schema.
+// Base files listed under the "extends" key aren't merged, since each file is checked on its own,
+// so the keys an extending file lacks aren't reported as missing (see Validate).
+// It returns an error if the file cannot be read or parsed, and the mismatches found otherwise.
+func ValidateFile(fp string, s *Schema) ([]*ValidationError, error) {
+	if fp == "" {
+		return nil, ErrFilePathEmpty
+	}
+
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := ParseNode(FileFormat(fp), data)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Validate(n), nil
+}
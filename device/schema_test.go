@@ -0,0 +1,368 @@
+package device
+
+import (
+	"encoding/json/v2"
+	"errors"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testSchema used for validation tests.
+var testSchema = ObjectSchema(map[string]*Schema{
+	"name":    StringSchema().Nullable(),
+	"mode":    StringSchema("relay", "roller"),
+	"delay":   NumberSchema().Between(0, 60),
+	"id":      IntegerSchema().AtLeast(0),
+	"tags":    ArraySchema(StringSchema()),
+	"meta":    ObjectSchema(nil),
+	"extends": AnySchema(),
+	"files": ArraySchema(AnyOfSchema(StringSchema(), ObjectSchema(map[string]*Schema{
+		"path": StringSchema(),
+	}, "path"))),
+}, "id")
+
+func TestSchema_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []string
+	}{
+		{
+			name: "valid",
+			data: `{"id":0,"name":null,"mode":"relay","delay":1.5,"tags":["a"],"meta":{"foo":"bar"}}`,
+		},
+		{
+			name: "valid alternatives",
+			data: `{"id":0,"files":["a.js",{"path":"b.js"}]}`,
+		},
+		{
+			name: "valid templates and references",
+			data: `{"id":0,"name":"{{ .Vars.room }}","mode":"${IOTAP_MODE}"}`,
+		},
+		{
+			name:     "wrong root type",
+			data:     `[]`,
+			expected: []string{"1:1: (root): expected object, got array"},
+		},
+		{
+			name:     "missing required key",
+			data:     `{}`,
+			expected: []string{`1:1: (root): missing required key "id"`},
+		},
+		{
+			name: "extending file without required keys",
+			data: `{"extends":"base.json","meta":{}}`,
+		},
+		{
+			name:     "extending file with incomplete list items",
+			data:     `{"extends":"base.json","files":[{"name":"b.js"}]}`,
+			expected: []string{"1:34: files[0].name: unknown key", `1:33: files[0]: missing required key "path"`},
+		},
+		{
+			name:     "unknown key",
+			data:     `{"id":0,"foo":"bar"}`,
+			expected: []string{"1:9: foo: unknown key"},
+		},
+		{
+			name: "wrong types",
+			data: `{"id":1.5,"name":1,"tags":[true]}`,
+			expected: []string{
+				"1:7: id: expected integer, got number",
+				"1:18: name: expected string or null, got number",
+				"1:28: tags[0]: expected string, got boolean",
+			},
+		},
+		{
+			name: "mismatched alternatives",
+			data: `{"id":0,"files":[1,{"name":"b.js"}]}`,
+			expected: []string{
+				"1:18: files[0]: expected string or object, got number",
+				"1:21: files[1].name: unknown key",
+				`1:20: files[1]: missing required key "path"`,
+			},
+		},
+		{
+			name: "out of range values",
+			data: `{"id":-1,"mode":"cover","delay":61}`,
+			expected: []string{
+				"1:7: id: value -1 is below the minimum of 0",
+				`1:17: mode: expected one of: relay, roller, got "cover"`,
+				"1:33: delay: value 61 is above the maximum of 60",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := ParseNode(FormatJSON, []byte(test.data))
+			if err != nil {
+				t.Fatalf("unable to parse data: %v", err)
+			}
+
+			var out []string
+			for _, ve := range testSchema.Validate(n) {
+				out = append(out, ve.Error())
+			}
+
+			if !reflect.DeepEqual(out, test.expected) {
+				t.Fatalf("expected %q, got %q", test.expected, out)
+			}
+		})
+	}
+}
+
+func TestValidationError_Error(t *testing.T) {
+	tests := []struct {
+		ve   *ValidationError
+		name string
+		out  string
+	}{
+		{
+			name: "with position",
+			ve:   &ValidationError{Path: "foo", Message: "unknown key", Line: 2, Column: 3},
+			out:  "2:3: foo: unknown key",
+		},
+		{
+			name: "without position",
+			ve:   &ValidationError{Path: "foo", Message: "unknown key"},
+			out:  "foo: unknown key",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.ve.Error() != test.out {
+				t.Fatalf("expected %q, got %q", test.out, test.ve.Error())
+			}
+		})
+	}
+}
+
+func TestExportSchema(t *testing.T) {
+	var sb strings.Builder
+
+	s := ObjectSchema(map[string]*Schema{
+		"name": StringSchema("foo", "bar").Nullable(),
+		"id":   IntegerSchema().Between(0, 10),
+		"file": AnyOfSchema(StringSchema(), BooleanSchema()),
+	}, "id")
+
+	if err := ExportSchema(&sb, s); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal([]byte(sb.String()), &out); err != nil {
+		t.Fatalf("unable to decode schema: %v", err)
+	}
+
+	expected := map[string]any{
+		"$schema": dialect,
+		"type":    "object",
+		"properties": map[string]any{
+			"name": map[string]any{
+				"type": []any{"string", "null"},
+				"enum": []any{"foo", "bar", nil},
+			},
+			"id": map[string]any{
+				"type":    "integer",
+				"minimum": float64(0),
+				"maximum": float64(10),
+			},
+			"file": map[string]any{
+				"anyOf": []any{
+					map[string]any{"type": "string"},
+					map[string]any{"type": "boolean"},
+				},
+			},
+		},
+		"additionalProperties": false,
+		"required":             []any{"id"},
+	}
+
+	if !reflect.DeepEqual(out, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, out)
+	}
+}
+
+func TestGetSchema(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		kind   string
+		driver string
+	}{
+		{
+			name: "failure: unsupported schema",
+			kind: "foo",
+			err:  ErrUnsupportedSchema,
+		},
+		{
+			name:   "failure: unsupported driver",
+			kind:   SchemaConfig,
+			driver: "bar",
+			err:    ErrUnsupportedDriver,
+		},
+		{
+			name: "success: auth",
+			kind: SchemaAuth,
+		},
+		{
+			name: "success: deployment",
+			kind: SchemaDeployment,
+		},
+		{
+			name: "success: schedule",
+			kind: SchemaSchedule,
+		},
+		{
+			name: "success: webhooks",
+			kind: SchemaWebhooks,
+		},
+		{
+			name: "success: kvs",
+			kind: SchemaKVS,
+		},
+		{
+			name: "success: virtual",
+			kind: SchemaVirtual,
+		},
+		{
+			name:   "success: config",
+			kind:   SchemaConfig,
+			driver: "foo",
+		},
+		{
+			name:   "success: config for all drivers",
+			kind:   SchemaConfig,
+			driver: AllDrivers,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				schemaRegistry = make(map[string]*Schema)
+			})
+
+			RegisterSchema("foo", ConfigSchema(nil))
+
+			s, err := GetSchema(test.kind, test.driver)
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if test.err == nil && s == nil {
+				t.Fatal("expected schema, got nil")
+			}
+		})
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		fp     string
+		kind   string
+		issues int
+	}{
+		{
+			name: "failure: empty file path",
+			kind: SchemaAuth,
+			err:  ErrFilePathEmpty,
+		},
+		{
+			name: "failure: file path not found",
+			kind: SchemaAuth,
+			fp:   "foo.bar",
+			err:  &fs.PathError{},
+		},
+		{
+			name:   "success: valid auth config",
+			kind:   SchemaAuth,
+			fp:     "../testdata/authconfig.json",
+			issues: 0,
+		},
+		{
+			name:   "success: valid extending auth config",
+			kind:   SchemaAuth,
+			fp:     "../testdata/extends/authconfig.json",
+			issues: 0,
+		},
+		{
+			name:   "success: valid deployment",
+			kind:   SchemaDeployment,
+			fp:     "../testdata/deployment.yaml",
+			issues: 0,
+		},
+		{
+			name:   "success: valid schedule",
+			kind:   SchemaSchedule,
+			fp:     "../testdata/schedule.json",
+			issues: 0,
+		},
+		{
+			name:   "success: valid webhooks",
+			kind:   SchemaWebhooks,
+			fp:     "../testdata/webhooks.json",
+			issues: 0,
+		},
+		{
+			name:   "success: invalid webhooks",
+			kind:   SchemaWebhooks,
+			fp:     "../testdata/schedule.json",
+			issues: 2,
+		},
+		{
+			name:   "success: valid kvs",
+			kind:   SchemaKVS,
+			fp:     "../testdata/kvs.json",
+			issues: 0,
+		},
+		{
+			name:   "success: valid virtual",
+			kind:   SchemaVirtual,
+			fp:     "../testdata/virtual.json",
+			issues: 0,
+		},
+		{
+			name:   "success: invalid deployment",
+			kind:   SchemaDeployment,
+			fp:     "../testdata/authconfig.toml",
+			issues: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := GetSchema(test.kind, "")
+			if err != nil {
+				t.Fatalf("unable to get schema: %v", err)
+			}
+
+			errs, err := ValidateFile(test.fp, s)
+
+			if len(errs) != test.issues {
+				t.Fatalf("expected %d issues, got %v", test.issues, errs)
+			}
+
+			var pathError *fs.PathError
+			switch {
+			case errors.As(test.err, &pathError):
+				var pe *fs.PathError
+				if errors.As(err, &pe) {
+					return
+				}
+
+			case errors.Is(err, test.err):
+				return
+			}
+
+			t.Fatalf("expected %#v, got %#v", test.err, err)
+		})
+	}
+}
@@ -1,22 +1,55 @@
 package device
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path"
+	"text/template"
 )
 
-// Script holds the path and the contents of an IoT device script.
+// Script holds the path and the contents of an IoT device script,
+// along with the deployment options set in the manifest (see Deployment).
 type Script struct {
-	path string
-	code []byte
+	enable   *bool
+	start    *bool
+	kvs      map[string]any
+	path     string
+	name     string
+	code     []byte
+	order    int
+	template bool
 }
 
-// Name of the file the script was loaded from.
+// Name of the script on the device, which defaults to the name of the file the script was loaded from.
 func (s *Script) Name() string {
-	return path.Base(s.path)
+	if s.name != "" {
+		return s.name
+	}
+
+	return s.Source()
+}
+
+// Enabled checks if the script should run when the device boots, which is the default.
+func (s *Script) Enabled() bool {
+	return s.enable == nil || *s.enable
+}
+
+// Started checks if the script should be started once deployed, which is the default.
+func (s *Script) Started() bool {
+	return s.start == nil || *s.start
+}
+
+// KeyValues returns the key-value store entries to set alongside the script, if any.
+func (s *Script) KeyValues() map[string]any {
+	return s.kvs
+}
+
+// Order returns the deployment order of the script, with lower values being deployed first.
+func (s *Script) Order() int {
+	return s.order
 }
 
 // Code returns the content of the script.
@@ -29,6 +62,43 @@ func (s *Script) Length() int {
 	return len(s.code)
 }
 
+// Source returns the name of the file the script was loaded from.
+func (s *Script) Source() string {
+	return path.Base(s.path)
+}
+
+// Hash returns the hex encoded SHA-256 hash of the script content.
+func (s *Script) Hash() string {
+	return hashCode(s.code)
+}
+
+// render returns a copy of the script, with its code rendered as a template for a device.
+// Scripts that aren't templated are returned as they are.
+func (s *Script) render(data *TemplateData) (*Script, error) {
+	if !s.template {
+		return s, nil
+	}
+
+	tpl, err := template.New(s.Name()).Option("missingkey=error").Parse(string(s.code))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = tpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	if buf.Len() == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrScriptEmpty, s.Name())
+	}
+
+	rendered := *s
+	rendered.code = buf.Bytes()
+
+	return &rendered, nil
+}
+
 // NewScript creates a new *Script instance by parsing data from the provided reader.
 // It returns an error if the data is invalid or cannot be parsed.
 func NewScript(r io.Reader) (*Script, error) {
@@ -0,0 +1,289 @@
+package device
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InstalledScript is a script found on a device.
+type InstalledScript struct {
+	Name    string
+	ID      int
+	Enabled bool
+	Running bool
+}
+
+// Scripter is an interface that provides a standard way to manage the scripts installed on IoT devices.
+// Remove requests stop the running scripts before removing them.
+type Scripter interface {
+	InstalledScripts(*http.Client) ([]*InstalledScript, error)
+	ScriptCode(*http.Client, int) ([]byte, error)
+	StartScriptRequests([]*InstalledScript) ([]*http.Request, error)
+	StopScriptRequests([]*InstalledScript) ([]*http.Request, error)
+	RemoveScriptRequests([]*InstalledScript) ([]*http.Request, error)
+}
+
+// selectScripts returns the installed scripts matching the given names, or every script if no names are given.
+func selectScripts(scripts []*InstalledScript, names []string, match func(*InstalledScript) bool) []*InstalledScript {
+	var selected []*InstalledScript
+
+	for _, s := range scripts {
+		if (len(names) == 0 || slices.Contains(names, s.Name)) && match(s) {
+			selected = append(selected, s)
+		}
+	}
+
+	return selected
+}
+
+// ListScripts is a procedure implementation designed to add the installed scripts of an IoT device to a Report.
+var ListScripts = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(Scripter)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: scripts", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	scripts, err := dev.InstalledScripts(&http.Client{
+		Transport: tap.transport,
+	})
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	for _, s := range scripts {
+		tap.report.Add(res, s.Name, strconv.Itoa(s.ID), strconv.FormatBool(s.Enabled), strconv.FormatBool(s.Running))
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+	}
+}
+
+// scriptProcedure returns a procedure implementation that dispatches the requests built for the installed scripts
+// matching the script names and a condition. Devices without matching scripts are skipped.
+func scriptProcedure(
+	match func(*InstalledScript) bool,
+	requests func(Scripter, []*InstalledScript) ([]*http.Request, error),
+) procedure {
+	return func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+		dev, ok := res.(Scripter)
+		if !ok {
+			ch <- &ProcedureResult{
+				dev: res,
+				err: fmt.Errorf("%w: scripts", ErrUnsupportedProcedure),
+			}
+			return
+		}
+
+		scripts, err := dev.InstalledScripts(&http.Client{
+			Transport: tap.transport,
+		})
+		if err != nil {
+			ch <- &ProcedureResult{
+				dev: res,
+				err: err,
+			}
+			return
+		}
+
+		selected := selectScripts(scripts, tap.names, match)
+		if len(selected) == 0 {
+			ch <- &ProcedureResult{
+				dev: res,
+				err: ErrDeviceUnchanged,
+			}
+			return
+		}
+
+		rs, err := requests(dev, selected)
+		if err != nil {
+			ch <- &ProcedureResult{
+				dev: res,
+				err: err,
+			}
+			return
+		}
+
+		ch <- &ProcedureResult{
+			dev: res,
+			err: dispatch(tap, res, rs),
+		}
+	}
+}
+
+// StartScripts is a procedure implementation designed to start the named scripts of an IoT device, if stopped.
+var StartScripts = scriptProcedure(
+	func(s *InstalledScript) bool { return !s.Running },
+	Scripter.StartScriptRequests,
+)
+
+// StopScripts is a procedure implementation designed to stop the named scripts of an IoT device, if running.
+var StopScripts = scriptProcedure(
+	func(s *InstalledScript) bool { return s.Running },
+	Scripter.StopScriptRequests,
+)
+
+// RemoveScripts is a procedure implementation designed to remove the named scripts from an IoT device.
+var RemoveScripts = scriptProcedure(
+	func(*InstalledScript) bool { return true },
+	Scripter.RemoveScriptRequests,
+)
+
+// PullScripts is a procedure implementation designed to download the code of the scripts installed on an IoT device,
+// into a directory of its own (e.g. <dir>/shellygen2_AABBCCDDEEFF/announce.js). Every script is downloaded,
+// unless script names are given.
+var PullScripts = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(Scripter)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: scripts", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	client := &http.Client{
+		Transport: tap.transport,
+	}
+
+	scripts, err := dev.InstalledScripts(client)
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	dir := filepath.Join(tap.pullDir, fmt.Sprintf("%s_%s", res.Driver(), strings.ReplaceAll(res.ID(), ":", "")))
+
+	for _, s := range selectScripts(scripts, tap.names, func(*InstalledScript) bool { return true }) {
+		code, err := dev.ScriptCode(client, s.ID)
+		if err != nil {
+			ch <- &ProcedureResult{
+				dev: res,
+				err: err,
+			}
+			return
+		}
+
+		if err = os.MkdirAll(dir, 0o700); err != nil {
+			ch <- &ProcedureResult{
+				dev: res,
+				err: err,
+			}
+			return
+		}
+
+		// Script names are set on the device, so they can't be trusted as paths
+		name := filepath.Base(filepath.Clean("/" + s.Name))
+		if name == string(filepath.Separator) {
+			name = fmt.Sprintf("script_%d.js", s.ID)
+		}
+
+		fp := filepath.Join(dir, name)
+
+		if err = os.WriteFile(fp, code, 0o600); err != nil {
+			ch <- &ProcedureResult{
+				dev: res,
+				err: err,
+			}
+			return
+		}
+
+		log.Printf("[%s] %s @ %s: script %s pulled to %s", res.Driver(), res.ID(), res.IP(), s.Name, fp)
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+	}
+}
+
+// ScriptStatus is a procedure implementation designed to add the status of the Deployment scripts on an IoT device
+// to a Report, by comparing their version markers with the deployment manifest (see Script.Status).
+// Templated scripts are rendered for each device beforehand.
+var ScriptStatus = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(Scripter)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: scripts", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	// Check if a deployment policy is set and enforce it
+	if tap.deployment.Policy != nil && tap.deployment.Policy.IsExcluded(res) {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: ErrPolicyExcluded,
+		}
+		return
+	}
+
+	dep, err := tap.deployment.render(NewTemplateData(res, tap.vars))
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	client := &http.Client{
+		Transport: tap.transport,
+	}
+
+	scripts, err := dev.InstalledScripts(client)
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	for _, src := range dep.Scripts {
+		i := slices.IndexFunc(scripts, func(s *InstalledScript) bool { return s.Name == src.Name() })
+		if i < 0 {
+			tap.report.Add(res, src.Name(), ScriptMissing, "", "")
+			continue
+		}
+
+		code, err := dev.ScriptCode(client, scripts[i].ID)
+		if err != nil {
+			ch <- &ProcedureResult{
+				dev: res,
+				err: err,
+			}
+			return
+		}
+
+		status, marker := src.Status(code)
+		if marker == nil {
+			tap.report.Add(res, src.Name(), status, "", "")
+			continue
+		}
+
+		tap.report.Add(res, src.Name(), status, marker.Source, marker.Deployed.Format(time.RFC3339))
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+	}
+}
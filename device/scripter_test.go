@@ -0,0 +1,328 @@
+package device
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type scripter struct {
+	funcError error
+	code      map[int][]byte
+	selected  []string
+	resource
+}
+
+func (s *scripter) InstalledScripts(*http.Client) ([]*InstalledScript, error) {
+	if s.funcError != nil {
+		return nil, s.funcError
+	}
+
+	return []*InstalledScript{
+		{Name: "announce.js", ID: 1, Enabled: true, Running: true},
+		{Name: "../helper", ID: 2},
+	}, nil
+}
+
+func (s *scripter) ScriptCode(_ *http.Client, id int) ([]byte, error) {
+	if code, ok := s.code[id]; ok {
+		return code, nil
+	}
+
+	if id == 1 {
+		return []byte(`print("announce");`), nil
+	}
+
+	return []byte(`print("helper");`), nil
+}
+
+func (s *scripter) requests(scripts []*InstalledScript) ([]*http.Request, error) {
+	for _, script := range scripts {
+		s.selected = append(s.selected, script.Name)
+	}
+
+	return []*http.Request{
+		{
+			URL:    &url.URL{},
+			Method: http.MethodGet,
+		},
+	}, nil
+}
+
+func (s *scripter) StartScriptRequests(scripts []*InstalledScript) ([]*http.Request, error) {
+	return s.requests(scripts)
+}
+
+func (s *scripter) StopScriptRequests(scripts []*InstalledScript) ([]*http.Request, error) {
+	return s.requests(scripts)
+}
+
+func (s *scripter) RemoveScriptRequests(scripts []*InstalledScript) ([]*http.Request, error) {
+	return s.requests(scripts)
+}
+
+func TestScriptProcedures(t *testing.T) {
+	ok := &roundTripper{
+		response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("{}")),
+		},
+	}
+
+	tests := []struct {
+		proc     procedure
+		rt       http.RoundTripper
+		dev      Resource
+		err      error
+		name     string
+		names    []string
+		selected []string
+		rows     int
+	}{
+		{
+			name: "failure: list unsupported procedure",
+			proc: ListScripts,
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: list function error",
+			proc: ListScripts,
+			dev: &scripter{
+				funcError: ErrUnexpected,
+			},
+			err: ErrUnexpected,
+		},
+		{
+			name: "success: list",
+			proc: ListScripts,
+			dev:  &scripter{},
+			rows: 2,
+		},
+		{
+			name:  "failure: start unsupported procedure",
+			proc:  StartScripts,
+			dev:   &resource{},
+			names: []string{"announce.js"},
+			err:   ErrUnsupportedProcedure,
+		},
+		{
+			name:  "failure: start function error",
+			proc:  StartScripts,
+			dev:   &scripter{funcError: ErrUnexpected},
+			names: []string{"announce.js"},
+			err:   ErrUnexpected,
+		},
+		{
+			name:  "failure: start running script",
+			proc:  StartScripts,
+			dev:   &scripter{},
+			names: []string{"announce.js"},
+			err:   ErrDeviceUnchanged,
+		},
+		{
+			name:  "failure: stop unknown script",
+			proc:  StopScripts,
+			dev:   &scripter{},
+			names: []string{"foo.js"},
+			err:   ErrDeviceUnchanged,
+		},
+		{
+			name:     "success: start stopped script",
+			proc:     StartScripts,
+			rt:       ok,
+			dev:      &scripter{},
+			names:    []string{"announce.js", "../helper"},
+			selected: []string{"../helper"},
+		},
+		{
+			name:     "success: stop running script",
+			proc:     StopScripts,
+			rt:       ok,
+			dev:      &scripter{},
+			names:    []string{"announce.js", "../helper"},
+			selected: []string{"announce.js"},
+		},
+		{
+			name:     "success: remove scripts",
+			proc:     RemoveScripts,
+			rt:       ok,
+			dev:      &scripter{},
+			names:    []string{"announce.js", "../helper"},
+			selected: []string{"announce.js", "../helper"},
+		},
+		{
+			name: "failure: pull unsupported procedure",
+			proc: PullScripts,
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: pull function error",
+			proc: PullScripts,
+			dev:  &scripter{funcError: ErrUnexpected},
+			err:  ErrUnexpected,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tap := &Tapper{
+				transport: test.rt,
+				report:    NewReport(),
+				names:     test.names,
+			}
+
+			ch := make(chan *ProcedureResult, 1)
+
+			test.proc(tap, test.dev, ch)
+
+			result := <-ch
+
+			if !errors.Is(result.err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, result.err)
+			}
+
+			if tap.report.Len() != test.rows {
+				t.Fatalf("expected %d report rows, got %d", test.rows, tap.report.Len())
+			}
+
+			if s, ok := test.dev.(*scripter); ok && !reflect.DeepEqual(s.selected, test.selected) {
+				t.Fatalf("expected %q, got %q", test.selected, s.selected)
+			}
+		})
+	}
+}
+
+func TestScriptStatus(t *testing.T) {
+	deployed := time.Date(2026, time.March, 1, 10, 30, 0, 0, time.UTC)
+
+	announce := &Script{
+		path: "../testdata/announce.js",
+		code: []byte(`print("announce");`),
+	}
+
+	tests := []struct {
+		dev  Resource
+		dep  *Deployment
+		err  error
+		name string
+		rows [][]string
+	}{
+		{
+			name: "failure: unsupported procedure",
+			dev:  &resource{},
+			dep:  &Deployment{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: policy exclusion",
+			dev:  &scripter{},
+			dep: &Deployment{
+				Policy: &Policy{
+					Mode: PolicyModeWhitelist,
+				},
+			},
+			err: ErrPolicyExcluded,
+		},
+		{
+			name: "failure: function error",
+			dev:  &scripter{funcError: ErrUnexpected},
+			dep:  &Deployment{},
+			err:  ErrUnexpected,
+		},
+		{
+			name: "success",
+			dev: &scripter{
+				code: map[int][]byte{
+					1: announce.Marked(deployed),
+				},
+			},
+			dep: &Deployment{
+				Scripts: []*Script{
+					announce,
+					{path: "../testdata/helper", code: []byte(`print("helper");`)},
+					{path: "../testdata/foo.js", code: []byte(`print("foo");`)},
+				},
+			},
+			rows: [][]string{
+				{"announce.js", ScriptCurrent, "announce.js", "2026-03-01T10:30:00Z"},
+				{"helper", ScriptMissing, "", ""},
+				{"foo.js", ScriptMissing, "", ""},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tap := &Tapper{
+				deployment: test.dep,
+				report:     NewReport(),
+			}
+
+			ch := make(chan *ProcedureResult, 1)
+
+			ScriptStatus(tap, test.dev, ch)
+
+			if result := <-ch; !errors.Is(result.err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, result.err)
+			}
+
+			var rows [][]string
+			for _, row := range tap.report.rows {
+				rows = append(rows, row.values[len(reportHeader):])
+			}
+
+			if !reflect.DeepEqual(rows, test.rows) {
+				t.Fatalf("expected %q, got %q", test.rows, rows)
+			}
+		})
+	}
+}
+
+func TestPullScripts(t *testing.T) {
+	mac, _ := net.ParseMAC("AA:BB:CC:DD:EE:01")
+
+	tap := &Tapper{
+		pullDir: t.TempDir(),
+	}
+
+	ch := make(chan *ProcedureResult, 1)
+
+	PullScripts(tap, &scripter{
+		resource: resource{
+			driver: "foo",
+			mac:    mac,
+		},
+	}, ch)
+
+	if result := <-ch; result.err != nil {
+		t.Fatalf("expected nil, got %v", result.err)
+	}
+
+	expected := map[string]string{
+		"announce.js": `print("announce");`,
+		"helper":      `print("helper");`,
+	}
+
+	dir := filepath.Join(tap.pullDir, "foo_aabbccddee01")
+
+	for name, code := range expected {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("unable to read pulled script: %v", err)
+		}
+
+		if string(data) != code {
+			t.Fatalf("expected %q, got %q", code, data)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package device
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Snapshotter is an interface that provides a standard way to capture the current
+// configuration of the IoT device components that are affected by a Config.
+// Components set with write-only values (see HoldsKey) are left out, so they're never rolled back.
+type Snapshotter interface {
+	Snapshot(*http.Client, Config) (Config, error)
+}
+
+// HoldsKey checks if a settings map holds any of the given keys, at any depth.
+// Drivers use it to leave write-only settings (e.g. Wi-Fi passwords) out of snapshots, since devices don't report
+// their values, and restoring the settings around them (e.g. the previous SSID) would pair them with the new ones.
+func HoldsKey(settings map[string]any, keys ...string) bool {
+	for key, value := range settings {
+		if slices.Contains(keys, key) {
+			return true
+		}
+
+		if nested, ok := value.(map[string]any); ok && HoldsKey(nested, keys...) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Mask returns a copy of the src map, holding only the keys present in the shape map.
+// Nested maps are masked recursively, so that only the affected settings are kept.
+func Mask(src, shape map[string]any) map[string]any {
+	masked := make(map[string]any, len(shape))
+
+	for key, value := range shape {
+		current, ok := src[key]
+		if !ok {
+			continue
+		}
+
+		if nested, ok := value.(map[string]any); ok {
+			if cur, ok := current.(map[string]any); ok {
+				masked[key] = Mask(cur, nested)
+				continue
+			}
+		}
+
+		masked[key] = current
+	}
+
+	return masked
+}
+
+// SaveSnapshot writes a Config snapshot of a device to a JSON file in the given directory.
+// The resulting file can be used as a regular configuration file for manual recovery.
+func SaveSnapshot(dir string, res Resource, snap Config) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	fp := filepath.Join(dir, fmt.Sprintf(
+		"%s_%s_%s.json",
+		res.Driver(),
+		strings.ReplaceAll(res.ID(), ":", ""),
+		time.Now().Format("20060102T150405"),
+	))
+
+	f, err := os.Create(fp)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		err = f.Close()
+		if err != nil {
+			log.Printf("Snapshot close error: %v", err)
+		}
+	}()
+
+	err = json.MarshalWrite(f, snap, json.Deterministic(true), jsontext.WithIndent("  "))
+	if err != nil {
+		return "", err
+	}
+
+	return fp, nil
+}
@@ -0,0 +1,151 @@
+package device
+
+import (
+	"encoding/json/v2"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		src      map[string]any
+		shape    map[string]any
+		expected map[string]any
+		name     string
+	}{
+		{
+			name: "mask flat keys",
+			src: map[string]any{
+				"enable": true,
+				"server": "mqtt.local",
+				"ro":     "read only",
+			},
+			shape: map[string]any{
+				"enable": false,
+				"server": "broker.local",
+			},
+			expected: map[string]any{
+				"enable": true,
+				"server": "mqtt.local",
+			},
+		},
+		{
+			name: "mask nested keys",
+			src: map[string]any{
+				"device": map[string]any{
+					"name":    "kitchen",
+					"eco":     false,
+					"fw_id":   "20240101",
+					"profile": "switch",
+				},
+			},
+			shape: map[string]any{
+				"device": map[string]any{
+					"name": "lounge",
+				},
+			},
+			expected: map[string]any{
+				"device": map[string]any{
+					"name": "kitchen",
+				},
+			},
+		},
+		{
+			name: "skip keys missing from the source",
+			src:  map[string]any{},
+			shape: map[string]any{
+				"enable": true,
+			},
+			expected: map[string]any{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			masked := Mask(test.src, test.shape)
+
+			if !reflect.DeepEqual(masked, test.expected) {
+				t.Fatalf("expected %#v, got %#v", test.expected, masked)
+			}
+		})
+	}
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	res := &resource{
+		driver: "test",
+		mac:    net.HardwareAddr{0x00, 0x1A, 0x2B, 0x3C, 0x4D, 0x5E},
+	}
+
+	t.Run("failure: unable to create directory", func(t *testing.T) {
+		fp := filepath.Join(t.TempDir(), "file")
+
+		if err := os.WriteFile(fp, nil, 0o600); err != nil {
+			t.Fatalf("unable to create file: %v", err)
+		}
+
+		_, err := SaveSnapshot(filepath.Join(fp, "snapshots"), res, &config{Foo: "bar"})
+
+		var pe *fs.PathError
+		if !errors.As(err, &pe) {
+			t.Fatalf("expected %#v, got %#v", &fs.PathError{}, err)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		fp, err := SaveSnapshot(t.TempDir(), res, &config{Foo: "bar"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, err := os.ReadFile(fp)
+		if err != nil {
+			t.Fatalf("unable to read snapshot: %v", err)
+		}
+
+		cfg := &config{}
+		if err = json.Unmarshal(data, cfg); err != nil {
+			t.Fatalf("unable to decode snapshot: %v", err)
+		}
+
+		if cfg.Foo != "bar" {
+			t.Fatalf("expected %q, got %q", "bar", cfg.Foo)
+		}
+	})
+}
+
+func TestHoldsKey(t *testing.T) {
+	tests := []struct {
+		settings map[string]any
+		name     string
+		holds    bool
+	}{
+		{
+			name:     "flat key",
+			settings: map[string]any{"ssid": "home", "key": "secret"},
+			holds:    true,
+		},
+		{
+			name:     "nested key",
+			settings: map[string]any{"config": map[string]any{"sta": map[string]any{"ssid": "home", "pass": "secret"}}},
+			holds:    true,
+		},
+		{
+			name:     "missing key",
+			settings: map[string]any{"config": map[string]any{"sta": map[string]any{"ssid": "home"}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if holds := HoldsKey(test.settings, "key", "pass"); holds != test.holds {
+				t.Fatalf("expected %t, got %t", test.holds, holds)
+			}
+		})
+	}
+}
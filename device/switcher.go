@@ -0,0 +1,101 @@
+package device
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ActionToggle flips an output channel, alongside the Job actions (on, off).
+const ActionToggle = "toggle"
+
+// Switch holds an output action to perform on IoT devices, with an optional Policy
+// restricting the devices to perform it on.
+type Switch struct {
+	Policy  *Policy
+	Action  string
+	Channel int
+	Timer   time.Duration
+}
+
+// NewSwitch creates a new *Switch instance.
+// The timer turns the output back off once it elapses, so it can only be set when turning it on.
+// It returns an error if the action is unknown, the channel is negative or the timer is invalid.
+func NewSwitch(action string, channel int, timer time.Duration) (*Switch, error) {
+	if action != ActionOn && action != ActionOff && action != ActionToggle {
+		return nil, fmt.Errorf("%w: action %q must be one of: %s, %s, %s", ErrInvalidSwitch, action, ActionOn, ActionOff, ActionToggle)
+	}
+
+	if channel < 0 {
+		return nil, fmt.Errorf("%w: negative channel %d", ErrInvalidSwitch, channel)
+	}
+
+	if timer < 0 || timer > 0 && timer < time.Second {
+		return nil, fmt.Errorf("%w: timer %s must be at least 1s", ErrInvalidSwitch, timer)
+	}
+
+	if timer > 0 && action != ActionOn {
+		return nil, fmt.Errorf("%w: timer can't be set with the %s action", ErrInvalidSwitch, action)
+	}
+
+	return &Switch{
+		Action:  action,
+		Channel: channel,
+		Timer:   timer,
+	}, nil
+}
+
+// Switcher is an interface that provides a standard way to operate the output channels (i.e. relay, switch)
+// of IoT devices, turning them on, off or toggling them, with an optional auto-off timer.
+// The resulting output state is returned (true if on).
+type Switcher interface {
+	Switch(*http.Client, int, string, time.Duration) (bool, error)
+}
+
+// outputState returns the textual representation of an output state.
+func outputState(on bool) string {
+	if on {
+		return ActionOn
+	}
+
+	return ActionOff
+}
+
+// SwitchOutput is a procedure implementation designed to operate an output channel of an IoT device,
+// adding its resulting state to a Report.
+var SwitchOutput = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(Switcher)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: switch", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	if tap.output.Policy != nil && tap.output.Policy.IsExcluded(res) {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: ErrPolicyExcluded,
+		}
+		return
+	}
+
+	on, err := dev.Switch(&http.Client{
+		Transport: tap.transport,
+	}, tap.output.Channel, tap.output.Action, tap.output.Timer)
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	tap.report.Add(res, strconv.Itoa(tap.output.Channel), outputState(on))
+
+	ch <- &ProcedureResult{
+		dev: res,
+	}
+}
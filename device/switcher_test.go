@@ -0,0 +1,179 @@
+package device
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type switcher struct {
+	funcError error
+	action    string
+	resource
+}
+
+func (s *switcher) Switch(_ *http.Client, _ int, action string, _ time.Duration) (bool, error) {
+	if s.funcError != nil {
+		return false, s.funcError
+	}
+
+	s.action = action
+
+	return action != ActionOff, nil
+}
+
+func TestNewSwitch(t *testing.T) {
+	tests := []struct {
+		sw      *Switch
+		err     error
+		name    string
+		action  string
+		channel int
+		timer   time.Duration
+	}{
+		{
+			name:   "failure: invalid action",
+			action: "flip",
+			err:    ErrInvalidSwitch,
+		},
+		{
+			name:    "failure: negative channel",
+			action:  ActionOn,
+			channel: -1,
+			err:     ErrInvalidSwitch,
+		},
+		{
+			name:   "failure: negative timer",
+			action: ActionOn,
+			timer:  -time.Second,
+			err:    ErrInvalidSwitch,
+		},
+		{
+			name:   "failure: sub-second timer",
+			action: ActionOn,
+			timer:  500 * time.Millisecond,
+			err:    ErrInvalidSwitch,
+		},
+		{
+			name:   "failure: timer with the toggle action",
+			action: ActionToggle,
+			timer:  time.Minute,
+			err:    ErrInvalidSwitch,
+		},
+		{
+			name:    "success: off",
+			action:  ActionOff,
+			channel: 1,
+			sw: &Switch{
+				Action:  ActionOff,
+				Channel: 1,
+			},
+		},
+		{
+			name:   "success: on with timer",
+			action: ActionOn,
+			timer:  time.Minute,
+			sw: &Switch{
+				Action: ActionOn,
+				Timer:  time.Minute,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sw, err := NewSwitch(test.action, test.channel, test.timer)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if !reflect.DeepEqual(sw, test.sw) {
+				t.Fatalf("expected %#v, got %#v", test.sw, sw)
+			}
+		})
+	}
+}
+
+func TestSwitchOutput(t *testing.T) {
+	tests := []struct {
+		dev    Resource
+		policy *Policy
+		err    error
+		name   string
+		action string
+		rows   [][]string
+	}{
+		{
+			name:   "failure: unsupported procedure",
+			dev:    &resource{},
+			action: ActionOn,
+			err:    ErrUnsupportedProcedure,
+		},
+		{
+			name:   "failure: policy exclusion",
+			dev:    &switcher{resource: resource{model: "SHSW-1"}},
+			policy: &Policy{Models: []string{"SHSW-1"}, Mode: PolicyModeBlacklist},
+			action: ActionOn,
+			err:    ErrPolicyExcluded,
+		},
+		{
+			name:   "failure: function error",
+			dev:    &switcher{funcError: ErrChannelNotFound},
+			action: ActionOn,
+			err:    ErrChannelNotFound,
+		},
+		{
+			name:   "success: on",
+			dev:    &switcher{resource: resource{model: "SNSW-001X16EU"}},
+			policy: &Policy{Models: []string{"SNSW"}, Mode: PolicyModeWhitelist},
+			action: ActionOn,
+			rows: [][]string{
+				{"2", "on"},
+			},
+		},
+		{
+			name:   "success: off",
+			dev:    &switcher{},
+			action: ActionOff,
+			rows: [][]string{
+				{"2", "off"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tap := &Tapper{
+				output: &Switch{
+					Policy:  test.policy,
+					Action:  test.action,
+					Channel: 2,
+				},
+				report: NewReport(),
+			}
+
+			ch := make(chan *ProcedureResult, 1)
+
+			SwitchOutput(tap, test.dev, ch)
+
+			if result := <-ch; !errors.Is(result.err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, result.err)
+			}
+
+			var rows [][]string
+			for _, row := range tap.report.rows {
+				rows = append(rows, row.values[len(reportHeader):])
+			}
+
+			if !reflect.DeepEqual(rows, test.rows) {
+				t.Fatalf("expected %q, got %q", test.rows, rows)
+			}
+
+			if s, ok := test.dev.(*switcher); ok && test.rows != nil && s.action != test.action {
+				t.Fatalf("expected %q, got %q", test.action, s.action)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package device
 import (
 	"encoding/json/jsontext"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"net/url"
@@ -20,13 +21,27 @@ const (
 
 // Tapper knows how to tap into devices and execute tasks on them.
 type Tapper struct {
-	config     Config
-	transport  http.RoundTripper
-	cred       *Credentials
-	auth       *AuthConfig
-	deployment *Deployment
-	probers    []Prober
-	timeout    time.Duration
+	config      Config
+	transport   http.RoundTripper
+	cred        *Credentials
+	auth        *AuthConfig
+	deployment  *Deployment
+	schedule    *Schedule
+	webhooks    *Webhooks
+	kvs         *KeyValues
+	virtual     *VirtualComponents
+	rpc         *RPC
+	query       *Query
+	output      *Switch
+	report      *Report
+	vars        Variables
+	keys        []string
+	names       []string
+	snapshotDir string
+	pullDir     string
+	probers     []Prober
+	timeout     time.Duration
+	prune       bool
 }
 
 // NewTapper creates a new *Tapper instance.
@@ -57,6 +72,96 @@ func (t *Tapper) SetDeployment(dep *Deployment) {
 	t.deployment = dep
 }
 
+// SetSchedule passed by the user.
+func (t *Tapper) SetSchedule(sch *Schedule) {
+	t.schedule = sch
+}
+
+// SetWebhooks passed by the user.
+func (t *Tapper) SetWebhooks(wh *Webhooks) {
+	t.webhooks = wh
+}
+
+// SetKeyValues passed by the user.
+func (t *Tapper) SetKeyValues(kv *KeyValues) {
+	t.kvs = kv
+}
+
+// SetVirtualComponents passed by the user.
+func (t *Tapper) SetVirtualComponents(v *VirtualComponents) {
+	t.virtual = v
+}
+
+// SetRPC passed by the user.
+func (t *Tapper) SetRPC(rpc *RPC) {
+	t.rpc = rpc
+}
+
+// SetQuery passed by the user.
+func (t *Tapper) SetQuery(q *Query) {
+	t.query = q
+}
+
+// SetSwitch passed by the user.
+func (t *Tapper) SetSwitch(sw *Switch) {
+	t.output = sw
+}
+
+// SetKeys of the device key-value store entries to get or delete.
+func (t *Tapper) SetKeys(keys []string) {
+	t.keys = keys
+}
+
+// SetScriptNames of the device scripts to manage.
+func (t *Tapper) SetScriptNames(names []string) {
+	t.names = names
+}
+
+// SetPullDir where device scripts are downloaded.
+func (t *Tapper) SetPullDir(dir string) {
+	t.pullDir = dir
+}
+
+// SetPrune enables the removal of device items that aren't declared by the user (e.g. scheduled jobs).
+func (t *Tapper) SetPrune(prune bool) {
+	t.prune = prune
+}
+
+// SetReport where procedures add their tabular results.
+func (t *Tapper) SetReport(rep *Report) {
+	t.report = rep
+}
+
+// SetVariables holding user defined values for each device.
+func (t *Tapper) SetVariables(vars Variables) {
+	t.vars = vars
+}
+
+// configFor returns the Config to be applied to a specific device,
+// with its overrides merged and its templates rendered.
+// Devices without a matching MultiConfig section are excluded.
+func (t *Tapper) configFor(res Resource) (Config, error) {
+	cfg := t.config
+
+	if mc, ok := cfg.(MultiConfig); ok {
+		if cfg, ok = mc.For(res.Driver()); !ok {
+			return nil, fmt.Errorf("%w: missing %s configuration section", ErrPolicyExcluded, res.Driver())
+		}
+	}
+
+	cfg, err := ApplyOverrides(cfg, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return RenderConfig(cfg, NewTemplateData(res, t.vars))
+}
+
+// SetSnapshotDir where device configuration snapshots are kept.
+func (t *Tapper) SetSnapshotDir(dir string) {
+	t.snapshotDir = dir
+}
+
 // probeIP for a specific IoT device.
 func probeIP(prober Prober, client *http.Client, ip net.IP) (Resource, error) {
 	r, dev, err := prober.Request(ip)
@@ -175,7 +280,7 @@ func (t *Tapper) Execute(proc procedure, devices Collection) (int, error) {
 		}
 
 		// Skipped devices
-		if errors.Is(result.err, ErrPolicyExcluded) {
+		if errors.Is(result.err, ErrPolicyExcluded) || errors.Is(result.err, ErrDeviceUnchanged) {
 			continue
 		}
 
@@ -78,6 +78,67 @@ func TestTapper_SetDeployment(t *testing.T) {
 	}
 }
 
+func TestTapper_SetSnapshotDir(t *testing.T) {
+	tap := &Tapper{}
+
+	tap.SetSnapshotDir("/tmp/snapshots")
+
+	if tap.snapshotDir != "/tmp/snapshots" {
+		t.Fatal("snapshot directory mismatch")
+	}
+}
+
+func TestTapper_configFor(t *testing.T) {
+	tests := []struct {
+		cfg      Config
+		expected Config
+		err      error
+		name     string
+	}{
+		{
+			name: "failure: missing driver configuration section",
+			cfg: MultiConfig{
+				"foo": &config{Foo: "bar"},
+			},
+			err: ErrPolicyExcluded,
+		},
+		{
+			name: "success: driver configuration section",
+			cfg: MultiConfig{
+				"test": &config{Foo: "bar"},
+			},
+			expected: &config{Foo: "bar"},
+		},
+		{
+			name:     "success: single driver configuration",
+			cfg:      &config{Foo: "baz"},
+			expected: &config{Foo: "baz"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tap := &Tapper{
+				config: test.cfg,
+			}
+
+			cfg, err := tap.configFor(templateResource)
+
+			if test.expected == nil && cfg != nil {
+				t.Fatalf("expected nil, got %#v", cfg)
+			}
+
+			if test.expected != nil && !reflect.DeepEqual(cfg, test.expected) {
+				t.Fatalf("expected %#v, got %#v", test.expected, cfg)
+			}
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+		})
+	}
+}
+
 func TestTapper_probe(t *testing.T) {
 	tests := []struct {
 		prober Prober
@@ -191,10 +252,16 @@ func (p *prober) Unmarshaler() *json.Unmarshalers {
 type roundTripper struct {
 	response *http.Response
 	err      error
+	hang     bool // Block until the request is cancelled, like an unresponsive device
 }
 
 // RoundTrip implements the http.RoundTripper interface.
-func (rt *roundTripper) RoundTrip(_ *http.Request) (*http.Response, error) {
+func (rt *roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	if rt.hang {
+		<-r.Context().Done()
+		return nil, r.Context().Err()
+	}
+
 	return rt.response, rt.err
 }
 
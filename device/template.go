@@ -0,0 +1,167 @@
+package device
+
+import (
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// TemplateData holds the per device values that can be referenced in templates.
+type TemplateData struct {
+	Vars       map[string]string
+	Driver     string
+	MAC        string
+	IP         string
+	Model      string
+	Name       string
+	Generation string
+}
+
+// NewTemplateData creates a new *TemplateData instance from a device and its user variables.
+func NewTemplateData(res Resource, vars Variables) *TemplateData {
+	return &TemplateData{
+		Vars:       vars.Lookup(res),
+		Driver:     res.Driver(),
+		MAC:        res.MAC().String(),
+		IP:         res.IP().String(),
+		Model:      res.Model(),
+		Name:       res.Name(),
+		Generation: res.Generation(),
+	}
+}
+
+// RenderString executes a text/template string against the given data.
+// Strings without template actions are returned untouched.
+func RenderString(text string, data *TemplateData) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tpl, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err = tpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// render walks a value, returning a copy where every string has been rendered as a template.
+// Pointers to values other than maps, slices and strings (e.g. *Policy) are returned as they are.
+func render(v reflect.Value, data *TemplateData) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		switch v.Elem().Kind() {
+		case reflect.Map, reflect.Slice, reflect.String:
+			elem, err := render(v.Elem(), data)
+			if err != nil {
+				return v, err
+			}
+
+			ptr := reflect.New(v.Elem().Type())
+			ptr.Elem().Set(elem)
+
+			return ptr, nil
+		}
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		elem, err := render(v.Elem(), data)
+		if err != nil {
+			return v, err
+		}
+
+		iface := reflect.New(v.Type()).Elem()
+		iface.Set(elem)
+
+		return iface, nil
+
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v, nil
+		}
+
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, err := render(iter.Value(), data)
+			if err != nil {
+				return v, err
+			}
+
+			m.SetMapIndex(iter.Key(), elem)
+		}
+
+		return m, nil
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+
+		for i := range v.Len() {
+			elem, err := render(v.Index(i), data)
+			if err != nil {
+				return v, err
+			}
+
+			s.Index(i).Set(elem)
+		}
+
+		return s, nil
+
+	case reflect.String:
+		text, err := RenderString(v.String(), data)
+		if err != nil {
+			return v, err
+		}
+
+		str := reflect.New(v.Type()).Elem()
+		str.SetString(text)
+
+		return str, nil
+	}
+
+	return v, nil
+}
+
+// RenderConfig returns a copy of a Config, with every template in its settings rendered for a device.
+// The original Config is left untouched, so it can be rendered for multiple devices.
+func RenderConfig(cfg Config, data *TemplateData) (Config, error) {
+	src := reflect.ValueOf(cfg)
+	if src.Kind() != reflect.Pointer || src.IsNil() || src.Elem().Kind() != reflect.Struct {
+		return cfg, nil
+	}
+
+	dst := reflect.New(src.Elem().Type())
+
+	for i := range src.Elem().NumField() {
+		field := dst.Elem().Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		value, err := render(src.Elem().Field(i), data)
+		if err != nil {
+			return nil, err
+		}
+
+		field.Set(value)
+	}
+
+	return dst.Interface().(Config), nil
+}
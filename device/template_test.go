@@ -0,0 +1,195 @@
+package device
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+// settings type for testing purposes.
+type settings map[string]any
+
+// templateConfig implementation for testing purposes.
+type templateConfig struct {
+	Policy    *Policy      `json:"policy,omitempty"`
+	Name      *string      `json:"name,omitempty"`
+	Sys       *settings    `json:"sys,omitempty"`
+	Switch    *[]*settings `json:"switch,omitempty"`
+	Overrides *[]*Override `json:"overrides,omitempty"`
+}
+
+// DeviceOverrides returns the per device configuration overrides.
+func (c *templateConfig) DeviceOverrides() []*Override {
+	if c.Overrides == nil {
+		return nil
+	}
+
+	return *c.Overrides
+}
+
+// Driver name of this Config implementation.
+func (c *templateConfig) Driver() string {
+	return "test"
+}
+
+// Empty checks if the struct holding the configuration has a zero value.
+func (c *templateConfig) Empty() bool {
+	return *c == templateConfig{}
+}
+
+var templateResource = &resource{
+	driver: "test",
+	name:   "plug",
+	model:  "SNPL-00112EU",
+	gen:    "2",
+	ip:     net.ParseIP("192.168.146.123"),
+	mac:    net.HardwareAddr{0xAA, 0xBB, 0xCC, 0xDD, 0xEE, 0x01},
+}
+
+func TestNewTemplateData(t *testing.T) {
+	data := NewTemplateData(templateResource, expectedVariables)
+
+	expected := &TemplateData{
+		Vars: map[string]string{
+			"room":  "kitchen",
+			"topic": "home/kitchen",
+		},
+		Driver:     "test",
+		MAC:        "aa:bb:cc:dd:ee:01",
+		IP:         "192.168.146.123",
+		Model:      "SNPL-00112EU",
+		Name:       "plug",
+		Generation: "2",
+	}
+
+	if !reflect.DeepEqual(data, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, data)
+	}
+}
+
+func TestRenderString(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		failed   bool
+	}{
+		{
+			name:     "success: plain string",
+			text:     "plain",
+			expected: "plain",
+		},
+		{
+			name:     "success: device fields",
+			text:     "{{ .Model }}-{{ .Name }}@{{ .IP }}",
+			expected: "SNPL-00112EU-plug@192.168.146.123",
+		},
+		{
+			name:     "success: user variables",
+			text:     "{{ .Vars.topic }}",
+			expected: "home/kitchen",
+		},
+		{
+			name:   "failure: parse error",
+			text:   "{{ .Name ",
+			failed: true,
+		},
+		{
+			name:   "failure: missing variable",
+			text:   "{{ .Vars.floor }}",
+			failed: true,
+		},
+	}
+
+	data := NewTemplateData(templateResource, expectedVariables)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			text, err := RenderString(test.text, data)
+
+			if (err != nil) != test.failed {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if text != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, text)
+			}
+		})
+	}
+}
+
+func TestRenderConfig(t *testing.T) {
+	name := "{{ .Vars.room }}"
+	policy := &Policy{
+		Mode: PolicyModeBlacklist,
+	}
+
+	cfg := &templateConfig{
+		Policy: policy,
+		Name:   &name,
+		Sys: &settings{
+			"config": map[string]any{
+				"device": map[string]any{
+					"name": "{{ .Vars.room }}-{{ .MAC }}",
+				},
+				"eco_mode": true,
+			},
+		},
+		Switch: &[]*settings{
+			{
+				"id": 0,
+				"config": map[string]any{
+					"name": "{{ .Name }}",
+				},
+			},
+		},
+	}
+
+	rendered, err := RenderConfig(cfg, NewTemplateData(templateResource, expectedVariables))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	kitchen := "kitchen"
+	expected := &templateConfig{
+		Policy: policy,
+		Name:   &kitchen,
+		Sys: &settings{
+			"config": map[string]any{
+				"device": map[string]any{
+					"name": "kitchen-aa:bb:cc:dd:ee:01",
+				},
+				"eco_mode": true,
+			},
+		},
+		Switch: &[]*settings{
+			{
+				"id": 0,
+				"config": map[string]any{
+					"name": "plug",
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(rendered, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, rendered)
+	}
+
+	// The original Config must remain untouched
+	if name != "{{ .Vars.room }}" {
+		t.Fatalf("original config was modified: %q", name)
+	}
+
+	invalid := "{{ .Foo }}"
+
+	_, err = RenderConfig(&templateConfig{Name: &invalid}, &TemplateData{})
+	if err == nil {
+		t.Fatal("expected template execution error")
+	}
+
+	nilCfg, err := RenderConfig(nil, &TemplateData{})
+	if nilCfg != nil || err != nil {
+		t.Fatalf("expected nil config and error, got %#v, %v", nilCfg, err)
+	}
+}
@@ -0,0 +1,137 @@
+package device
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Variables holds user defined values for IoT devices, keyed by MAC address.
+type Variables map[string]map[string]string
+
+// normaliseMAC returns the canonical string representation of a MAC address.
+func normaliseMAC(address string) (string, error) {
+	mac, err := net.ParseMAC(Macify(strings.ToLower(strings.TrimSpace(address))))
+	if err != nil {
+		return "", err
+	}
+
+	return mac.String(), nil
+}
+
+// Lookup returns the variables of a device, or nil if none were defined.
+func (v Variables) Lookup(res Resource) map[string]string {
+	return v[res.MAC().String()]
+}
+
+// NewVariablesJSON creates a Variables instance by parsing JSON data from the provided reader.
+// The data is expected to be an object keyed by MAC address, holding an object of variables.
+// It returns an error if the data is invalid or cannot be parsed.
+func NewVariablesJSON(r io.Reader) (Variables, error) {
+	var tmp map[string]map[string]string
+	if err := json.UnmarshalRead(r, &tmp); err != nil {
+		return nil, err
+	}
+
+	vars := make(Variables, len(tmp))
+
+	for address, values := range tmp {
+		mac, err := normaliseMAC(address)
+		if err != nil {
+			return nil, err
+		}
+
+		vars[mac] = values
+	}
+
+	return vars, nil
+}
+
+// NewVariablesCSV creates a Variables instance by parsing CSV data from the provided reader.
+// The first row must hold the column names, the first column being the device MAC address.
+// It returns an error if the data is invalid or cannot be parsed.
+func NewVariablesCSV(r io.Reader) (Variables, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrVariablesEmpty
+	}
+
+	header := rows[0]
+	vars := make(Variables, len(rows)-1)
+
+	for _, row := range rows[1:] {
+		mac, err := normaliseMAC(row[0])
+		if err != nil {
+			return nil, err
+		}
+
+		values := make(map[string]string, len(row)-1)
+		for i := 1; i < len(row); i++ {
+			values[strings.TrimSpace(header[i])] = row[i]
+		}
+
+		vars[mac] = values
+	}
+
+	return vars, nil
+}
+
+// LoadVariables creates a new Variables instance from a CSV, JSON, YAML or TOML file at the given path.
+// It returns an error if the file cannot be opened or contains invalid data.
+func LoadVariables(fp string) (Variables, error) {
+	if fp == "" {
+		return nil, ErrFilePathEmpty
+	}
+
+	var parse func(io.Reader) (Variables, error)
+
+	switch ext := strings.ToLower(filepath.Ext(fp)); ext {
+	case ".csv":
+		parse = NewVariablesCSV
+
+	case ".json":
+		parse = NewVariablesJSON
+
+	case ".yaml", ".yml", ".toml":
+		parse = func(r io.Reader) (Variables, error) {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+
+			if data, err = ToJSON(FileFormat(fp), data); err != nil {
+				return nil, err
+			}
+
+			return NewVariablesJSON(bytes.NewReader(data))
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFileFormat, ext)
+	}
+
+	f, err := os.Open(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err = f.Close()
+		if err != nil {
+			log.Printf("Variables close error: %v", err)
+		}
+	}()
+
+	return parse(f)
+}
@@ -0,0 +1,234 @@
+package device
+
+import (
+	"encoding/csv"
+	"encoding/json/jsontext"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var expectedVariables = Variables{
+	"aa:bb:cc:dd:ee:01": {
+		"room":  "kitchen",
+		"topic": "home/kitchen",
+	},
+	"aa:bb:cc:dd:ee:02": {
+		"room":  "lounge",
+		"topic": "home/lounge",
+	},
+}
+
+func TestVariables_Lookup(t *testing.T) {
+	res := &resource{
+		mac: net.HardwareAddr{0xAA, 0xBB, 0xCC, 0xDD, 0xEE, 0x01},
+	}
+
+	vars := expectedVariables.Lookup(res)
+	if vars["room"] != "kitchen" {
+		t.Fatalf("expected %q, got %q", "kitchen", vars["room"])
+	}
+
+	if Variables(nil).Lookup(res) != nil {
+		t.Fatal("expected nil variables")
+	}
+}
+
+func TestNewVariablesJSON(t *testing.T) {
+	tests := []struct {
+		r    io.Reader
+		vars Variables
+		err  error
+		name string
+	}{
+		{
+			name: "failure: syntactic error",
+			r:    strings.NewReader(`{`),
+			err:  &jsontext.SyntacticError{},
+		},
+		{
+			name: "failure: invalid MAC address",
+			r:    strings.NewReader(`{"foo":{"room":"kitchen"}}`),
+			err:  &net.AddrError{},
+		},
+		{
+			name: "success",
+			r:    strings.NewReader(`{"AA:BB:CC:DD:EE:01":{"room":"kitchen"}}`),
+			vars: Variables{
+				"aa:bb:cc:dd:ee:01": {
+					"room": "kitchen",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vars, err := NewVariablesJSON(test.r)
+
+			if !reflect.DeepEqual(vars, test.vars) {
+				t.Fatalf("expected %#v, got %#v", test.vars, vars)
+			}
+
+			var (
+				syntacticError *jsontext.SyntacticError
+				addrError      *net.AddrError
+			)
+
+			switch {
+			case errors.As(test.err, &syntacticError):
+				var se *jsontext.SyntacticError
+				if errors.As(err, &se) {
+					return
+				}
+
+			case errors.As(test.err, &addrError):
+				var ae *net.AddrError
+				if errors.As(err, &ae) {
+					return
+				}
+
+			case errors.Is(err, test.err):
+				return
+			}
+
+			t.Fatalf("expected %#v, got %#v", test.err, err)
+		})
+	}
+}
+
+func TestNewVariablesCSV(t *testing.T) {
+	tests := []struct {
+		r    io.Reader
+		vars Variables
+		err  error
+		name string
+	}{
+		{
+			name: "failure: parse error",
+			r:    strings.NewReader("mac,room\naabbccddee01,kitchen,extra\n"),
+			err:  &csv.ParseError{},
+		},
+		{
+			name: "failure: variables empty",
+			r:    strings.NewReader(""),
+			err:  ErrVariablesEmpty,
+		},
+		{
+			name: "failure: invalid MAC address",
+			r:    strings.NewReader("mac,room\nfoo,kitchen\n"),
+			err:  &net.AddrError{},
+		},
+		{
+			name: "success",
+			r:    strings.NewReader("mac,room\naabbccddee01,kitchen\n"),
+			vars: Variables{
+				"aa:bb:cc:dd:ee:01": {
+					"room": "kitchen",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vars, err := NewVariablesCSV(test.r)
+
+			if !reflect.DeepEqual(vars, test.vars) {
+				t.Fatalf("expected %#v, got %#v", test.vars, vars)
+			}
+
+			var (
+				parseError *csv.ParseError
+				addrError  *net.AddrError
+			)
+
+			switch {
+			case errors.As(test.err, &parseError):
+				var pe *csv.ParseError
+				if errors.As(err, &pe) {
+					return
+				}
+
+			case errors.As(test.err, &addrError):
+				var ae *net.AddrError
+				if errors.As(err, &ae) {
+					return
+				}
+
+			case errors.Is(err, test.err):
+				return
+			}
+
+			t.Fatalf("expected %#v, got %#v", test.err, err)
+		})
+	}
+}
+
+func TestLoadVariables(t *testing.T) {
+	tests := []struct {
+		vars Variables
+		err  error
+		name string
+		fp   string
+	}{
+		{
+			name: "failure: empty file path",
+			err:  ErrFilePathEmpty,
+		},
+		{
+			name: "failure: unsupported file format",
+			fp:   "../testdata/script1.js",
+			err:  ErrUnsupportedFileFormat,
+		},
+		{
+			name: "failure: file path not found",
+			fp:   "foo.csv",
+			err:  &fs.PathError{},
+		},
+		{
+			name: "success: CSV file",
+			fp:   "../testdata/variables.csv",
+			vars: expectedVariables,
+		},
+		{
+			name: "success: JSON file",
+			fp:   "../testdata/variables.json",
+			vars: expectedVariables,
+		},
+		{
+			name: "success: YAML file",
+			fp:   "../testdata/variables.yaml",
+			vars: expectedVariables,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vars, err := LoadVariables(test.fp)
+
+			if !reflect.DeepEqual(vars, test.vars) {
+				t.Fatalf("expected %#v, got %#v", test.vars, vars)
+			}
+
+			var pathError *fs.PathError
+			switch {
+			case errors.As(test.err, &pathError):
+				var pe *fs.PathError
+				if errors.As(err, &pe) {
+					return
+				}
+
+			case errors.Is(err, test.err):
+				return
+
+			default:
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+		})
+	}
+}
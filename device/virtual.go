@@ -0,0 +1,205 @@
+package device
+
+import (
+	"bytes"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"slices"
+)
+
+// Virtual component ID boundaries.
+const (
+	VirtualMinID = 200
+	VirtualMaxID = 299
+)
+
+// VirtualTypes holds the supported virtual component types.
+var VirtualTypes = []string{
+	"boolean",
+	"number",
+	"text",
+	"enum",
+	"group",
+	"button",
+}
+
+// VirtualComponent is a user defined component (e.g. a dashboard value), identified by its type and ID.
+type VirtualComponent struct {
+	Config map[string]any `json:"config,omitempty"`
+	Type   string         `json:"type"`
+	ID     int            `json:"id"`
+}
+
+// Key returns the component key (i.e. type:id).
+func (vc *VirtualComponent) Key() string {
+	return fmt.Sprintf("%s:%d", vc.Type, vc.ID)
+}
+
+// Name returns the configured component name, if any.
+func (vc *VirtualComponent) Name() string {
+	name, _ := vc.Config["name"].(string)
+
+	return name
+}
+
+// VirtualComponents holds the virtual components to sync on one or more IoT devices, along with a policy to enforce.
+type VirtualComponents struct {
+	Policy  *Policy             `json:"policy,omitempty"`
+	Virtual []*VirtualComponent `json:"virtual"`
+}
+
+// render returns a copy of the components, with their configuration templates rendered for a device.
+func (v *VirtualComponents) render(data *TemplateData) ([]*VirtualComponent, error) {
+	components := make([]*VirtualComponent, 0, len(v.Virtual))
+
+	for _, vc := range v.Virtual {
+		cfg, err := render(reflect.ValueOf(vc.Config), data)
+		if err != nil {
+			return nil, err
+		}
+
+		components = append(components, &VirtualComponent{
+			Type:   vc.Type,
+			ID:     vc.ID,
+			Config: cfg.Interface().(map[string]any),
+		})
+	}
+
+	return components, nil
+}
+
+// Virtualizer is an interface that provides a standard way to manage virtual components on IoT devices.
+// Virtual requests create the missing components and update the changed ones, returning the device
+// components that aren't declared, unless pruning is requested, in which case they're deleted.
+type Virtualizer interface {
+	VirtualComponents(*http.Client) ([]*VirtualComponent, error)
+	VirtualRequests(*http.Client, []*VirtualComponent, bool) ([]*http.Request, []*VirtualComponent, error)
+}
+
+// NewVirtualComponents creates a new *VirtualComponents instance by parsing data from the provided reader.
+// Environment variable and file references are resolved beforehand (see Interpolate).
+// It returns an error if the data is invalid or cannot be parsed.
+func NewVirtualComponents(r io.Reader) (*VirtualComponents, error) {
+	data, err := interpolateRead(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var v VirtualComponents
+	if err = json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(v.Virtual))
+
+	for i, vc := range v.Virtual {
+		if !slices.Contains(VirtualTypes, vc.Type) || vc.ID < VirtualMinID || vc.ID > VirtualMaxID || keys[vc.Key()] {
+			return nil, fmt.Errorf("component %d: %w", i, ErrInvalidComponent)
+		}
+
+		keys[vc.Key()] = true
+	}
+
+	return &v, nil
+}
+
+// LoadVirtualComponents creates a new *VirtualComponents instance from a file at the given path.
+// Base files listed under the "extends" key are merged beforehand (see ReadFile).
+// It returns an error if the file cannot be opened or contains invalid data.
+func LoadVirtualComponents(fp string) (*VirtualComponents, error) {
+	data, err := ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewVirtualComponents(bytes.NewReader(data))
+}
+
+// ListVirtual is a procedure implementation designed to add the virtual components of an IoT device to a Report.
+var ListVirtual = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(Virtualizer)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: virtual", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	components, err := dev.VirtualComponents(&http.Client{
+		Transport: tap.transport,
+	})
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	for _, vc := range components {
+		tap.report.Add(res, vc.Key(), vc.Name())
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+	}
+}
+
+// ApplyVirtual is a procedure implementation designed to sync VirtualComponents to an IoT device.
+// Component configuration templates are rendered for each device, prior to being applied.
+// Device components that aren't declared are logged, unless pruning is enabled, in which case they're deleted.
+var ApplyVirtual = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(Virtualizer)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: virtual", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	// Check if a virtual component policy is set and enforce it
+	if tap.virtual.Policy != nil && tap.virtual.Policy.IsExcluded(res) {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: ErrPolicyExcluded,
+		}
+		return
+	}
+
+	components, err := tap.virtual.render(NewTemplateData(res, tap.vars))
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	rs, extra, err := dev.VirtualRequests(&http.Client{
+		Transport: tap.transport,
+	}, components, tap.prune)
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	if !tap.prune {
+		for _, vc := range extra {
+			log.Printf("[%s] %s @ %s: component %s isn't declared, use --prune to delete it", res.Driver(), res.ID(), res.IP(), vc.Key())
+		}
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+		err: dispatch(tap, res, rs),
+	}
+}
@@ -0,0 +1,291 @@
+package device
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+type virtualizer struct {
+	funcError  error
+	components []*VirtualComponent
+	resource
+}
+
+func (v *virtualizer) VirtualComponents(*http.Client) ([]*VirtualComponent, error) {
+	if v.funcError != nil {
+		return nil, v.funcError
+	}
+
+	return []*VirtualComponent{
+		{Type: "boolean", ID: 200, Config: map[string]any{"name": "Heating"}},
+		{Type: "button", ID: 201},
+	}, nil
+}
+
+func (v *virtualizer) VirtualRequests(_ *http.Client, components []*VirtualComponent, _ bool) ([]*http.Request, []*VirtualComponent, error) {
+	if v.funcError != nil {
+		return nil, nil, v.funcError
+	}
+
+	v.components = components
+
+	return []*http.Request{
+		{
+			URL:    &url.URL{},
+			Method: http.MethodGet,
+		},
+	}, []*VirtualComponent{
+		{Type: "button", ID: 201},
+	}, nil
+}
+
+func TestVirtualComponent(t *testing.T) {
+	vc := &VirtualComponent{
+		Type:   "number",
+		ID:     200,
+		Config: map[string]any{"name": "Target temperature"},
+	}
+
+	if key := vc.Key(); key != "number:200" {
+		t.Fatalf("expected number:200, got %s", key)
+	}
+
+	if name := vc.Name(); name != "Target temperature" {
+		t.Fatalf("expected Target temperature, got %s", name)
+	}
+}
+
+func TestNewVirtualComponents(t *testing.T) {
+	tests := []struct {
+		err        error
+		name       string
+		data       string
+		components int
+	}{
+		{
+			name: "failure: invalid JSON",
+			data: `{`,
+			err:  io.ErrUnexpectedEOF,
+		},
+		{
+			name: "failure: invalid type",
+			data: `{"virtual":[{"type":"switch","id":200}]}`,
+			err:  ErrInvalidComponent,
+		},
+		{
+			name: "failure: ID out of range",
+			data: `{"virtual":[{"type":"boolean","id":1}]}`,
+			err:  ErrInvalidComponent,
+		},
+		{
+			name: "failure: duplicate component",
+			data: `{"virtual":[{"type":"boolean","id":200},{"type":"boolean","id":200}]}`,
+			err:  ErrInvalidComponent,
+		},
+		{
+			name:       "success",
+			data:       `{"virtual":[{"type":"boolean","id":200},{"type":"number","id":200,"config":{"min":0}}]}`,
+			components: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := NewVirtualComponents(strings.NewReader(test.data))
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if v != nil && len(v.Virtual) != test.components {
+				t.Fatalf("expected %d components, got %d", test.components, len(v.Virtual))
+			}
+		})
+	}
+}
+
+func TestLoadVirtualComponents(t *testing.T) {
+	tests := []struct {
+		err        error
+		name       string
+		fp         string
+		components int
+	}{
+		{
+			name: "failure: empty file path",
+			err:  ErrFilePathEmpty,
+		},
+		{
+			name: "failure: file not found",
+			fp:   "../testdata/missing.json",
+			err:  fs.ErrNotExist,
+		},
+		{
+			name:       "success",
+			fp:         "../testdata/virtual.json",
+			components: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := LoadVirtualComponents(test.fp)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if v != nil && len(v.Virtual) != test.components {
+				t.Fatalf("expected %d components, got %d", test.components, len(v.Virtual))
+			}
+		})
+	}
+}
+
+func TestVirtualProcedures(t *testing.T) {
+	ok := &roundTripper{
+		response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("{}")),
+		},
+	}
+
+	mac, _ := net.ParseMAC("AA:BB:CC:DD:EE:01")
+
+	tests := []struct {
+		proc       procedure
+		rt         http.RoundTripper
+		dev        Resource
+		v          *VirtualComponents
+		err        error
+		name       string
+		components []*VirtualComponent
+		rows       int
+	}{
+		{
+			name: "failure: list unsupported procedure",
+			proc: ListVirtual,
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: list function error",
+			proc: ListVirtual,
+			dev: &virtualizer{
+				funcError: ErrUnexpected,
+			},
+			err: ErrUnexpected,
+		},
+		{
+			name: "success: list",
+			proc: ListVirtual,
+			dev:  &virtualizer{},
+			rows: 2,
+		},
+		{
+			name: "failure: apply unsupported procedure",
+			proc: ApplyVirtual,
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: apply policy exclusion",
+			proc: ApplyVirtual,
+			dev:  &virtualizer{},
+			v: &VirtualComponents{
+				Policy: &Policy{
+					Mode: PolicyModeWhitelist,
+				},
+			},
+			err: ErrPolicyExcluded,
+		},
+		{
+			name: "failure: apply template error",
+			proc: ApplyVirtual,
+			dev:  &virtualizer{},
+			v: &VirtualComponents{
+				Virtual: []*VirtualComponent{
+					{Type: "boolean", ID: 200, Config: map[string]any{"name": "{{ .Foo }}"}},
+				},
+			},
+			err: template.ExecError{},
+		},
+		{
+			name: "failure: apply function error",
+			proc: ApplyVirtual,
+			dev: &virtualizer{
+				funcError: ErrUnexpected,
+			},
+			v:   &VirtualComponents{},
+			err: ErrUnexpected,
+		},
+		{
+			name: "success: apply",
+			proc: ApplyVirtual,
+			dev: &virtualizer{
+				resource: resource{
+					mac: mac,
+				},
+			},
+			rt: ok,
+			v: &VirtualComponents{
+				Virtual: []*VirtualComponent{
+					{Type: "boolean", ID: 200, Config: map[string]any{"name": "{{ .Vars.room }} heating"}},
+					{Type: "button", ID: 201},
+				},
+			},
+			components: []*VirtualComponent{
+				{Type: "boolean", ID: 200, Config: map[string]any{"name": "kitchen heating"}},
+				{Type: "button", ID: 201},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tap := &Tapper{
+				transport: test.rt,
+				virtual:   test.v,
+				report:    NewReport(),
+				vars: Variables{
+					"aa:bb:cc:dd:ee:01": {"room": "kitchen"},
+				},
+			}
+
+			ch := make(chan *ProcedureResult, 1)
+
+			test.proc(tap, test.dev, ch)
+
+			result := <-ch
+
+			var execError template.ExecError
+			if errors.As(test.err, &execError) {
+				if !errors.As(result.err, &execError) {
+					t.Fatalf("expected %#v, got %#v", test.err, result.err)
+				}
+
+				return
+			}
+
+			if !errors.Is(result.err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, result.err)
+			}
+
+			if tap.report.Len() != test.rows {
+				t.Fatalf("expected %d report rows, got %d", test.rows, tap.report.Len())
+			}
+
+			if v, ok := test.dev.(*virtualizer); ok && test.components != nil {
+				if !reflect.DeepEqual(v.components, test.components) {
+					t.Fatalf("expected %#v, got %#v", test.components, v.components)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package device
+
+import (
+	"bytes"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Hook is an event webhook, calling one or more URLs when a device event occurs.
+// Events are driver specific (e.g. "out_on_url" for Shelly Gen1, "switch.on" for Shelly Gen2),
+// and hooks for events a device doesn't support are skipped. URLs can be templated (see RenderString).
+type Hook struct {
+	Enable  *bool    `json:"enable,omitempty"`
+	Event   string   `json:"event"`
+	Name    string   `json:"name,omitempty"`
+	URLs    []string `json:"urls"`
+	Channel int      `json:"channel"`
+}
+
+// Enabled checks if the Hook is enabled, which is the default.
+func (h *Hook) Enabled() bool {
+	return h.Enable == nil || *h.Enable
+}
+
+// Webhooks holds the hooks to sync on one or more IoT devices, along with a policy to enforce.
+type Webhooks struct {
+	Policy *Policy `json:"policy,omitempty"`
+	Hooks  []*Hook `json:"hooks"`
+}
+
+// render returns a copy of the hooks, with their URLs rendered for a device.
+func (w *Webhooks) render(data *TemplateData) ([]*Hook, error) {
+	hooks := make([]*Hook, 0, len(w.Hooks))
+
+	for _, h := range w.Hooks {
+		hook := *h
+		hook.URLs = make([]string, 0, len(h.URLs))
+
+		for _, u := range h.URLs {
+			rendered, err := RenderString(u, data)
+			if err != nil {
+				return nil, err
+			}
+
+			hook.URLs = append(hook.URLs, rendered)
+		}
+
+		hooks = append(hooks, &hook)
+	}
+
+	return hooks, nil
+}
+
+// InstalledHook is a webhook found on a device, in a display friendly form.
+type InstalledHook struct {
+	ID      string
+	Event   string
+	URLs    []string
+	Channel int
+	Enabled bool
+}
+
+// Webhooker is an interface that provides a standard way to manage event webhooks on IoT devices.
+// Hook requests create or update the hooks that differ, returning the device hooks that aren't declared,
+// unless pruning is requested, in which case they're removed.
+type Webhooker interface {
+	InstalledHooks(*http.Client) ([]*InstalledHook, error)
+	HookRequests(*http.Client, []*Hook, bool) ([]*http.Request, []*InstalledHook, error)
+}
+
+// NewWebhooks creates a new *Webhooks instance by parsing data from the provided reader.
+// Environment variable and file references are resolved beforehand (see Interpolate).
+// It returns an error if the data is invalid or cannot be parsed.
+func NewWebhooks(r io.Reader) (*Webhooks, error) {
+	data, err := interpolateRead(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var wh Webhooks
+	if err = json.Unmarshal(data, &wh); err != nil {
+		return nil, err
+	}
+
+	for i, hook := range wh.Hooks {
+		if hook.Event == "" || len(hook.URLs) == 0 || hook.Channel < 0 {
+			return nil, fmt.Errorf("hook %d: %w", i, ErrInvalidHook)
+		}
+	}
+
+	return &wh, nil
+}
+
+// LoadWebhooks creates a new *Webhooks instance from a file at the given path.
+// Base webhook files listed under the "extends" key are merged beforehand (see ReadFile).
+// It returns an error if the file cannot be opened or contains invalid data.
+func LoadWebhooks(fp string) (*Webhooks, error) {
+	data, err := ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWebhooks(bytes.NewReader(data))
+}
+
+// ListWebhooks is a procedure implementation designed to add the webhooks of an IoT device to a Report.
+var ListWebhooks = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(Webhooker)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: webhooks", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	hooks, err := dev.InstalledHooks(&http.Client{
+		Transport: tap.transport,
+	})
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	for _, h := range hooks {
+		tap.report.Add(res, h.ID, h.Event, strconv.Itoa(h.Channel), strconv.FormatBool(h.Enabled), strings.Join(h.URLs, " "))
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+	}
+}
+
+// ApplyWebhooks is a procedure implementation designed to sync Webhooks to an IoT device.
+// Hook URL templates are rendered for each device, prior to being applied.
+// Device hooks that aren't declared are logged, unless pruning is enabled, in which case they're removed.
+var ApplyWebhooks = func(tap *Tapper, res Resource, ch chan<- *ProcedureResult) {
+	dev, ok := res.(Webhooker)
+	if !ok {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: fmt.Errorf("%w: webhooks", ErrUnsupportedProcedure),
+		}
+		return
+	}
+
+	// Check if a webhook policy is set and enforce it
+	if tap.webhooks.Policy != nil && tap.webhooks.Policy.IsExcluded(res) {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: ErrPolicyExcluded,
+		}
+		return
+	}
+
+	hooks, err := tap.webhooks.render(NewTemplateData(res, tap.vars))
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	rs, extra, err := dev.HookRequests(&http.Client{
+		Transport: tap.transport,
+	}, hooks, tap.prune)
+	if err != nil {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: err,
+		}
+		return
+	}
+
+	if !tap.prune {
+		for _, h := range extra {
+			log.Printf("[%s] %s @ %s: hook %s (%s) isn't declared, use --prune to remove it", res.Driver(), res.ID(), res.IP(), h.ID, h.Event)
+		}
+	}
+
+	if len(rs) == 0 {
+		ch <- &ProcedureResult{
+			dev: res,
+			err: ErrDeviceUnchanged,
+		}
+		return
+	}
+
+	ch <- &ProcedureResult{
+		dev: res,
+		err: dispatch(tap, res, rs),
+	}
+}
@@ -0,0 +1,290 @@
+package device
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+type webhooker struct {
+	funcError error
+	hooks     []*Hook
+	resource
+	unchanged bool
+}
+
+func (w *webhooker) InstalledHooks(*http.Client) ([]*InstalledHook, error) {
+	if w.funcError != nil {
+		return nil, w.funcError
+	}
+
+	return []*InstalledHook{
+		{ID: "1", Event: "switch.on", Enabled: true, URLs: []string{"http://example.com/on"}},
+	}, nil
+}
+
+func (w *webhooker) HookRequests(_ *http.Client, hooks []*Hook, _ bool) ([]*http.Request, []*InstalledHook, error) {
+	if w.funcError != nil {
+		return nil, nil, w.funcError
+	}
+
+	w.hooks = hooks
+
+	if w.unchanged {
+		return nil, nil, nil
+	}
+
+	return []*http.Request{
+		{
+			URL:    &url.URL{},
+			Method: http.MethodGet,
+		},
+	}, []*InstalledHook{
+		{ID: "2", Event: "switch.off", URLs: []string{"http://example.com/off"}},
+	}, nil
+}
+
+func TestNewWebhooks(t *testing.T) {
+	tests := []struct {
+		err   error
+		name  string
+		data  string
+		hooks int
+	}{
+		{
+			name: "failure: invalid JSON",
+			data: `{`,
+			err:  io.ErrUnexpectedEOF,
+		},
+		{
+			name: "failure: missing event",
+			data: `{"hooks":[{"urls":["http://example.com"]}]}`,
+			err:  ErrInvalidHook,
+		},
+		{
+			name: "failure: missing URLs",
+			data: `{"hooks":[{"event":"switch.on"}]}`,
+			err:  ErrInvalidHook,
+		},
+		{
+			name: "failure: negative channel",
+			data: `{"hooks":[{"event":"switch.on","channel":-1,"urls":["http://example.com"]}]}`,
+			err:  ErrInvalidHook,
+		},
+		{
+			name:  "success",
+			data:  `{"hooks":[{"event":"switch.on","channel":1,"enable":false,"urls":["http://example.com"]}]}`,
+			hooks: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wh, err := NewWebhooks(strings.NewReader(test.data))
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if wh != nil && len(wh.Hooks) != test.hooks {
+				t.Fatalf("expected %d hooks, got %d", test.hooks, len(wh.Hooks))
+			}
+		})
+	}
+}
+
+func TestLoadWebhooks(t *testing.T) {
+	tests := []struct {
+		err   error
+		name  string
+		fp    string
+		hooks int
+	}{
+		{
+			name: "failure: empty file path",
+			err:  ErrFilePathEmpty,
+		},
+		{
+			name: "failure: file not found",
+			fp:   "../testdata/missing.json",
+			err:  fs.ErrNotExist,
+		},
+		{
+			name:  "success",
+			fp:    "../testdata/webhooks.json",
+			hooks: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wh, err := LoadWebhooks(test.fp)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if wh != nil && len(wh.Hooks) != test.hooks {
+				t.Fatalf("expected %d hooks, got %d", test.hooks, len(wh.Hooks))
+			}
+		})
+	}
+}
+
+func TestWebhookProcedures(t *testing.T) {
+	ok := &roundTripper{
+		response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("{}")),
+		},
+	}
+
+	mac, _ := net.ParseMAC("AA:BB:CC:DD:EE:01")
+
+	tests := []struct {
+		proc procedure
+		rt   http.RoundTripper
+		dev  Resource
+		wh   *Webhooks
+		err  error
+		name string
+		urls []string
+		rows int
+	}{
+		{
+			name: "failure: list unsupported procedure",
+			proc: ListWebhooks,
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: list function error",
+			proc: ListWebhooks,
+			dev: &webhooker{
+				funcError: ErrUnexpected,
+			},
+			err: ErrUnexpected,
+		},
+		{
+			name: "success: list",
+			proc: ListWebhooks,
+			dev:  &webhooker{},
+			rows: 1,
+		},
+		{
+			name: "failure: apply unsupported procedure",
+			proc: ApplyWebhooks,
+			dev:  &resource{},
+			err:  ErrUnsupportedProcedure,
+		},
+		{
+			name: "failure: apply policy exclusion",
+			proc: ApplyWebhooks,
+			dev:  &webhooker{},
+			wh: &Webhooks{
+				Policy: &Policy{
+					Mode: PolicyModeWhitelist,
+				},
+			},
+			err: ErrPolicyExcluded,
+		},
+		{
+			name: "failure: apply template error",
+			proc: ApplyWebhooks,
+			dev:  &webhooker{},
+			wh: &Webhooks{
+				Hooks: []*Hook{
+					{Event: "switch.on", URLs: []string{"http://example.com/{{ .Foo }}"}},
+				},
+			},
+			err: template.ExecError{},
+		},
+		{
+			name: "failure: apply function error",
+			proc: ApplyWebhooks,
+			dev: &webhooker{
+				funcError: ErrUnexpected,
+			},
+			wh:  &Webhooks{},
+			err: ErrUnexpected,
+		},
+		{
+			name: "success: apply without changes",
+			proc: ApplyWebhooks,
+			dev: &webhooker{
+				unchanged: true,
+			},
+			wh:  &Webhooks{},
+			err: ErrDeviceUnchanged,
+		},
+		{
+			name: "success: apply",
+			proc: ApplyWebhooks,
+			dev: &webhooker{
+				resource: resource{
+					name: "kitchen",
+					mac:  mac,
+				},
+			},
+			rt: ok,
+			wh: &Webhooks{
+				Hooks: []*Hook{
+					{Event: "switch.on", URLs: []string{"http://example.com/{{ .Name }}?mac={{ .MAC }}&room={{ .Vars.room }}"}},
+				},
+			},
+			urls: []string{"http://example.com/kitchen?mac=aa:bb:cc:dd:ee:01&room=kitchen"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tap := &Tapper{
+				transport: test.rt,
+				webhooks:  test.wh,
+				report:    NewReport(),
+				vars: Variables{
+					"aa:bb:cc:dd:ee:01": {"room": "kitchen"},
+				},
+			}
+
+			ch := make(chan *ProcedureResult, 1)
+
+			test.proc(tap, test.dev, ch)
+
+			result := <-ch
+
+			var execError template.ExecError
+			if errors.As(test.err, &execError) {
+				if !errors.As(result.err, &execError) {
+					t.Fatalf("expected %#v, got %#v", test.err, result.err)
+				}
+
+				return
+			}
+
+			if !errors.Is(result.err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, result.err)
+			}
+
+			if tap.report.Len() != test.rows {
+				t.Fatalf("expected %d report rows, got %d", test.rows, tap.report.Len())
+			}
+
+			if wh, ok := test.dev.(*webhooker); ok && test.urls != nil {
+				if urls := wh.hooks[0].URLs; !reflect.DeepEqual(urls, test.urls) {
+					t.Fatalf("expected %q, got %q", test.urls, urls)
+				}
+
+				// The declared hooks must be left untouched
+				if test.wh.Hooks[0].URLs[0] == test.urls[0] {
+					t.Fatal("expected the declared hook URLs to remain unrendered")
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package httpclient
 
 import (
+	"encoding/json/jsontext"
 	"encoding/json/v2"
 	"fmt"
 	"io"
@@ -99,6 +100,15 @@ func (d *Dispatcher) Dispatch(r *http.Request, opts ...DispatchOption) error {
 		return err
 	}
 
+	// Error bodies are only bound when they hold JSON (e.g. RPC errors), since plain text ones can't be
+	if resp.StatusCode >= http.StatusBadRequest && (d.bind == nil || !jsontext.Value(b).IsValid()) {
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%w: %w: %s: status %d (body: %s)", errRequestUnsuccessful, ErrNotFound, r.URL.Path, resp.StatusCode, b)
+		}
+
+		return fmt.Errorf("%w: %s: status %d (body: %s)", errRequestUnsuccessful, r.URL.Path, resp.StatusCode, b)
+	}
+
 	if d.bind != nil {
 		if d.unmarshaler != nil {
 			return json.Unmarshal(b, &d.bind, json.WithUnmarshalers(d.unmarshaler))
@@ -107,9 +117,5 @@ func (d *Dispatcher) Dispatch(r *http.Request, opts ...DispatchOption) error {
 		return json.Unmarshal(b, &d.bind)
 	}
 
-	if resp.StatusCode >= http.StatusBadRequest {
-		return fmt.Errorf("%w: %s: status %d (body: %s)", errRequestUnsuccessful, r.URL.Path, resp.StatusCode, b)
-	}
-
 	return nil
 }
@@ -193,6 +193,39 @@ func TestDispatcher_Dispatch(t *testing.T) {
 
 			err: errRequestUnsuccessful,
 		},
+		{
+			name: "failure: not found with binding",
+			req: &http.Request{
+				Method: http.MethodGet,
+				URL:    uri,
+			},
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusNotFound,
+					Body:       io.NopCloser(strings.NewReader("Not Found")),
+				},
+			},
+			opts: []DispatchOption{
+				WithBinding(make(map[string]any)),
+			},
+			err: ErrNotFound,
+		},
+		{
+			name: "success: unmarshal error body",
+			req: &http.Request{
+				Method: http.MethodGet,
+				URL:    uri,
+			},
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusInternalServerError,
+					Body:       io.NopCloser(strings.NewReader(`{"code":-105,"message":"not found"}`)),
+				},
+			},
+			opts: []DispatchOption{
+				WithBinding(make(map[string]any)),
+			},
+		},
 		{
 			name: "success: no body",
 			req: &http.Request{
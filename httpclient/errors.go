@@ -2,6 +2,9 @@ package httpclient
 
 import "errors"
 
+// ErrNotFound is returned when the requested resource doesn't exist, along with the unsuccessful request error.
+var ErrNotFound = errors.New("HTTP resource not found")
+
 var (
 	errRequestUnauthorised = errors.New("unauthorised HTTP request")
 	errRequestUnsuccessful = errors.New("unsuccessful HTTP request")
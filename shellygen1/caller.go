@@ -0,0 +1,56 @@
+package shellygen1
+
+import (
+	"encoding/json/jsontext"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/quetzyg/IoTap/device"
+	"github.com/quetzyg/IoTap/httpclient"
+)
+
+// Call an arbitrary HTTP API path on the device (e.g. status, relay/0), returning its response.
+// Parameters are sent as query-string values.
+func (d *Device) Call(client *http.Client, path string, params map[string]any) ([]byte, error) {
+	values := make(url.Values, len(params))
+
+	for key, value := range params {
+		values.Set(key, fmt.Sprint(value))
+	}
+
+	r, err := request(d, path, values)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp jsontext.Value
+
+	dispatcher := httpclient.NewDispatcher(client)
+
+	if err = dispatcher.Dispatch(r, httpclient.WithBinding(&resp)); err != nil {
+		return nil, err
+	}
+
+	if err = resp.Compact(); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// documentPaths maps document kinds to the HTTP API paths returning them.
+var documentPaths = map[string]string{
+	device.DocumentStatus: "status",
+	device.DocumentConfig: "settings",
+}
+
+// Document returns the status or configuration document of the device.
+func (d *Device) Document(client *http.Client, kind string) ([]byte, error) {
+	path, ok := documentPaths[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", device.ErrUnsupportedDocument, kind)
+	}
+
+	return d.Call(client, path, nil)
+}
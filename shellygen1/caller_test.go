@@ -0,0 +1,132 @@
+package shellygen1
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+// recorder is an http.RoundTripper that keeps the requests it gets, replying with the same body.
+type recorder struct {
+	requests []*http.Request
+	body     string
+	status   int
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (rec *recorder) RoundTrip(r *http.Request) (*http.Response, error) {
+	rec.requests = append(rec.requests, r)
+
+	status := http.StatusOK
+	if rec.status != 0 {
+		status = rec.status
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(rec.body)),
+	}, nil
+}
+
+func TestDevice_Call(t *testing.T) {
+	tests := []struct {
+		dev      *Device
+		params   map[string]any
+		err      error
+		name     string
+		path     string
+		url      string
+		expected string
+	}{
+		{
+			name: "failure: missing credentials",
+			dev:  &Device{ip: net.ParseIP("192.168.1.10"), secured: true},
+			path: "status",
+			err:  device.ErrMissingCredentials,
+		},
+		{
+			name:     "success: without params",
+			dev:      &Device{ip: net.ParseIP("192.168.1.10")},
+			path:     "status",
+			url:      "http://192.168.1.10/status",
+			expected: `{"relays":[{"ison":true}]}`,
+		},
+		{
+			name:     "success: with params",
+			dev:      &Device{ip: net.ParseIP("192.168.1.10")},
+			path:     "/relay/0",
+			params:   map[string]any{"turn": "on", "timer": float64(30)},
+			url:      "http://192.168.1.10/relay/0?timer=30&turn=on",
+			expected: `{"relays":[{"ison":true}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := &recorder{body: `{"relays": [{"ison": true}]}`}
+
+			resp, err := test.dev.Call(&http.Client{Transport: rec}, test.path, test.params)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if string(resp) != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, resp)
+			}
+
+			if test.url == "" {
+				return
+			}
+
+			if urls := requestURLs(rec.requests); len(urls) != 1 || urls[0] != test.url {
+				t.Fatalf("expected %q, got %q", test.url, urls)
+			}
+		})
+	}
+}
+
+func TestDevice_Document(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		kind string
+		url  string
+	}{
+		{
+			name: "failure: unsupported document",
+			kind: "foo",
+			err:  device.ErrUnsupportedDocument,
+		},
+		{
+			name: "success: status",
+			kind: device.DocumentStatus,
+			url:  "http://192.168.1.10/status",
+		},
+		{
+			name: "success: config",
+			kind: device.DocumentConfig,
+			url:  "http://192.168.1.10/settings",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := &recorder{body: `{"cloud":{"enabled":true}}`}
+
+			dev := &Device{ip: net.ParseIP("192.168.1.10")}
+
+			if _, err := dev.Document(&http.Client{Transport: rec}, test.kind); !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if urls := requestURLs(rec.requests); test.url != "" && (len(urls) != 1 || urls[0] != test.url) {
+				t.Fatalf("expected %q, got %q", test.url, urls)
+			}
+		})
+	}
+}
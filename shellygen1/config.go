@@ -7,19 +7,27 @@ import "github.com/quetzyg/IoTap/device"
 type settings map[string]any
 
 // Config implementation for the Shelly Gen1 driver.
+// The device mode is set before any other endpoint, since the
+// roller, light, color and white endpoints depend on it.
 type Config struct {
-	Policy                 *device.Policy `json:"policy,omitempty"`
-	Settings               *settings      `json:"settings,omitempty"`
-	SettingsAP             *settings      `json:"settings_ap,omitempty"`
-	SettingsSTA            *settings      `json:"settings_sta,omitempty"`
-	SettingsSTA1           *settings      `json:"settings_sta1,omitempty"`
-	SettingsCloud          *settings      `json:"settings_cloud,omitempty"`
-	SettingsActions        *[]*settings   `json:"settings_actions,omitempty"`
-	SettingsRelay          *[]*settings   `json:"settings_relay,omitempty"`
-	SettingsPower          *[]*settings   `json:"settings_power,omitempty"`
-	SettingsExtTemperature *[]*settings   `json:"settings_ext_temperature,omitempty"`
-	SettingsExtHumidity    *[]*settings   `json:"settings_ext_humidity,omitempty"`
-	SettingsExtSwitch      *[]*settings   `json:"settings_ext_switch,omitempty"`
+	Policy                 *device.Policy      `json:"policy,omitempty"`
+	Mode                   *string             `json:"mode,omitempty"`
+	Settings               *settings           `json:"settings,omitempty"`
+	SettingsAP             *settings           `json:"settings_ap,omitempty"`
+	SettingsSTA            *settings           `json:"settings_sta,omitempty"`
+	SettingsSTA1           *settings           `json:"settings_sta1,omitempty"`
+	SettingsCloud          *settings           `json:"settings_cloud,omitempty"`
+	SettingsActions        *[]*settings        `json:"settings_actions,omitempty"`
+	SettingsRelay          *[]*settings        `json:"settings_relay,omitempty"`
+	SettingsPower          *[]*settings        `json:"settings_power,omitempty"`
+	SettingsExtTemperature *[]*settings        `json:"settings_ext_temperature,omitempty"`
+	SettingsExtHumidity    *[]*settings        `json:"settings_ext_humidity,omitempty"`
+	SettingsExtSwitch      *[]*settings        `json:"settings_ext_switch,omitempty"`
+	SettingsRoller         *[]*settings        `json:"settings_roller,omitempty"`
+	SettingsLight          *[]*settings        `json:"settings_light,omitempty"`
+	SettingsColor          *[]*settings        `json:"settings_color,omitempty"`
+	SettingsWhite          *[]*settings        `json:"settings_white,omitempty"`
+	Overrides              *[]*device.Override `json:"overrides,omitempty"`
 }
 
 // Driver name of this Config implementation.
@@ -27,6 +35,15 @@ func (c *Config) Driver() string {
 	return Driver
 }
 
+// DeviceOverrides returns the per device configuration overrides.
+func (c *Config) DeviceOverrides() []*device.Override {
+	if c.Overrides == nil {
+		return nil
+	}
+
+	return *c.Overrides
+}
+
 // Empty checks if the struct holding the configuration has a zero value.
 func (c *Config) Empty() bool {
 	return *c == Config{}
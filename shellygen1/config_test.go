@@ -2,6 +2,8 @@ package shellygen1
 
 import (
 	"testing"
+
+	"github.com/quetzyg/IoTap/device"
 )
 
 func TestConfig_Driver(t *testing.T) {
@@ -12,6 +14,26 @@ func TestConfig_Driver(t *testing.T) {
 	}
 }
 
+func TestConfig_DeviceOverrides(t *testing.T) {
+	if (&Config{}).DeviceOverrides() != nil {
+		t.Fatal("expected nil overrides")
+	}
+
+	cfg := &Config{
+		Overrides: &[]*device.Override{
+			{
+				Match: &device.Matcher{
+					Names: []string{"^server-room"},
+				},
+			},
+		},
+	}
+
+	if len(cfg.DeviceOverrides()) != 1 {
+		t.Fatalf("expected 1 override, got %d", len(cfg.DeviceOverrides()))
+	}
+}
+
 func TestConfig_Empty(t *testing.T) {
 	tests := []struct {
 		cfg   *Config
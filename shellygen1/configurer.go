@@ -13,6 +13,7 @@ var paths = map[string]string{
 	// Common HTTP API configuration endpoint paths
 	// See: https://shelly-api-docs.shelly.cloud/gen1/#common-http-api
 	"settings":         "settings",
+	"mode":             "settings",
 	"settings_ap":      "settings/ap",
 	"settings_sta":     "settings/sta",
 	"settings_sta1":    "settings/sta1",
@@ -26,6 +27,16 @@ var paths = map[string]string{
 	"settings_ext_temperature": "settings/ext_temperature/%d",
 	"settings_ext_humidity":    "settings/ext_humidity/%d",
 	"settings_ext_switch":      "settings/ext_switch/%d",
+
+	// See: https://shelly-api-docs.shelly.cloud/gen1/#shelly2-5
+	"settings_roller": "settings/roller/%d",
+
+	// See: https://shelly-api-docs.shelly.cloud/gen1/#shelly-dimmer-1-2
+	"settings_light": "settings/light/%d",
+
+	// See: https://shelly-api-docs.shelly.cloud/gen1/#shelly-rgbw2-color
+	"settings_color": "settings/color/%d",
+	"settings_white": "settings/white/%d",
 }
 
 // ConfigureRequests generates a slice of *http.Requests that are to be executed in order to configure an IoT device.
@@ -57,6 +68,14 @@ func (d *Device) ConfigureRequests(config device.Config) ([]*http.Request, error
 		path := paths[tag]
 
 		switch params := setting.Interface().(type) {
+		case *string:
+			r, err := request(d, path, &settings{tag: *params})
+			if err != nil {
+				return nil, err
+			}
+
+			requests = append(requests, r)
+
 		case *settings:
 			r, err := request(d, path, params)
 			if err != nil {
@@ -67,12 +86,14 @@ func (d *Device) ConfigureRequests(config device.Config) ([]*http.Request, error
 
 		case *[]*settings:
 			for j, p := range *params {
+				indexed := path
+
 				// Handle paths that require an index
 				if strings.Contains(path, "%d") {
-					path = fmt.Sprintf(path, j)
+					indexed = fmt.Sprintf(path, j)
 				}
 
-				r, err := request(d, path, p)
+				r, err := request(d, indexed, p)
 				if err != nil {
 					return nil, err
 				}
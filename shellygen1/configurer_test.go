@@ -140,6 +140,78 @@ func TestDevice_ConfigureRequests(t *testing.T) {
 				return []*http.Request{r1, r2}
 			}(),
 		},
+		{
+			name: "success: device mode and indexed settings",
+			cfg: &Config{
+				Mode: func() *string {
+					mode := "white"
+					return &mode
+				}(),
+				SettingsWhite: &[]*settings{
+					{
+						"brightness": 50,
+					},
+					{
+						"brightness":    75,
+						"default_state": "last",
+					},
+				},
+			},
+			rs: func() []*http.Request {
+				r1 := &http.Request{
+					Method: http.MethodGet,
+					URL: &url.URL{
+						Scheme:   "http",
+						Host:     "192.168.146.123",
+						Path:     "settings",
+						RawQuery: "mode=white",
+					},
+					Header: http.Header{},
+				}
+
+				r1.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
+
+				r2 := &http.Request{
+					Method: http.MethodGet,
+					URL: &url.URL{
+						Scheme:   "http",
+						Host:     "192.168.146.123",
+						Path:     "settings/white/0",
+						RawQuery: "brightness=50",
+					},
+					Header: http.Header{},
+				}
+
+				r2.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
+
+				r3 := &http.Request{
+					Method: http.MethodGet,
+					URL: &url.URL{
+						Scheme:   "http",
+						Host:     "192.168.146.123",
+						Path:     "settings/white/1",
+						RawQuery: "brightness=75&default_state=last",
+					},
+					Header: http.Header{},
+				}
+
+				r3.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
+
+				r4 := &http.Request{
+					Method: http.MethodGet,
+					URL: &url.URL{
+						Scheme: "http",
+						Host:   "192.168.146.123",
+						Path:   rebootPath,
+					},
+					Header: http.Header{},
+				}
+
+				r4.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
+
+				return []*http.Request{r1, r2, r3, r4}
+			}(),
+		},
 	}
 
 	shelly1 := &Device{ip: net.ParseIP("192.168.146.123")}
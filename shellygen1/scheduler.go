@@ -0,0 +1,193 @@
+package shellygen1
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/quetzyg/IoTap/device"
+	"github.com/quetzyg/IoTap/httpclient"
+)
+
+// relaySchedule holds the schedule settings of a relay.
+type relaySchedule struct {
+	Schedule bool     `json:"schedule"`
+	Rules    []string `json:"schedule_rules"`
+}
+
+// scheduleResponse holds the relay schedules of a settings endpoint request.
+type scheduleResponse struct {
+	Relays []*relaySchedule `json:"relays"`
+}
+
+// scheduleRule converts a driver agnostic device.Job into a relay schedule rule (i.e. HHMM-DAYS-ACTION),
+// where days are listed by index, starting on Monday (0).
+// See: https://shelly-api-docs.shelly.cloud/gen1/#settings-relay-index
+func scheduleRule(j *device.Job) string {
+	var days strings.Builder
+
+	for _, day := range j.Weekdays() {
+		days.WriteString(strconv.Itoa(day))
+	}
+
+	hour, minute := j.Clock()
+
+	return fmt.Sprintf("%02d%02d-%s-%s", hour, minute, days.String(), j.Action)
+}
+
+// scheduled returns the display friendly representation of a relay schedule rule.
+func scheduled(relay int, enabled bool, rule string) *device.ScheduledJob {
+	spec, action := rule, ""
+
+	if i := strings.LastIndex(rule, "-"); i >= 0 {
+		spec, action = rule[:i], rule[i+1:]
+	}
+
+	return &device.ScheduledJob{
+		ID:      fmt.Sprintf("relay/%d", relay),
+		Enabled: enabled,
+		Spec:    spec,
+		Action:  action,
+	}
+}
+
+// fetchSchedules returns the schedule settings of every device relay.
+func (d *Device) fetchSchedules(client *http.Client) ([]*relaySchedule, error) {
+	r, err := request(d, "settings", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &scheduleResponse{}
+
+	dispatcher := httpclient.NewDispatcher(client)
+
+	if err = dispatcher.Dispatch(r, httpclient.WithBinding(resp)); err != nil {
+		return nil, err
+	}
+
+	return resp.Relays, nil
+}
+
+// scheduleRequest creates a relay schedule settings request, enabling the schedule only when it has rules.
+func (d *Device) scheduleRequest(relay int, rules []string) (*http.Request, error) {
+	return request(d, fmt.Sprintf(paths["settings_relay"], relay), url.Values{
+		"schedule":       {strconv.FormatBool(len(rules) > 0)},
+		"schedule_rules": {strings.Join(rules, ",")},
+	})
+}
+
+// ScheduledJobs returns the relay schedule rules of the device.
+func (d *Device) ScheduledJobs(client *http.Client) ([]*device.ScheduledJob, error) {
+	relays, err := d.fetchSchedules(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*device.ScheduledJob
+
+	for i, relay := range relays {
+		for _, rule := range relay.Rules {
+			jobs = append(jobs, scheduled(i, relay.Schedule, rule))
+		}
+	}
+
+	return jobs, nil
+}
+
+// ScheduleRequests creates an ordered slice of *http.Request objects for syncing a device.Schedule.
+// Relay rules already on the device are kept, while the missing ones are appended.
+// Relay rules that aren't in the Schedule are returned, and removed when pruning.
+func (d *Device) ScheduleRequests(client *http.Client, sch *device.Schedule, prune bool) ([]*http.Request, []*device.ScheduledJob, error) {
+	relays, err := d.fetchSchedules(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wanted := make([][]string, len(relays))
+
+	for _, j := range sch.Jobs {
+		if j.Channel >= len(relays) {
+			return nil, nil, fmt.Errorf("%w: relay %d", device.ErrChannelNotFound, j.Channel)
+		}
+
+		if rule := scheduleRule(j); !slices.Contains(wanted[j.Channel], rule) {
+			wanted[j.Channel] = append(wanted[j.Channel], rule)
+		}
+	}
+
+	var (
+		requests []*http.Request
+		extra    []*device.ScheduledJob
+	)
+
+	for i, relay := range relays {
+		var rules []string
+
+		for _, rule := range relay.Rules {
+			if slices.Contains(wanted[i], rule) {
+				rules = append(rules, rule)
+				continue
+			}
+
+			extra = append(extra, scheduled(i, relay.Schedule, rule))
+
+			if !prune {
+				rules = append(rules, rule)
+			}
+		}
+
+		for _, rule := range wanted[i] {
+			if !slices.Contains(rules, rule) {
+				rules = append(rules, rule)
+			}
+		}
+
+		// Skip relays that are already in sync
+		if slices.Equal(rules, relay.Rules) && relay.Schedule == (len(rules) > 0) {
+			continue
+		}
+
+		// Leave relays without rules alone, when they never had any
+		if len(rules) == 0 && len(relay.Rules) == 0 {
+			continue
+		}
+
+		r, err := d.scheduleRequest(i, rules)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		requests = append(requests, r)
+	}
+
+	return requests, extra, nil
+}
+
+// ClearScheduleRequests creates a slice of *http.Request objects for removing every relay schedule rule.
+func (d *Device) ClearScheduleRequests(client *http.Client) ([]*http.Request, error) {
+	relays, err := d.fetchSchedules(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []*http.Request
+
+	for i, relay := range relays {
+		if len(relay.Rules) == 0 && !relay.Schedule {
+			continue
+		}
+
+		r, err := d.scheduleRequest(i, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		requests = append(requests, r)
+	}
+
+	return requests, nil
+}
@@ -0,0 +1,187 @@
+package shellygen1
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+const relaySettings = `{"relays":[` +
+	`{"schedule":true,"schedule_rules":["0800-04-on","1200-0123456-off"]},` +
+	`{"schedule":false,"schedule_rules":[]}` +
+	`]}`
+
+// settingsResponse returns a mocked settings endpoint response.
+func settingsResponse() *roundTripper {
+	return &roundTripper{
+		response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(relaySettings)),
+		},
+	}
+}
+
+// requestURLs returns the URLs of the requests.
+func requestURLs(rs []*http.Request) []string {
+	var urls []string
+
+	for _, r := range rs {
+		urls = append(urls, r.URL.String())
+	}
+
+	return urls
+}
+
+func TestScheduleRule(t *testing.T) {
+	tests := []struct {
+		job  *device.Job
+		name string
+		rule string
+	}{
+		{
+			name: "every day",
+			job:  &device.Job{Time: "18:30", Action: device.ActionOff},
+			rule: "1830-0123456-off",
+		},
+		{
+			name: "specific days",
+			job:  &device.Job{Time: "07:05", Days: []string{"sun", "mon"}, Action: device.ActionOn},
+			rule: "0705-06-on",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if rule := scheduleRule(test.job); rule != test.rule {
+				t.Fatalf("expected %q, got %q", test.rule, rule)
+			}
+		})
+	}
+}
+
+func TestDevice_ScheduledJobs(t *testing.T) {
+	shelly1 := &Device{ip: net.ParseIP("192.168.146.123")}
+
+	jobs, err := shelly1.ScheduledJobs(&http.Client{Transport: settingsResponse()})
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := []*device.ScheduledJob{
+		{ID: "relay/0", Enabled: true, Spec: "0800-04", Action: "on"},
+		{ID: "relay/0", Enabled: true, Spec: "1200-0123456", Action: "off"},
+	}
+
+	if !reflect.DeepEqual(jobs, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, jobs)
+	}
+}
+
+func TestDevice_ScheduleRequests(t *testing.T) {
+	tests := []struct {
+		rt    http.RoundTripper
+		sch   *device.Schedule
+		err   error
+		name  string
+		urls  []string
+		extra int
+		prune bool
+	}{
+		{
+			name: "failure: dispatch failed",
+			rt: &roundTripper{
+				err: net.ErrClosed,
+			},
+			sch: &device.Schedule{},
+			err: net.ErrClosed,
+		},
+		{
+			name: "failure: relay not found",
+			rt:   settingsResponse(),
+			sch: &device.Schedule{
+				Jobs: []*device.Job{
+					{Channel: 2, Time: "08:00", Action: device.ActionOn},
+				},
+			},
+			err: device.ErrChannelNotFound,
+		},
+		{
+			name: "success: relays in sync",
+			rt:   settingsResponse(),
+			sch: &device.Schedule{
+				Jobs: []*device.Job{
+					{Time: "08:00", Days: []string{"mon", "fri"}, Action: device.ActionOn},
+				},
+			},
+			extra: 1,
+		},
+		{
+			name: "success: rules appended",
+			rt:   settingsResponse(),
+			sch: &device.Schedule{
+				Jobs: []*device.Job{
+					{Time: "08:00", Days: []string{"mon", "fri"}, Action: device.ActionOn},
+					{Channel: 1, Time: "22:00", Action: device.ActionOff},
+				},
+			},
+			urls: []string{
+				"http://192.168.146.123/settings/relay/1?schedule=true&schedule_rules=2200-0123456-off",
+			},
+			extra: 1,
+		},
+		{
+			name: "success: rules pruned",
+			rt:   settingsResponse(),
+			sch: &device.Schedule{
+				Jobs: []*device.Job{
+					{Time: "08:00", Days: []string{"mon", "fri"}, Action: device.ActionOn},
+				},
+			},
+			prune: true,
+			urls: []string{
+				"http://192.168.146.123/settings/relay/0?schedule=true&schedule_rules=0800-04-on",
+			},
+			extra: 1,
+		},
+	}
+
+	shelly1 := &Device{ip: net.ParseIP("192.168.146.123")}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rs, extra, err := shelly1.ScheduleRequests(&http.Client{Transport: test.rt}, test.sch, test.prune)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if urls := requestURLs(rs); !reflect.DeepEqual(urls, test.urls) {
+				t.Fatalf("expected %q, got %q", test.urls, urls)
+			}
+
+			if len(extra) != test.extra {
+				t.Fatalf("expected %d extra jobs, got %d", test.extra, len(extra))
+			}
+		})
+	}
+}
+
+func TestDevice_ClearScheduleRequests(t *testing.T) {
+	shelly1 := &Device{ip: net.ParseIP("192.168.146.123")}
+
+	rs, err := shelly1.ClearScheduleRequests(&http.Client{Transport: settingsResponse()})
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := []string{"http://192.168.146.123/settings/relay/0?schedule=false&schedule_rules="}
+
+	if urls := requestURLs(rs); !reflect.DeepEqual(urls, expected) {
+		t.Fatalf("expected %q, got %q", expected, urls)
+	}
+}
@@ -0,0 +1,199 @@
+package shellygen1
+
+import "github.com/quetzyg/IoTap/device"
+
+// endpoint returns the Schema of an endpoint's settings.
+func endpoint(props map[string]*device.Schema) *device.Schema {
+	return device.ObjectSchema(props)
+}
+
+// indexed returns the Schema of an indexed endpoint's settings list, addressed by position.
+func indexed(props map[string]*device.Schema) *device.Schema {
+	return device.ArraySchema(device.ObjectSchema(props))
+}
+
+// station returns the Schema of a Wi-Fi station endpoint.
+func station() *device.Schema {
+	return endpoint(map[string]*device.Schema{
+		"enabled":     device.BooleanSchema(),
+		"ssid":        device.StringSchema(),
+		"key":         device.StringSchema(),
+		"ipv4_method": device.StringSchema("dhcp", "static"),
+		"ip":          device.StringSchema(),
+		"netmask":     device.StringSchema(),
+		"gateway":     device.StringSchema(),
+		"dns":         device.StringSchema(),
+	})
+}
+
+// threshold returns the Schema of an external sensor threshold action.
+func threshold() *device.Schema {
+	return device.StringSchema("disabled", "relay_on", "relay_off")
+}
+
+// output returns the Schema of an output endpoint's settings, with the settings shared by relays and lights.
+func output(props map[string]*device.Schema) *device.Schema {
+	props["name"] = device.StringSchema().Nullable()
+	props["default_state"] = device.StringSchema("off", "on", "last", "switch")
+	props["btn_type"] = device.StringSchema("momentary", "toggle", "edge", "detached", "action", "cycle", "momentary_on_release")
+	props["btn_reverse"] = device.BooleanSchema()
+	props["auto_on"] = device.NumberSchema().AtLeast(0)
+	props["auto_off"] = device.NumberSchema().AtLeast(0)
+	props["schedule"] = device.BooleanSchema()
+	props["schedule_rules"] = device.ArraySchema(device.StringSchema())
+
+	return indexed(props)
+}
+
+// channel returns the Schema of a colour channel level.
+func channel() *device.Schema {
+	return device.IntegerSchema().Between(0, 255)
+}
+
+// percentage returns the Schema of a percentage level.
+func percentage() *device.Schema {
+	return device.IntegerSchema().Between(0, 100)
+}
+
+// transition returns the Schema of a light transition duration, in milliseconds.
+func transition() *device.Schema {
+	return device.IntegerSchema().Between(0, 5000)
+}
+
+// endpoints holds the Schemas of the supported Gen1 endpoints, keyed by their Config tag.
+// See: https://shelly-api-docs.shelly.cloud/gen1/
+var endpoints = map[string]*device.Schema{
+	"mode": device.StringSchema("relay", "roller", "color", "white").Describe("Device mode, set before any other endpoint"),
+	"settings": endpoint(map[string]*device.Schema{
+		"name":                        device.StringSchema().Nullable(),
+		"mode":                        device.StringSchema("relay", "roller", "color", "white"),
+		"max_power":                   device.IntegerSchema().AtLeast(0),
+		"eco_mode_enabled":            device.BooleanSchema(),
+		"led_status_disable":          device.BooleanSchema(),
+		"led_power_disable":           device.BooleanSchema(),
+		"discoverable":                device.BooleanSchema(),
+		"debug_enable":                device.BooleanSchema(),
+		"allow_cross_origin":          device.BooleanSchema(),
+		"wifirecovery_reboot_enabled": device.BooleanSchema(),
+		"ap_roaming_enabled":          device.BooleanSchema(),
+		"ap_roaming_threshold":        device.IntegerSchema().Between(-127, 0),
+		"timezone":                    device.StringSchema(),
+		"tzautodetect":                device.BooleanSchema(),
+		"tz_utc_offset":               device.IntegerSchema(),
+		"tz_dst":                      device.BooleanSchema(),
+		"tz_dst_auto":                 device.BooleanSchema(),
+		"lat":                         device.NumberSchema().Between(-90, 90),
+		"lng":                         device.NumberSchema().Between(-180, 180),
+		"sntp_server":                 device.StringSchema(),
+		"coiot_enable":                device.BooleanSchema(),
+		"coiot_update_period":         device.IntegerSchema().AtLeast(0),
+		"coiot_peer":                  device.StringSchema(),
+		"mqtt_enable":                 device.BooleanSchema(),
+		"mqtt_server":                 device.StringSchema(),
+		"mqtt_clean_session":          device.BooleanSchema(),
+		"mqtt_retain":                 device.BooleanSchema(),
+		"mqtt_user":                   device.StringSchema(),
+		"mqtt_pass":                   device.StringSchema(),
+		"mqtt_id":                     device.StringSchema(),
+		"mqtt_reconnect_timeout_max":  device.NumberSchema().AtLeast(0),
+		"mqtt_reconnect_timeout_min":  device.NumberSchema().AtLeast(0),
+		"mqtt_keep_alive":             device.IntegerSchema().AtLeast(0),
+		"mqtt_update_period":          device.IntegerSchema().AtLeast(0),
+		"mqtt_max_qos":                device.IntegerSchema().Between(0, 2),
+	}),
+	"settings_ap": endpoint(map[string]*device.Schema{
+		"enabled": device.BooleanSchema(),
+		"ssid":    device.StringSchema(),
+		"key":     device.StringSchema(),
+	}),
+	"settings_sta":  station(),
+	"settings_sta1": station(),
+	"settings_cloud": endpoint(map[string]*device.Schema{
+		"enabled": device.BooleanSchema(),
+	}),
+	"settings_actions": indexed(map[string]*device.Schema{
+		"index":   device.IntegerSchema().AtLeast(0),
+		"name":    device.StringSchema(),
+		"enabled": device.BooleanSchema(),
+		"urls":    device.ArraySchema(device.StringSchema()),
+	}),
+	"settings_relay": output(map[string]*device.Schema{
+		"appliance_type": device.StringSchema(),
+		"max_power":      device.IntegerSchema().AtLeast(0),
+	}),
+	"settings_power": indexed(map[string]*device.Schema{
+		"power": device.NumberSchema().AtLeast(0),
+	}),
+	"settings_ext_temperature": indexed(map[string]*device.Schema{
+		"overtemp_threshold_tC":  device.NumberSchema(),
+		"overtemp_threshold_tF":  device.NumberSchema(),
+		"undertemp_threshold_tC": device.NumberSchema(),
+		"undertemp_threshold_tF": device.NumberSchema(),
+		"overtemp_act":           threshold(),
+		"undertemp_act":          threshold(),
+		"offset_tC":              device.NumberSchema(),
+		"offset_tF":              device.NumberSchema(),
+	}),
+	"settings_ext_humidity": indexed(map[string]*device.Schema{
+		"overhum_threshold":  device.NumberSchema().Between(0, 100),
+		"underhum_threshold": device.NumberSchema().Between(0, 100),
+		"overhum_act":        threshold(),
+		"underhum_act":       threshold(),
+		"offset":             device.NumberSchema(),
+	}),
+	"settings_ext_switch": indexed(map[string]*device.Schema{
+		"relay_num": device.IntegerSchema().AtLeast(-1),
+		"reverse":   device.BooleanSchema(),
+	}),
+	"settings_roller": indexed(map[string]*device.Schema{
+		"maxtime":                   device.NumberSchema().AtLeast(0),
+		"maxtime_open":              device.NumberSchema().AtLeast(0),
+		"maxtime_close":             device.NumberSchema().AtLeast(0),
+		"default_state":             device.StringSchema("stop", "open", "close", "switch"),
+		"swap":                      device.BooleanSchema(),
+		"swap_inputs":               device.BooleanSchema(),
+		"input_mode":                device.StringSchema("openclose", "onebutton"),
+		"button_type":               device.StringSchema("momentary", "toggle", "detached", "action"),
+		"btn_reverse":               device.BooleanSchema(),
+		"obstacle_mode":             device.StringSchema("disabled", "while_opening", "while_closing", "while_moving"),
+		"obstacle_action":           device.StringSchema("stop", "reverse"),
+		"obstacle_power":            device.IntegerSchema().AtLeast(0),
+		"obstacle_delay":            device.IntegerSchema().AtLeast(0),
+		"safety_mode":               device.StringSchema("disabled", "while_opening", "while_closing", "while_moving"),
+		"safety_action":             device.StringSchema("stop", "pause", "reverse"),
+		"safety_allowed_on_trigger": device.StringSchema("none", "open", "close", "all"),
+		"off_power":                 device.IntegerSchema().AtLeast(0),
+		"positioning":               device.BooleanSchema(),
+	}),
+	"settings_light": output(map[string]*device.Schema{
+		"mode":       device.StringSchema("color", "white"),
+		"brightness": percentage(),
+		"red":        channel(),
+		"green":      channel(),
+		"blue":       channel(),
+		"white":      channel(),
+		"gain":       percentage(),
+		"temp":       device.IntegerSchema().AtLeast(0),
+		"effect":     device.IntegerSchema().AtLeast(0),
+		"transition": transition(),
+		"night_mode": device.ObjectSchema(nil),
+	}),
+	"settings_color": output(map[string]*device.Schema{
+		"red":        channel(),
+		"green":      channel(),
+		"blue":       channel(),
+		"white":      channel(),
+		"gain":       percentage(),
+		"effect":     device.IntegerSchema().AtLeast(0),
+		"transition": transition(),
+	}),
+	"settings_white": output(map[string]*device.Schema{
+		"brightness": percentage(),
+		"transition": transition(),
+	}),
+}
+
+// Schema returns the configuration Schema of the Shelly Gen1 driver.
+func Schema() *device.Schema {
+	return device.ConfigSchema(endpoints)
+}
@@ -0,0 +1,75 @@
+package shellygen1
+
+import (
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+func TestSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		issues int
+	}{
+		{
+			name: "valid configuration",
+			data: `{
+				"meta": {"device": "Shelly 1"},
+				"policy": {"mode": "blacklist", "devices": ["AA:BB:CC:DD:EE:FF"]},
+				"settings": {"mqtt_enable": true, "mqtt_server": "192.168.1.254:1883", "mqtt_max_qos": 2},
+				"settings_relay": [{"name": null, "default_state": "off", "btn_type": "detached", "schedule_rules": ["0800-012345-on"]}],
+				"settings_sta": {"enabled": true, "ssid": "WIFI", "key": "${WIFI_KEY}", "ipv4_method": "dhcp"}
+			}`,
+		},
+		{
+			name: "valid roller configuration",
+			data: `{
+				"mode": "roller",
+				"settings_roller": [{"maxtime_open": 20, "input_mode": "openclose", "obstacle_mode": "while_moving", "positioning": true}]
+			}`,
+		},
+		{
+			name: "valid light configuration",
+			data: `{
+				"mode": "color",
+				"settings_light": [{"name": "Lamp", "default_state": "last", "brightness": 80, "transition": 500}],
+				"settings_color": [{"red": 255, "green": 128, "blue": 0, "gain": 100}]
+			}`,
+		},
+		{
+			name:   "invalid light values",
+			data:   `{"mode": "dimmer", "settings_white": [{"brightness": 120}], "settings_color": [{"red": 256}]}`,
+			issues: 3,
+		},
+		{
+			name:   "unknown endpoint",
+			data:   `{"settings_foo": {}}`,
+			issues: 1,
+		},
+		{
+			name:   "unknown endpoint setting",
+			data:   `{"settings": {"mqtt_enabled": true}}`,
+			issues: 1,
+		},
+		{
+			name:   "invalid values",
+			data:   `{"settings": {"mqtt_max_qos": 3, "mqtt_enable": "true"}, "settings_relay": [{"btn_type": "switch"}]}`,
+			issues: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := device.ParseNode(device.FormatJSON, []byte(test.data))
+			if err != nil {
+				t.Fatalf("unable to parse data: %v", err)
+			}
+
+			errs := Schema().Validate(n)
+			if len(errs) != test.issues {
+				t.Fatalf("expected %d issues, got %v", test.issues, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package shellygen1
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/quetzyg/IoTap/device"
+	"github.com/quetzyg/IoTap/httpclient"
+)
+
+// writeOnlyKeys holds the setting keys whose values aren't returned by the settings endpoints
+// (e.g. Wi-Fi keys and MQTT passwords).
+// See: https://shelly-api-docs.shelly.cloud/gen1/#settings-sta
+var writeOnlyKeys = []string{"key", "mqtt_pass"}
+
+// writeOnly checks if endpoint settings hold write-only values (see device.HoldsKey).
+func writeOnly(params any) bool {
+	switch params := params.(type) {
+	case *settings:
+		return device.HoldsKey(*params, writeOnlyKeys...)
+
+	case *[]*settings:
+		for _, p := range *params {
+			if device.HoldsKey(*p, writeOnlyKeys...) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// snapshot captures the current settings of a single device endpoint.
+// Only the settings present in the params are kept, so that a rollback restores exactly what was changed.
+func (d *Device) snapshot(client *http.Client, path string, params *settings) (*settings, error) {
+	r, err := request(d, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	current := map[string]any{}
+
+	dispatcher := httpclient.NewDispatcher(client)
+
+	if err = dispatcher.Dispatch(r, httpclient.WithBinding(&current)); err != nil {
+		return nil, err
+	}
+
+	snap := settings(device.Mask(current, *params))
+
+	return &snap, nil
+}
+
+// Snapshot captures the current settings of the device endpoints affected by a Config.
+// The resulting Config can be used to restore the device to its previous state.
+// Endpoints set with write-only values (e.g. Wi-Fi keys) are left out, so they're never rolled back.
+func (d *Device) Snapshot(client *http.Client, config device.Config) (device.Config, error) {
+	conf, ok := config.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected %q, got %q", device.ErrDriverMismatch, d.Driver(), config.Driver())
+	}
+
+	snap := &Config{}
+
+	confVal := reflect.Indirect(reflect.ValueOf(conf))
+	snapVal := reflect.Indirect(reflect.ValueOf(snap))
+
+	for i := range confVal.Type().NumField() {
+		setting := confVal.Field(i)
+
+		// Skip nil setting pointers, along with the settings that can't be captured
+		if setting.IsNil() || writeOnly(setting.Interface()) {
+			continue
+		}
+
+		// Current setting tag
+		tag := strings.TrimSuffix(confVal.Type().Field(i).Tag.Get("json"), ",omitempty")
+		path := paths[tag]
+
+		switch params := setting.Interface().(type) {
+		case *string:
+			s, err := d.snapshot(client, path, &settings{tag: *params})
+			if err != nil {
+				return nil, err
+			}
+
+			if value, ok := (*s)[tag].(string); ok {
+				snapVal.Field(i).Set(reflect.ValueOf(&value))
+			}
+
+		case *settings:
+			s, err := d.snapshot(client, path, params)
+			if err != nil {
+				return nil, err
+			}
+
+			snapVal.Field(i).Set(reflect.ValueOf(s))
+
+		case *[]*settings:
+			// Entries are kept in order, since their position determines the endpoint index
+			var list []*settings
+
+			for j, p := range *params {
+				indexed := path
+
+				// Handle paths that require an index
+				if strings.Contains(path, "%d") {
+					indexed = fmt.Sprintf(path, j)
+				}
+
+				s, err := d.snapshot(client, indexed, p)
+				if err != nil {
+					return nil, err
+				}
+
+				list = append(list, s)
+			}
+
+			snapVal.Field(i).Set(reflect.ValueOf(&list))
+		}
+	}
+
+	return snap, nil
+}
@@ -0,0 +1,154 @@
+package shellygen1
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+// roundTripper is a custom type used for mocking HTTP responses.
+type roundTripper struct {
+	response *http.Response
+	err      error
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (rt *roundTripper) RoundTrip(_ *http.Request) (*http.Response, error) {
+	return rt.response, rt.err
+}
+
+func TestDevice_Snapshot(t *testing.T) {
+	tests := []struct {
+		rt   http.RoundTripper
+		cfg  device.Config
+		snap device.Config
+		err  error
+		name string
+	}{
+		{
+			name: "failure: driver mismatch",
+			cfg:  &config{},
+			err:  device.ErrDriverMismatch,
+		},
+		{
+			name: "failure: dispatch failed",
+			cfg: &Config{
+				Settings: &settings{
+					"discoverable": true,
+				},
+			},
+			rt: &roundTripper{
+				err: net.ErrClosed,
+			},
+			err: net.ErrClosed,
+		},
+		{
+			name: "success: single settings",
+			cfg: &Config{
+				Settings: &settings{
+					"discoverable": true,
+				},
+			},
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"discoverable":false,"name":"kitchen"}`)),
+				},
+			},
+			snap: &Config{
+				Settings: &settings{
+					"discoverable": false,
+				},
+			},
+		},
+		{
+			name: "success: write-only settings left out",
+			cfg: &Config{
+				Settings: &settings{
+					"discoverable": true,
+				},
+				SettingsSTA: &settings{
+					"ssid": "home",
+					"key":  "secret",
+				},
+			},
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"discoverable":false,"name":"kitchen"}`)),
+				},
+			},
+			snap: &Config{
+				Settings: &settings{
+					"discoverable": false,
+				},
+			},
+		},
+		{
+			name: "success: settings slice",
+			cfg: &Config{
+				SettingsRelay: &[]*settings{
+					{
+						"auto_off": 3,
+					},
+				},
+			},
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"auto_off":0,"auto_on":0,"name":null}`)),
+				},
+			},
+			snap: &Config{
+				SettingsRelay: &[]*settings{
+					{
+						"auto_off": float64(0),
+					},
+				},
+			},
+		},
+		{
+			name: "success: device mode",
+			cfg: &Config{
+				Mode: func() *string {
+					mode := "roller"
+					return &mode
+				}(),
+			},
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"mode":"relay","name":"kitchen"}`)),
+				},
+			},
+			snap: &Config{
+				Mode: func() *string {
+					mode := "relay"
+					return &mode
+				}(),
+			},
+		},
+	}
+
+	shelly1 := &Device{ip: net.ParseIP("192.168.146.123")}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			snap, err := shelly1.Snapshot(&http.Client{Transport: test.rt}, test.cfg)
+
+			if !reflect.DeepEqual(snap, test.snap) {
+				t.Fatalf("expected %#v, got %#v", test.snap, snap)
+			}
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package shellygen1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/quetzyg/IoTap/device"
+	"github.com/quetzyg/IoTap/httpclient"
+)
+
+// relayStatus holds the relay state returned by the relay endpoint.
+type relayStatus struct {
+	IsOn bool `json:"ison"`
+}
+
+// Switch turns a relay on, off or toggles it, returning its resulting state.
+// When set, the timer flips the relay back once it elapses.
+// See: https://shelly-api-docs.shelly.cloud/gen1/#shelly1-shelly1pm-relay-index
+func (d *Device) Switch(client *http.Client, channel int, action string, timer time.Duration) (bool, error) {
+	values := url.Values{
+		"turn": {action},
+	}
+
+	if timer > 0 {
+		values.Set("timer", strconv.FormatFloat(timer.Seconds(), 'f', -1, 64))
+	}
+
+	r, err := request(d, fmt.Sprintf("relay/%d", channel), values)
+	if err != nil {
+		return false, err
+	}
+
+	status := &relayStatus{}
+
+	dispatcher := httpclient.NewDispatcher(client)
+
+	// Devices without the relay respond with a plain text 404 body
+	err = dispatcher.Dispatch(r, httpclient.WithBinding(status))
+	if errors.Is(err, httpclient.ErrNotFound) {
+		return false, fmt.Errorf("%w: relay %d", device.ErrChannelNotFound, channel)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return status.IsOn, nil
+}
@@ -0,0 +1,95 @@
+package shellygen1
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+func TestDevice_Switch(t *testing.T) {
+	tests := []struct {
+		dev     *Device
+		err     error
+		name    string
+		action  string
+		body    string
+		url     string
+		timer   time.Duration
+		channel int
+		status  int
+		on      bool
+	}{
+		{
+			name:   "failure: missing credentials",
+			dev:    &Device{ip: net.ParseIP("192.168.1.10"), secured: true},
+			action: device.ActionOn,
+			err:    device.ErrMissingCredentials,
+		},
+		{
+			name:    "failure: relay not found",
+			dev:     &Device{ip: net.ParseIP("192.168.1.10")},
+			action:  device.ActionOn,
+			channel: 3,
+			status:  http.StatusNotFound,
+			body:    "Not Found",
+			url:     "http://192.168.1.10/relay/3?turn=on",
+			err:     device.ErrChannelNotFound,
+		},
+		{
+			name:   "success: on",
+			dev:    &Device{ip: net.ParseIP("192.168.1.10")},
+			action: device.ActionOn,
+			body:   `{"ison":true,"has_timer":false}`,
+			url:    "http://192.168.1.10/relay/0?turn=on",
+			on:     true,
+		},
+		{
+			name:    "success: on with timer",
+			dev:     &Device{ip: net.ParseIP("192.168.1.10")},
+			action:  device.ActionOn,
+			channel: 1,
+			timer:   90 * time.Second,
+			body:    `{"ison":true,"has_timer":true}`,
+			url:     "http://192.168.1.10/relay/1?timer=90&turn=on",
+			on:      true,
+		},
+		{
+			name:   "success: off",
+			dev:    &Device{ip: net.ParseIP("192.168.1.10")},
+			action: device.ActionOff,
+			body:   `{"ison":false,"has_timer":false}`,
+			url:    "http://192.168.1.10/relay/0?turn=off",
+		},
+		{
+			name:   "success: toggle",
+			dev:    &Device{ip: net.ParseIP("192.168.1.10")},
+			action: device.ActionToggle,
+			body:   `{"ison":true,"has_timer":false}`,
+			url:    "http://192.168.1.10/relay/0?turn=toggle",
+			on:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := &recorder{body: test.body, status: test.status}
+
+			on, err := test.dev.Switch(&http.Client{Transport: rec}, test.channel, test.action, test.timer)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if on != test.on {
+				t.Fatalf("expected %t, got %t", test.on, on)
+			}
+
+			if urls := requestURLs(rec.requests); test.url != "" && (len(urls) != 1 || urls[0] != test.url) {
+				t.Fatalf("expected %q, got %q", test.url, urls)
+			}
+		})
+	}
+}
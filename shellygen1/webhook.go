@@ -0,0 +1,156 @@
+package shellygen1
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/quetzyg/IoTap/device"
+	"github.com/quetzyg/IoTap/httpclient"
+)
+
+// action holds the URLs called when a device event occurs, for a single channel.
+type action struct {
+	Index   int      `json:"index"`
+	Enabled bool     `json:"enabled"`
+	URLs    []string `json:"urls"`
+}
+
+// installed returns the display friendly representation of an action.
+func (a *action) installed(event string) *device.InstalledHook {
+	return &device.InstalledHook{
+		ID:      fmt.Sprintf("%s/%d", event, a.Index),
+		Event:   event,
+		Channel: a.Index,
+		Enabled: a.Enabled,
+		URLs:    a.URLs,
+	}
+}
+
+// actionsResponse holds the result of a settings actions endpoint request, keyed by event.
+type actionsResponse struct {
+	Actions map[string][]*action `json:"actions"`
+}
+
+// fetchActions returns the actions of the device, keyed by event.
+// See: https://shelly-api-docs.shelly.cloud/gen1/#settings-actions
+func (d *Device) fetchActions(client *http.Client) (map[string][]*action, error) {
+	r, err := request(d, paths["settings_actions"], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &actionsResponse{}
+
+	dispatcher := httpclient.NewDispatcher(client)
+
+	if err = dispatcher.Dispatch(r, httpclient.WithBinding(resp)); err != nil {
+		return nil, err
+	}
+
+	return resp.Actions, nil
+}
+
+// actionRequest creates a settings actions request, for a single event channel.
+func (d *Device) actionRequest(event string, index int, enabled bool, urls []string) (*http.Request, error) {
+	values := url.Values{
+		"index":   {strconv.Itoa(index)},
+		"name":    {event},
+		"enabled": {strconv.FormatBool(enabled)},
+		"urls[]":  urls,
+	}
+
+	// Send an empty value to clear the URLs
+	if len(urls) == 0 {
+		values.Set("urls[]", "")
+	}
+
+	return request(d, paths["settings_actions"], values)
+}
+
+// InstalledHooks returns the actions of the device that have URLs set.
+func (d *Device) InstalledHooks(client *http.Client) ([]*device.InstalledHook, error) {
+	actions, err := d.fetchActions(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var hooks []*device.InstalledHook
+
+	for _, event := range slices.Sorted(maps.Keys(actions)) {
+		for _, a := range actions[event] {
+			if len(a.URLs) > 0 {
+				hooks = append(hooks, a.installed(event))
+			}
+		}
+	}
+
+	return hooks, nil
+}
+
+// HookRequests creates an ordered slice of *http.Request objects for syncing webhooks into device actions.
+// Each event channel holds a single action, which is updated when its URLs or state differ.
+// Hooks for events the device doesn't support are skipped, and hook names are ignored.
+// Actions with URLs that aren't declared are returned, and cleared when pruning.
+func (d *Device) HookRequests(client *http.Client, hooks []*device.Hook, prune bool) ([]*http.Request, []*device.InstalledHook, error) {
+	actions, err := d.fetchActions(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var requests []*http.Request
+
+	declared := make(map[string]bool, len(hooks))
+
+	for _, h := range hooks {
+		for _, a := range actions[h.Event] {
+			if a.Index != h.Channel {
+				continue
+			}
+
+			declared[fmt.Sprintf("%s/%d", h.Event, a.Index)] = true
+
+			if a.Enabled == h.Enabled() && slices.Equal(a.URLs, h.URLs) {
+				break
+			}
+
+			r, err := d.actionRequest(h.Event, a.Index, h.Enabled(), h.URLs)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			requests = append(requests, r)
+			break
+		}
+	}
+
+	var extra []*device.InstalledHook
+
+	for _, event := range slices.Sorted(maps.Keys(actions)) {
+		for _, a := range actions[event] {
+			hook := a.installed(event)
+
+			if len(a.URLs) == 0 || declared[hook.ID] {
+				continue
+			}
+
+			extra = append(extra, hook)
+
+			if !prune {
+				continue
+			}
+
+			r, err := d.actionRequest(event, a.Index, false, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			requests = append(requests, r)
+		}
+	}
+
+	return requests, extra, nil
+}
@@ -0,0 +1,120 @@
+package shellygen1
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+const actionSettings = `{"actions":{` +
+	`"out_on_url":[{"index":0,"enabled":true,"urls":["http://example.com/on"]},{"index":1,"enabled":false,"urls":[]}],` +
+	`"out_off_url":[{"index":0,"enabled":true,"urls":["http://example.com/off"]}],` +
+	`"btn_on_url":[{"index":0,"enabled":false,"urls":[]}]` +
+	`}}`
+
+// actionSettingsResponse returns a mocked settings actions endpoint response.
+func actionSettingsResponse() *roundTripper {
+	return &roundTripper{
+		response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(actionSettings)),
+		},
+	}
+}
+
+func TestDevice_InstalledHooks(t *testing.T) {
+	shelly1 := &Device{ip: net.ParseIP("192.168.146.123")}
+
+	hooks, err := shelly1.InstalledHooks(&http.Client{Transport: actionSettingsResponse()})
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := []*device.InstalledHook{
+		{ID: "out_off_url/0", Event: "out_off_url", Enabled: true, URLs: []string{"http://example.com/off"}},
+		{ID: "out_on_url/0", Event: "out_on_url", Enabled: true, URLs: []string{"http://example.com/on"}},
+	}
+
+	if !reflect.DeepEqual(hooks, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, hooks)
+	}
+}
+
+func TestDevice_HookRequests(t *testing.T) {
+	tests := []struct {
+		rt    http.RoundTripper
+		hooks []*device.Hook
+		err   error
+		name  string
+		urls  []string
+		extra int
+		prune bool
+	}{
+		{
+			name: "failure: dispatch failed",
+			rt: &roundTripper{
+				err: net.ErrClosed,
+			},
+			err: net.ErrClosed,
+		},
+		{
+			name: "success: actions in sync",
+			rt:   actionSettingsResponse(),
+			hooks: []*device.Hook{
+				{Event: "out_on_url", URLs: []string{"http://example.com/on"}},
+				{Event: "switch.on", URLs: []string{"http://example.com/gen2"}},
+			},
+			extra: 1,
+		},
+		{
+			name: "success: actions updated",
+			rt:   actionSettingsResponse(),
+			hooks: []*device.Hook{
+				{Event: "out_on_url", URLs: []string{"http://example.com/on"}},
+				{Event: "out_on_url", Channel: 1, URLs: []string{"http://example.com/on?relay=1"}},
+				{Event: "out_off_url", Enable: new(bool), URLs: []string{"http://example.com/off"}},
+			},
+			urls: []string{
+				"http://192.168.146.123/settings/actions?enabled=true&index=1&name=out_on_url&urls%5B%5D=http%3A%2F%2Fexample.com%2Fon%3Frelay%3D1",
+				"http://192.168.146.123/settings/actions?enabled=false&index=0&name=out_off_url&urls%5B%5D=http%3A%2F%2Fexample.com%2Foff",
+			},
+		},
+		{
+			name: "success: actions pruned",
+			rt:   actionSettingsResponse(),
+			hooks: []*device.Hook{
+				{Event: "out_on_url", URLs: []string{"http://example.com/on"}},
+			},
+			prune: true,
+			urls: []string{
+				"http://192.168.146.123/settings/actions?enabled=false&index=0&name=out_off_url&urls%5B%5D=",
+			},
+			extra: 1,
+		},
+	}
+
+	shelly1 := &Device{ip: net.ParseIP("192.168.146.123")}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rs, extra, err := shelly1.HookRequests(&http.Client{Transport: test.rt}, test.hooks, test.prune)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if urls := requestURLs(rs); !reflect.DeepEqual(urls, test.urls) {
+				t.Fatalf("expected %q, got %q", test.urls, urls)
+			}
+
+			if len(extra) != test.extra {
+				t.Fatalf("expected %d extra hooks, got %d", test.extra, len(extra))
+			}
+		})
+	}
+}
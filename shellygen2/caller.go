@@ -0,0 +1,55 @@
+package shellygen2
+
+import (
+	"encoding/json/jsontext"
+	"fmt"
+	"net/http"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+// callResponse holds the outcome of an arbitrary RPC method request.
+type callResponse struct {
+	Result jsontext.Value `json:"result"`
+}
+
+// Call an arbitrary RPC method on the device (e.g. Switch.GetStatus, Script.Eval), returning its result.
+// Method errors reported by the device are returned as errors.
+func (d *Device) Call(client *http.Client, method string, params map[string]any) ([]byte, error) {
+	var p any
+	if len(params) > 0 {
+		p = params
+	}
+
+	resp := &callResponse{}
+
+	if err := d.call(client, method, p, resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Result) == 0 {
+		return []byte("null"), nil
+	}
+
+	if err := resp.Result.Compact(); err != nil {
+		return nil, err
+	}
+
+	return resp.Result, nil
+}
+
+// documentMethods maps document kinds to the RPC methods returning them.
+var documentMethods = map[string]string{
+	device.DocumentStatus: "Shelly.GetStatus",
+	device.DocumentConfig: "Shelly.GetConfig",
+}
+
+// Document returns the status or configuration document of the device.
+func (d *Device) Document(client *http.Client, kind string) ([]byte, error) {
+	method, ok := documentMethods[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", device.ErrUnsupportedDocument, kind)
+	}
+
+	return d.Call(client, method, nil)
+}
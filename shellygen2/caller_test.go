@@ -0,0 +1,149 @@
+package shellygen2
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+// recorder is an http.RoundTripper that keeps the requests it gets, replying with the same body.
+type recorder struct {
+	requests []*http.Request
+	body     string
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (rec *recorder) RoundTrip(r *http.Request) (*http.Response, error) {
+	rec.requests = append(rec.requests, r)
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(rec.body)),
+	}, nil
+}
+
+func TestDevice_Call(t *testing.T) {
+	tests := []struct {
+		params   map[string]any
+		err      string
+		name     string
+		body     string
+		request  string
+		expected string
+	}{
+		{
+			name:    "failure: rpc error",
+			body:    `{"id":0,"src":"shellypro1","error":{"code":-103,"message":"Invalid argument 'id'"}}`,
+			params:  map[string]any{"id": float64(9)},
+			request: `{"id":0,"method":"Switch.GetStatus","params":{"id":9},"src":"IoTap"}`,
+			err:     "rpc error -103: Invalid argument 'id'",
+		},
+		{
+			name:     "success: without params",
+			body:     `{"id":0,"src":"shellypro1","result":{"id":0, "output":true}}`,
+			request:  `{"id":0,"method":"Switch.GetStatus","src":"IoTap"}`,
+			expected: `{"id":0,"output":true}`,
+		},
+		{
+			name:     "success: with params",
+			body:     `{"id":0,"src":"shellypro1","result":{"result":"pong"}}`,
+			params:   map[string]any{"id": float64(1), "code": "ping()"},
+			request:  `{"id":0,"method":"Switch.GetStatus","params":{"code":"ping()","id":1},"src":"IoTap"}`,
+			expected: `{"result":"pong"}`,
+		},
+		{
+			name:     "success: null result",
+			body:     `{"id":0,"src":"shellypro1"}`,
+			request:  `{"id":0,"method":"Switch.GetStatus","src":"IoTap"}`,
+			expected: `null`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := &recorder{body: test.body}
+
+			dev := &Device{ip: net.ParseIP("192.168.1.10")}
+
+			resp, err := dev.Call(&http.Client{Transport: rec}, "Switch.GetStatus", test.params)
+
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Fatalf("expected %q, got %v", test.err, err)
+				}
+			} else if err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			if string(resp) != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, resp)
+			}
+
+			if bodies := rpcBodies(t, rec.requests); !reflect.DeepEqual(bodies, []string{test.request}) {
+				t.Fatalf("expected %q, got %q", test.request, bodies)
+			}
+
+			if u := rec.requests[0].URL; !reflect.DeepEqual(u, &url.URL{Scheme: "http", Host: "192.168.1.10", Path: "/rpc"}) {
+				t.Fatalf("unexpected URL: %s", u)
+			}
+		})
+	}
+}
+
+func TestDevice_Document(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		kind   string
+		method string
+	}{
+		{
+			name: "failure: unsupported document",
+			kind: "foo",
+			err:  device.ErrUnsupportedDocument,
+		},
+		{
+			name:   "success: status",
+			kind:   device.DocumentStatus,
+			method: "Shelly.GetStatus",
+		},
+		{
+			name:   "success: config",
+			kind:   device.DocumentConfig,
+			method: "Shelly.GetConfig",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := &recorder{body: `{"id":0,"result":{"cloud":{"enable":true}}}`}
+
+			dev := &Device{ip: net.ParseIP("192.168.1.10")}
+
+			doc, err := dev.Document(&http.Client{Transport: rec}, test.kind)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if string(doc) != `{"cloud":{"enable":true}}` {
+				t.Fatalf("unexpected document: %s", doc)
+			}
+
+			expected := []string{`{"id":0,"method":"` + test.method + `","src":"IoTap"}`}
+			if bodies := rpcBodies(t, rec.requests); !reflect.DeepEqual(bodies, expected) {
+				t.Fatalf("expected %q, got %q", expected, bodies)
+			}
+		})
+	}
+}
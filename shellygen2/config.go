@@ -7,17 +7,32 @@ import "github.com/quetzyg/IoTap/device"
 type settings map[string]any
 
 // Config implementation for the Shelly Gen2 driver.
+// The device profile is switched before any component is configured (see Device.ProfileRequest).
 type Config struct {
-	Policy *device.Policy `json:"policy,omitempty"`
-	BLE    *settings      `json:"ble,omitempty"`
-	Cloud  *settings      `json:"cloud,omitempty"`
-	Eth    *settings      `json:"eth,omitempty"`
-	Input  *[]*settings   `json:"input,omitempty"`
-	MQTT   *settings      `json:"mqtt,omitempty"`
-	Switch *[]*settings   `json:"switch,omitempty"`
-	Sys    *settings      `json:"sys,omitempty"`
-	WiFi   *settings      `json:"wifi,omitempty"`
-	WS     *settings      `json:"ws,omitempty"`
+	Policy      *device.Policy      `json:"policy,omitempty"`
+	Profile     *string             `json:"profile,omitempty"`
+	BLE         *settings           `json:"ble,omitempty"`
+	BTHome      *settings           `json:"bthome,omitempty"`
+	Cloud       *settings           `json:"cloud,omitempty"`
+	Cover       *[]*settings        `json:"cover,omitempty"`
+	EM          *[]*settings        `json:"em,omitempty"`
+	EM1         *[]*settings        `json:"em1,omitempty"`
+	Eth         *settings           `json:"eth,omitempty"`
+	Humidity    *[]*settings        `json:"humidity,omitempty"`
+	Input       *[]*settings        `json:"input,omitempty"`
+	Light       *[]*settings        `json:"light,omitempty"`
+	Matter      *settings           `json:"matter,omitempty"`
+	MQTT        *settings           `json:"mqtt,omitempty"`
+	PM1         *[]*settings        `json:"pm1,omitempty"`
+	Smoke       *[]*settings        `json:"smoke,omitempty"`
+	Switch      *[]*settings        `json:"switch,omitempty"`
+	Sys         *settings           `json:"sys,omitempty"`
+	Temperature *[]*settings        `json:"temperature,omitempty"`
+	UI          *settings           `json:"ui,omitempty"`
+	Voltmeter   *[]*settings        `json:"voltmeter,omitempty"`
+	WiFi        *settings           `json:"wifi,omitempty"`
+	WS          *settings           `json:"ws,omitempty"`
+	Overrides   *[]*device.Override `json:"overrides,omitempty"`
 }
 
 // Driver name of this Config implementation.
@@ -25,6 +40,15 @@ func (c *Config) Driver() string {
 	return Driver
 }
 
+// DeviceOverrides returns the per device configuration overrides.
+func (c *Config) DeviceOverrides() []*device.Override {
+	if c.Overrides == nil {
+		return nil
+	}
+
+	return *c.Overrides
+}
+
 // Empty checks if the struct holding the configuration has a zero value.
 func (c *Config) Empty() bool {
 	return *c == Config{}
@@ -1,6 +1,10 @@
 package shellygen2
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
 
 func TestConfig_Driver(t *testing.T) {
 	driver := (&Config{}).Driver()
@@ -10,6 +14,26 @@ func TestConfig_Driver(t *testing.T) {
 	}
 }
 
+func TestConfig_DeviceOverrides(t *testing.T) {
+	if (&Config{}).DeviceOverrides() != nil {
+		t.Fatal("expected nil overrides")
+	}
+
+	cfg := &Config{
+		Overrides: &[]*device.Override{
+			{
+				Match: &device.Matcher{
+					Names: []string{"^server-room"},
+				},
+			},
+		},
+	}
+
+	if len(cfg.DeviceOverrides()) != 1 {
+		t.Fatalf("expected 1 override, got %d", len(cfg.DeviceOverrides()))
+	}
+}
+
 func TestConfig_Empty(t *testing.T) {
 	tests := []struct {
 		cfg   *Config
@@ -163,6 +163,106 @@ func TestDevice_ConfigureRequests(t *testing.T) {
 				return []*http.Request{r1, r2}
 			}(),
 		},
+		{
+			name: "success: additional components",
+			cfg: &Config{
+				Cover: &[]*settings{
+					{
+						"id": 0,
+						"config": map[string]any{
+							"in_mode":      "dual",
+							"maxtime_open": 30,
+						},
+					},
+				},
+				Humidity: &[]*settings{
+					{
+						"id": 1,
+						"config": map[string]any{
+							"name": "Bathroom",
+						},
+					},
+				},
+				Matter: &settings{
+					"config": map[string]any{
+						"enable": false,
+					},
+				},
+				UI: &settings{
+					"config": map[string]any{
+						"idle_brightness": 30,
+					},
+				},
+			},
+			rs: func() []*http.Request {
+				r1 := &http.Request{
+					Method: http.MethodPost,
+					URL: &url.URL{
+						Scheme: "http",
+						Host:   "192.168.146.123",
+						Path:   rpcPath,
+					},
+					Header: http.Header{},
+					Body:   io.NopCloser(bytes.NewBufferString(`{"params":{"config":{"in_mode":"dual","maxtime_open":30},"id":0},"src":"IoTap","method":"cover.SetConfig","id":0}`)),
+				}
+
+				r1.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
+
+				r2 := &http.Request{
+					Method: http.MethodPost,
+					URL: &url.URL{
+						Scheme: "http",
+						Host:   "192.168.146.123",
+						Path:   rpcPath,
+					},
+					Header: http.Header{},
+					Body:   io.NopCloser(bytes.NewBufferString(`{"params":{"config":{"name":"Bathroom"},"id":1},"src":"IoTap","method":"humidity.SetConfig","id":0}`)),
+				}
+
+				r2.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
+
+				r3 := &http.Request{
+					Method: http.MethodPost,
+					URL: &url.URL{
+						Scheme: "http",
+						Host:   "192.168.146.123",
+						Path:   rpcPath,
+					},
+					Header: http.Header{},
+					Body:   io.NopCloser(bytes.NewBufferString(`{"params":{"config":{"enable":false}},"src":"IoTap","method":"matter.SetConfig","id":0}`)),
+				}
+
+				r3.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
+
+				r4 := &http.Request{
+					Method: http.MethodPost,
+					URL: &url.URL{
+						Scheme: "http",
+						Host:   "192.168.146.123",
+						Path:   rpcPath,
+					},
+					Header: http.Header{},
+					Body:   io.NopCloser(bytes.NewBufferString(`{"params":{"config":{"idle_brightness":30}},"src":"IoTap","method":"ui.SetConfig","id":0}`)),
+				}
+
+				r4.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
+
+				r5 := &http.Request{
+					Method: http.MethodPost,
+					URL: &url.URL{
+						Scheme: "http",
+						Host:   "192.168.146.123",
+						Path:   rpcPath,
+					},
+					Header: http.Header{},
+					Body:   io.NopCloser(bytes.NewBufferString(`{"src":"IoTap","method":"Shelly.Reboot","id":0}`)),
+				}
+
+				r5.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
+
+				return []*http.Request{r1, r2, r3, r4, r5}
+			}(),
+		},
 	}
 
 	shelly2 := &Device{ip: net.ParseIP("192.168.146.123")}
@@ -1,17 +1,38 @@
 package shellygen2
 
 import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"maps"
 	"net/http"
+	"reflect"
+	"time"
+	"unicode/utf8"
 
 	"github.com/quetzyg/IoTap/device"
-	"github.com/quetzyg/IoTap/httpclient"
 )
 
-const chunkSize = 1024
+// Script.PutCode chunk sizes. Uploads start with the largest chunk size, which is halved
+// each time a device fails to store a chunk due to its size, down to the smallest one.
+const (
+	maxChunkSize = 2048
+	minChunkSize = 128
+)
+
+// errCodeResourceExhausted is the RPC error code returned when a device runs out of memory (e.g. large chunks).
+// See: https://shelly-api-docs.shelly.cloud/gen2/General/CommonErrors
+const errCodeResourceExhausted = -108
+
+// errChunkRejected is returned when a device fails to store a script code chunk, due to its size.
+var errChunkRejected = errors.New("script chunk rejected")
 
 // Basic script resource representation.
 type script struct {
-	ID int `json:"id"`
+	Name    string `json:"name"`
+	ID      int    `json:"id"`
+	Enable  bool   `json:"enable"`
+	Running bool   `json:"running"`
 }
 
 // listResponse holds the result of a Script.List method request.
@@ -21,99 +42,299 @@ type listResponse struct {
 	} `json:"result"`
 }
 
+// createResponse holds the result of a Script.Create method request.
+type createResponse struct {
+	Result struct {
+		ID int `json:"id"`
+	} `json:"result"`
+}
+
+// putCodeResponse holds the result of a Script.PutCode method request.
+type putCodeResponse struct {
+	Result struct {
+		Len int `json:"len"`
+	} `json:"result"`
+}
+
 // fetchScripts constructs and returns a slice of *script resources associated with the device.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptlist
 func (d *Device) fetchScripts(client *http.Client) ([]*script, error) {
-	// List all the IoT device scripts
-	// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptlist
-	r, err := request(d, "Script.List", nil)
+	resp := &listResponse{}
+
+	if err := d.fetch(client, "Script.List", resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Result.Scripts, nil
+}
+
+// createScript creates an empty device script, returning the ID assigned by the device.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptcreate
+func (d *Device) createScript(client *http.Client, name string) (int, error) {
+	resp := &createResponse{}
+
+	// Devices out of script slots report an error, instead of an ID
+	var re *rpcError
+
+	err := d.call(client, "Script.Create", map[string]any{"name": name}, resp)
+	if errors.As(err, &re) {
+		return 0, fmt.Errorf("%w: %s: %w", device.ErrScriptUpload, name, err)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Result.ID, nil
+}
+
+// putCode uploads script code in chunks of up to the given size, checking the code length reported by the device
+// after each chunk is stored. Chunks end on a rune boundary, since JSON strings can't hold invalid UTF-8.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptputcode
+func (d *Device) putCode(client *http.Client, id int, code []byte, size int) error {
+	for start, end := 0, 0; start < len(code); start = end {
+		end = min(start+size, len(code))
+
+		for end > start && end < len(code) && !utf8.RuneStart(code[end]) {
+			end--
+		}
+
+		resp := &putCodeResponse{}
+
+		err := d.call(client, "Script.PutCode", map[string]any{
+			"id":     id,
+			"append": start != 0,
+			"code":   string(code[start:end]),
+		}, resp)
+		if isRPCError(err, errCodeResourceExhausted) {
+			return fmt.Errorf("%w: %w", errChunkRejected, err)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if resp.Result.Len != end {
+			return fmt.Errorf("%w: stored %d of %d bytes", errChunkRejected, resp.Result.Len, end)
+		}
+	}
+
+	return nil
+}
+
+// upload stops and disables a device script, before replacing its code and reading it back for verification.
+// Chunks are halved while the device rejects them due to their size, so the upload adapts to the device limits.
+// Scripts are left stopped and disabled when the upload fails, so a partially written script never runs.
+func (d *Device) upload(client *http.Client, current *script, code []byte) error {
+	// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptstop
+	if current.Running {
+		if err := d.call(client, "Script.Stop", map[string]any{"id": current.ID}, nil); err != nil {
+			return err
+		}
+	}
+
+	// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptsetconfig
+	if current.Enable {
+		err := d.call(client, "Script.SetConfig", map[string]any{
+			"id": current.ID,
+			"config": map[string]any{
+				"enable": false,
+			},
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	var err error
+
+	for size := maxChunkSize; size >= minChunkSize; size /= 2 {
+		if err = d.putCode(client, current.ID, code, size); !errors.Is(err, errChunkRejected) {
+			break
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", device.ErrScriptUpload, current.Name, err)
+	}
+
+	stored, err := d.ScriptCode(client, current.ID)
+	if err != nil {
+		return err
+	}
+
+	if sha256.Sum256(stored) != sha256.Sum256(code) {
+		return fmt.Errorf("%w: %s: stored code doesn't match", device.ErrScriptUpload, current.Name)
+	}
+
+	return nil
+}
+
+// startRequests creates an ordered slice of *http.Request objects for configuring a script to run on boot,
+// and starting it, as set in the deployment manifest.
+func (d *Device) startRequests(id int, s *device.Script) ([]*http.Request, error) {
+	// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptsetconfig
+	enable, err := request(d, "Script.SetConfig", map[string]any{
+		"id": id,
+		"config": map[string]any{
+			"enable": s.Enabled(),
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	resp := &listResponse{}
+	if !s.Started() {
+		return []*http.Request{enable}, nil
+	}
+
+	// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptstart
+	start, err := request(d, "Script.Start", map[string]any{"id": id})
+	if err != nil {
+		return nil, err
+	}
 
-	dispatcher := httpclient.NewDispatcher(client)
+	return []*http.Request{enable, start}, nil
+}
+
+// kvsRequests creates a slice of *http.Request objects for setting the script key-value store entries
+// that differ from the device ones.
+func (d *Device) kvsRequests(client *http.Client, scripts []*device.Script) ([]*http.Request, error) {
+	declared := make(map[string]any)
+	for _, s := range scripts {
+		maps.Copy(declared, s.KeyValues())
+	}
 
-	if err = dispatcher.Dispatch(r, httpclient.WithBinding(resp), httpclient.WithChallenger(d)); err != nil {
+	if len(declared) == 0 {
+		return nil, nil
+	}
+
+	current, err := d.KeyValues(client)
+	if err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Scripts, nil
+	changed := make(map[string]any)
+
+	// Entries are compared in full, since setting one replaces its whole value
+	for key, value := range declared {
+		if !reflect.DeepEqual(normalise(value), current[key]) {
+			changed[key] = value
+		}
+	}
+
+	return d.SetKeyValueRequests(changed)
 }
 
-// DeployRequests creates an ordered slice of *http.Request objects for deploying device scripts.
-func (d *Device) DeployRequests(client *http.Client, dep *device.Deployment) ([]*http.Request, error) {
+// Deploy device scripts, returning an ordered slice of *http.Request objects for the remaining steps.
+// Scripts are matched by name, with their code only being uploaded when it differs from the device one,
+// along with a version marker (see device.ScriptMarker). Device scripts without a marker are uploaded again.
+// Missing scripts are created straight away, so their code is uploaded using the ID assigned by the device.
+// Changed scripts are stopped and disabled, and their code is uploaded and verified straight away too,
+// failing the deployment if it doesn't match.
+// The returned requests configure and start the scripts as set in the Deployment, set their key-value store
+// entries and, when pruning is requested, remove the device scripts that aren't in the Deployment.
+// No requests are returned when the device scripts are up to date, in which case no reboot is needed.
+func (d *Device) Deploy(client *http.Client, dep *device.Deployment, prune bool) ([]*http.Request, error) {
 	// Check if a deployment policy is set and enforce it
 	if dep.Policy != nil && dep.Policy.IsExcluded(d) {
 		return nil, device.ErrPolicyExcluded
 	}
 
-	var requests []*http.Request
-
-	// Delete any existing scripts
-	// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptdelete
 	scripts, err := d.fetchScripts(client)
 	if err != nil {
 		return nil, err
 	}
 
+	existing := make(map[string]*script, len(scripts))
 	for _, s := range scripts {
-		r, err := request(d, "Script.Delete", map[string]any{"id": s.ID})
-		if err != nil {
-			return nil, err
-		}
-		requests = append(requests, r)
+		existing[s.Name] = s
 	}
 
-	// Deploy scripts
-	for id, s := range dep.Scripts {
-		// Create script
-		// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptcreate
-		r, err := request(d, "Script.Create", map[string]any{"name": s.Name()})
-		if err != nil {
-			return nil, err
-		}
-		requests = append(requests, r)
-
-		// Upload code in chunks
-		// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptputcode
-		for start := 0; start < s.Length(); start += chunkSize {
-			end := start + chunkSize
-			if end > s.Length() {
-				end = s.Length()
+	var requests []*http.Request
+
+	declared := make(map[string]bool, len(dep.Scripts))
+	deployed := time.Now().UTC().Truncate(time.Second)
+
+	for _, s := range dep.Scripts {
+		declared[s.Name()] = true
+
+		current, ok := existing[s.Name()]
+		if !ok {
+			id, err := d.createScript(client, s.Name())
+			if err != nil {
+				return nil, err
 			}
 
-			r, err = request(d, "Script.PutCode", map[string]any{
-				"id":     id + 1,
-				"append": start != 0,
-				"code":   string(s.Code()[start:end]),
-			})
+			current = &script{ID: id, Name: s.Name()}
+		} else {
+			code, err := d.ScriptCode(client, current.ID)
 			if err != nil {
 				return nil, err
 			}
-			requests = append(requests, r)
+
+			if s.Current(code) {
+				if current.Enable == s.Enabled() && (current.Running || !s.Started()) {
+					continue
+				}
+
+				rs, err := d.startRequests(current.ID, s)
+				if err != nil {
+					return nil, err
+				}
+
+				requests = append(requests, rs...)
+				continue
+			}
 		}
 
-		// Enable script
-		// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptsetconfig
-		r, err = request(d, "Script.SetConfig", map[string]any{
-			"id": id + 1,
-			"config": map[string]any{
-				"enable": true,
-			},
-		})
-		if err != nil {
+		if err = d.upload(client, current, s.Marked(deployed)); err != nil {
 			return nil, err
 		}
-		requests = append(requests, r)
 
-		// Start script
-		// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptstart
-		r, err = request(d, "Script.Start", map[string]any{"id": id + 1})
+		rs, err := d.startRequests(current.ID, s)
 		if err != nil {
 			return nil, err
 		}
-		requests = append(requests, r)
+
+		requests = append(requests, rs...)
+	}
+
+	// Key-value store entries are set before any script is started, so they can be read on startup
+	rs, err := d.kvsRequests(client, dep.Scripts)
+	if err != nil {
+		return nil, err
+	}
+
+	requests = append(rs, requests...)
+
+	if prune {
+		for _, s := range scripts {
+			if declared[s.Name] {
+				continue
+			}
+
+			if s.Running {
+				r, err := request(d, "Script.Stop", map[string]any{"id": s.ID})
+				if err != nil {
+					return nil, err
+				}
+
+				requests = append(requests, r)
+			}
+
+			// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptdelete
+			r, err := request(d, "Script.Delete", map[string]any{"id": s.ID})
+			if err != nil {
+				return nil, err
+			}
+
+			requests = append(requests, r)
+		}
+	}
+
+	if len(requests) == 0 {
+		return nil, nil
 	}
 
 	// Reboot request
@@ -2,17 +2,21 @@ package shellygen2
 
 import (
 	"bytes"
+	"encoding/json/v2"
 	"errors"
 	"io"
+	"maps"
 	"net"
 	"net/http"
-	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/quetzyg/IoTap/device"
-	"github.com/quetzyg/IoTap/httpclient"
 )
 
 // roundTripper is a custom type used for mocking HTTP responses.
@@ -40,6 +44,16 @@ func TestFetchScripts(t *testing.T) {
 			},
 			err: net.ErrClosed,
 		},
+		{
+			name: "failure: rpc error",
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusBadRequest,
+					Body:       io.NopCloser(strings.NewReader(`{"error":{"code":-103,"message":"Invalid argument"}}`)),
+				},
+			},
+			err: &rpcError{Code: -103},
+		},
 		{
 			name: "success: scripts retrieved",
 			rt: &roundTripper{
@@ -75,13 +89,144 @@ func TestFetchScripts(t *testing.T) {
 	}
 }
 
-func TestDevice_DeployRequests(t *testing.T) {
+// scriptHost is a fake device script host, storing the code of the scripts it holds.
+type scriptHost struct {
+	code     map[int]string
+	scripts  []*script
+	kvs      []*kvsItem
+	methods  []string
+	maxChunk int
+	nextID   int
+	corrupt  bool
+	full     bool
+	invalid  bool
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (sh *scriptHost) RoundTrip(r *http.Request) (*http.Response, error) {
+	var rpc struct {
+		Params struct {
+			Code   string `json:"code"`
+			Name   string `json:"name"`
+			ID     int    `json:"id"`
+			Offset int    `json:"offset"`
+			Append bool   `json:"append"`
+		} `json:"params"`
+		Method string `json:"method"`
+	}
+
+	if err := json.UnmarshalRead(r.Body, &rpc); err != nil {
+		return nil, err
+	}
+
+	sh.methods = append(sh.methods, rpc.Method)
+
+	var resp any = map[string]any{"result": nil}
+
+	switch rpc.Method {
+	case "Script.List":
+		resp = map[string]any{"result": map[string]any{"scripts": sh.scripts}}
+
+	case "Script.Create":
+		if sh.full {
+			resp = map[string]any{"error": map[string]any{"code": -108, "message": "Maximum number of scripts reached"}}
+			break
+		}
+
+		sh.code[sh.nextID] = ""
+		resp = map[string]any{"result": map[string]any{"id": sh.nextID}}
+
+	case "Script.GetCode":
+		code := sh.code[rpc.Params.ID][rpc.Params.Offset:]
+		if sh.corrupt {
+			code = strings.ToUpper(code)
+		}
+
+		resp = map[string]any{"result": map[string]any{"data": code, "left": 0}}
+
+	case "Script.PutCode":
+		if sh.invalid {
+			resp = map[string]any{"error": map[string]any{"code": -103, "message": "Invalid argument 'code'"}}
+			break
+		}
+
+		if sh.maxChunk > 0 && len(rpc.Params.Code) > sh.maxChunk {
+			resp = map[string]any{"error": map[string]any{"code": -108, "message": "Resource exhausted"}}
+			break
+		}
+
+		if !rpc.Params.Append {
+			sh.code[rpc.Params.ID] = ""
+		}
+
+		sh.code[rpc.Params.ID] += rpc.Params.Code
+		resp = map[string]any{"result": map[string]any{"len": len(sh.code[rpc.Params.ID])}}
+
+	case "KVS.GetMany":
+		resp = map[string]any{"result": map[string]any{"items": sh.kvs, "total": len(sh.kvs)}}
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewReader(data)),
+	}, nil
+}
+
+func TestDevice_Deploy(t *testing.T) {
+	src, err := device.LoadScript("../testdata/script1.js")
+	if err != nil {
+		t.Fatalf("unable to load script: %v", err)
+	}
+
+	dep := &device.Deployment{
+		Scripts: []*device.Script{src},
+	}
+
+	opts, err := device.NewDeployment(strings.NewReader(`{"scripts":[` +
+		`{"path":"../testdata/script1.js","name":"helper","enable":false,"start":false,"kvs":{"delay":5,"mode":"eco"}}` +
+		`]}`))
+	if err != nil {
+		t.Fatalf("unable to load deployment: %v", err)
+	}
+
+	// Large scripts are uploaded in several chunks
+	fp := filepath.Join(t.TempDir(), "large.js")
+	if err = os.WriteFile(fp, bytes.Repeat([]byte("var foo = \"abc\";\n"), 300), 0o600); err != nil {
+		t.Fatalf("unable to write script: %v", err)
+	}
+
+	large, err := device.LoadScript(fp)
+	if err != nil {
+		t.Fatalf("unable to load script: %v", err)
+	}
+
+	// Multibyte characters straddle the chunk boundaries
+	fp = filepath.Join(t.TempDir(), "unicode.js")
+	if err = os.WriteFile(fp, []byte("//"+strings.Repeat("€", 1000)+"\n"), 0o600); err != nil {
+		t.Fatalf("unable to write script: %v", err)
+	}
+
+	unicode, err := device.LoadScript(fp)
+	if err != nil {
+		t.Fatalf("unable to load script: %v", err)
+	}
+
+	marked := string(src.Marked(time.Now()))
+
 	tests := []struct {
-		rt   http.RoundTripper
-		err  error
-		dep  *device.Deployment
-		name string
-		rs   []*http.Request
+		host    *scriptHost
+		rt      http.RoundTripper
+		err     error
+		dep     *device.Deployment
+		name    string
+		bodies  []string
+		methods []string
+		prune   bool
 	}{
 		{
 			name: "failure: excluded via policy",
@@ -95,131 +240,242 @@ func TestDevice_DeployRequests(t *testing.T) {
 		},
 		{
 			name: "failure: unable to fetch scripts",
-			dep:  &device.Deployment{},
+			dep:  dep,
 			rt: &roundTripper{
 				err: net.ErrClosed,
 			},
 			err: net.ErrClosed,
 		},
 		{
-			name: "failure: unable to fetch scripts",
+			name: "failure: no script slot left",
+			dep:  dep,
+			host: &scriptHost{
+				full: true,
+			},
+			methods: []string{"Script.List", "Script.Create"},
+			err:     device.ErrScriptUpload,
+		},
+		{
+			name: "failure: chunks rejected by the device",
+			dep:  dep,
+			host: &scriptHost{
+				maxChunk: 64,
+				nextID:   1,
+			},
+			methods: []string{
+				"Script.List", "Script.Create",
+				"Script.PutCode", "Script.PutCode", "Script.PutCode", "Script.PutCode", "Script.PutCode",
+			},
+			err: device.ErrScriptUpload,
+		},
+		{
+			name: "failure: chunk error unrelated to its size",
+			dep:  dep,
+			host: &scriptHost{
+				invalid: true,
+				nextID:  1,
+			},
+			methods: []string{"Script.List", "Script.Create", "Script.PutCode"},
+			err:     device.ErrScriptUpload,
+		},
+		{
+			name: "failure: stored code mismatch",
+			dep:  dep,
+			host: &scriptHost{
+				corrupt: true,
+				nextID:  1,
+			},
+			methods: []string{"Script.List", "Script.Create", "Script.PutCode", "Script.GetCode"},
+			err:     device.ErrScriptUpload,
+		},
+		{
+			name: "success: missing script created with the device assigned ID",
+			dep:  dep,
+			host: &scriptHost{
+				scripts: []*script{{ID: 1, Name: "other.js", Enable: true, Running: true}},
+				code:    map[int]string{1: "print(1);"},
+				nextID:  3,
+			},
+			methods: []string{"Script.List", "Script.Create", "Script.PutCode", "Script.GetCode"},
+			bodies: []string{
+				`{"id":0,"method":"Script.SetConfig","params":{"config":{"enable":true},"id":3},"src":"IoTap"}`,
+				`{"id":0,"method":"Script.Start","params":{"id":3},"src":"IoTap"}`,
+				`{"id":0,"method":"Shelly.Reboot","src":"IoTap"}`,
+			},
+		},
+		{
+			name: "success: large script uploaded in adapted chunks",
 			dep: &device.Deployment{
-				Scripts: func() []*device.Script {
-					src, err := device.LoadScript("../testdata/script1.js")
-					if err != nil {
-						t.Fatalf("unable to load script: %v", err)
-					}
-
-					return []*device.Script{
-						src,
-					}
-				}(),
+				Scripts: []*device.Script{large},
 			},
-			rt: &roundTripper{
-				response: &http.Response{
-					StatusCode: http.StatusOK,
-					Body:       io.NopCloser(strings.NewReader(`{"result":{"scripts":[{"id":1}]}}`)),
+			host: &scriptHost{
+				maxChunk: 1024,
+				nextID:   1,
+			},
+			methods: []string{
+				"Script.List", "Script.Create",
+				"Script.PutCode",
+				"Script.PutCode", "Script.PutCode", "Script.PutCode", "Script.PutCode", "Script.PutCode", "Script.PutCode",
+				"Script.GetCode",
+			},
+			bodies: []string{
+				`{"id":0,"method":"Script.SetConfig","params":{"config":{"enable":true},"id":1},"src":"IoTap"}`,
+				`{"id":0,"method":"Script.Start","params":{"id":1},"src":"IoTap"}`,
+				`{"id":0,"method":"Shelly.Reboot","src":"IoTap"}`,
+			},
+		},
+		{
+			name: "success: multibyte script split on rune boundaries",
+			dep: &device.Deployment{
+				Scripts: []*device.Script{unicode},
+			},
+			host: &scriptHost{
+				nextID: 1,
+			},
+			methods: []string{"Script.List", "Script.Create", "Script.PutCode", "Script.PutCode", "Script.GetCode"},
+			bodies: []string{
+				`{"id":0,"method":"Script.SetConfig","params":{"config":{"enable":true},"id":1},"src":"IoTap"}`,
+				`{"id":0,"method":"Script.Start","params":{"id":1},"src":"IoTap"}`,
+				`{"id":0,"method":"Shelly.Reboot","src":"IoTap"}`,
+			},
+		},
+		{
+			name: "success: unchanged script",
+			dep:  dep,
+			host: &scriptHost{
+				scripts: []*script{{ID: 2, Name: "script1.js", Enable: true, Running: true}},
+				code:    map[int]string{2: marked},
+			},
+			methods: []string{"Script.List", "Script.GetCode"},
+		},
+		{
+			name: "success: unchanged script started",
+			dep:  dep,
+			host: &scriptHost{
+				scripts: []*script{{ID: 2, Name: "script1.js"}},
+				code:    map[int]string{2: marked},
+			},
+			methods: []string{"Script.List", "Script.GetCode"},
+			bodies: []string{
+				`{"id":0,"method":"Script.SetConfig","params":{"config":{"enable":true},"id":2},"src":"IoTap"}`,
+				`{"id":0,"method":"Script.Start","params":{"id":2},"src":"IoTap"}`,
+				`{"id":0,"method":"Shelly.Reboot","src":"IoTap"}`,
+			},
+		},
+		{
+			name: "success: unmarked script uploaded with a version marker",
+			dep:  dep,
+			host: &scriptHost{
+				scripts: []*script{{ID: 2, Name: "script1.js"}},
+				code:    map[int]string{2: string(src.Code())},
+			},
+			methods: []string{"Script.List", "Script.GetCode", "Script.PutCode", "Script.GetCode"},
+			bodies: []string{
+				`{"id":0,"method":"Script.SetConfig","params":{"config":{"enable":true},"id":2},"src":"IoTap"}`,
+				`{"id":0,"method":"Script.Start","params":{"id":2},"src":"IoTap"}`,
+				`{"id":0,"method":"Shelly.Reboot","src":"IoTap"}`,
+			},
+		},
+		{
+			name: "success: changed script stopped, disabled and updated in place",
+			dep:  dep,
+			host: &scriptHost{
+				scripts: []*script{{ID: 2, Name: "script1.js", Enable: true, Running: true}},
+				code:    map[int]string{2: `var foo = "xyz";`},
+			},
+			methods: []string{"Script.List", "Script.GetCode", "Script.Stop", "Script.SetConfig", "Script.PutCode", "Script.GetCode"},
+			bodies: []string{
+				`{"id":0,"method":"Script.SetConfig","params":{"config":{"enable":true},"id":2},"src":"IoTap"}`,
+				`{"id":0,"method":"Script.Start","params":{"id":2},"src":"IoTap"}`,
+				`{"id":0,"method":"Shelly.Reboot","src":"IoTap"}`,
+			},
+		},
+		{
+			name: "success: script options applied",
+			dep:  opts,
+			host: &scriptHost{
+				kvs:    []*kvsItem{{Key: "mode", Value: "eco"}},
+				nextID: 1,
+			},
+			methods: []string{"Script.List", "Script.Create", "Script.PutCode", "Script.GetCode", "KVS.GetMany"},
+			bodies: []string{
+				`{"id":0,"method":"KVS.Set","params":{"key":"delay","value":5},"src":"IoTap"}`,
+				`{"id":0,"method":"Script.SetConfig","params":{"config":{"enable":false},"id":1},"src":"IoTap"}`,
+				`{"id":0,"method":"Shelly.Reboot","src":"IoTap"}`,
+			},
+		},
+		{
+			name: "success: unchanged script options",
+			dep:  opts,
+			host: &scriptHost{
+				scripts: []*script{{ID: 1, Name: "helper", Running: true}},
+				code:    map[int]string{1: marked},
+				kvs:     []*kvsItem{{Key: "delay", Value: 5}, {Key: "mode", Value: "eco"}},
+			},
+			methods: []string{"Script.List", "Script.GetCode", "KVS.GetMany"},
+		},
+		{
+			name: "success: undeclared script pruned",
+			dep:  dep,
+			host: &scriptHost{
+				scripts: []*script{
+					{ID: 1, Name: "other.js", Enable: true, Running: true},
+					{ID: 2, Name: "script1.js", Enable: true, Running: true},
 				},
+				code: map[int]string{1: "print(1);", 2: marked},
 			},
-			rs: func() []*http.Request {
-				r1 := &http.Request{
-					Method: http.MethodPost,
-					URL: &url.URL{
-						Scheme: "http",
-						Host:   "192.168.146.123",
-						Path:   rpcPath,
-					},
-					Header: http.Header{},
-					Body:   io.NopCloser(bytes.NewBufferString(`{"params":{"id":1},"src":"IoTap","method":"Script.Delete","id":0}`)),
-				}
-
-				r1.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
-
-				r2 := &http.Request{
-					Method: http.MethodPost,
-					URL: &url.URL{
-						Scheme: "http",
-						Host:   "192.168.146.123",
-						Path:   rpcPath,
-					},
-					Header: http.Header{},
-					Body:   io.NopCloser(bytes.NewBufferString(`{"params":{"name":"script1.js"},"src":"IoTap","method":"Script.Create","id":0}`)),
-				}
+			prune:   true,
+			methods: []string{"Script.List", "Script.GetCode"},
+			bodies: []string{
+				`{"id":0,"method":"Script.Stop","params":{"id":1},"src":"IoTap"}`,
+				`{"id":0,"method":"Script.Delete","params":{"id":1},"src":"IoTap"}`,
+				`{"id":0,"method":"Shelly.Reboot","src":"IoTap"}`,
+			},
+		},
+	}
 
-				r2.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
-
-				r3 := &http.Request{
-					Method: http.MethodPost,
-					URL: &url.URL{
-						Scheme: "http",
-						Host:   "192.168.146.123",
-						Path:   rpcPath,
-					},
-					Header: http.Header{},
-					Body:   io.NopCloser(bytes.NewBufferString(`{"params":{"append":false,"code":"var foo = \"abc\";","id":1},"src":"IoTap","method":"Script.PutCode","id":0}`)),
-				}
+	shelly2 := &Device{ip: net.ParseIP("192.168.146.123")}
 
-				r3.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
-
-				r4 := &http.Request{
-					Method: http.MethodPost,
-					URL: &url.URL{
-						Scheme: "http",
-						Host:   "192.168.146.123",
-						Path:   rpcPath,
-					},
-					Header: http.Header{},
-					Body:   io.NopCloser(bytes.NewBufferString(`{"params":{"config":{"enable":true},"id":1},"src":"IoTap","method":"Script.SetConfig","id":0}`)),
-				}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rt := test.rt
 
-				r4.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
-
-				r5 := &http.Request{
-					Method: http.MethodPost,
-					URL: &url.URL{
-						Scheme: "http",
-						Host:   "192.168.146.123",
-						Path:   rpcPath,
-					},
-					Header: http.Header{},
-					Body:   io.NopCloser(bytes.NewBufferString(`{"params":{"id":1},"src":"IoTap","method":"Script.Start","id":0}`)),
+			if test.host != nil {
+				if test.host.code == nil {
+					test.host.code = make(map[int]string)
 				}
 
-				r5.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
-
-				r6 := &http.Request{
-					Method: http.MethodPost,
-					URL: &url.URL{
-						Scheme: "http",
-						Host:   "192.168.146.123",
-						Path:   rpcPath,
-					},
-					Header: http.Header{},
-					Body:   io.NopCloser(bytes.NewBufferString(`{"src":"IoTap","method":"Shelly.Reboot","id":0}`)),
-				}
+				rt = test.host
+			}
 
-				r6.Header.Set(httpclient.ContentTypeHeader, httpclient.JSONMimeType)
+			rs, err := shelly2.Deploy(&http.Client{Transport: rt}, test.dep, test.prune)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
 
-				return []*http.Request{r1, r2, r3, r4, r5, r6}
-			}(),
-		},
-	}
+			if bodies := rpcBodies(t, rs); !reflect.DeepEqual(bodies, test.bodies) {
+				t.Fatalf("expected %q, got %q", test.bodies, bodies)
+			}
 
-	shelly2 := &Device{ip: net.ParseIP("192.168.146.123")}
+			if test.host == nil {
+				return
+			}
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			client := &http.Client{
-				Transport: test.rt,
+			if !reflect.DeepEqual(test.host.methods, test.methods) {
+				t.Fatalf("expected %q, got %q", test.methods, test.host.methods)
 			}
-			rs, err := shelly2.DeployRequests(client, test.dep)
 
-			for i, r := range rs {
-				compareRequests(t, test.rs[i], r)
+			// Uploaded scripts must be stored with a version marker
+			if test.err != nil || !slices.Contains(test.host.methods, "Script.PutCode") {
+				return
 			}
 
-			if !errors.Is(err, test.err) {
-				t.Fatalf("expected %#v, got %#v", test.err, err)
+			for _, src := range test.dep.Scripts {
+				if !slices.ContainsFunc(slices.Collect(maps.Values(test.host.code)), func(code string) bool {
+					return src.Current([]byte(code))
+				}) {
+					t.Fatalf("expected %s to be stored with a version marker", src.Name())
+				}
 			}
 		})
 	}
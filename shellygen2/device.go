@@ -20,6 +20,7 @@ type Device struct {
 	cred        *device.Credentials
 	name        string
 	model       string
+	profile     string
 	Realm       string
 	Firmware    string
 	Version     string
@@ -13,5 +13,7 @@ func init() {
 		return &Config{}
 	})
 
+	device.RegisterSchema(Driver, Schema())
+
 	device.RegisterDeployer(Driver)
 }
@@ -0,0 +1,148 @@
+package shellygen2
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// kvsItem is a key-value store entry representation.
+type kvsItem struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+// kvsGetResponse holds the result of a KVS.Get method request.
+type kvsGetResponse struct {
+	Result struct {
+		Value any `json:"value"`
+	} `json:"result"`
+}
+
+// kvsGetManyResponse holds the result of a KVS.GetMany method request.
+// Recent firmware versions return the items as an array, while older ones use an object keyed by item key.
+type kvsGetManyResponse struct {
+	Result struct {
+		Items jsontext.Value `json:"items"`
+		Total int            `json:"total"`
+	} `json:"result"`
+}
+
+// items returns the key-value store entries of the response.
+func (r *kvsGetManyResponse) items() ([]*kvsItem, error) {
+	if len(r.Result.Items) == 0 {
+		return nil, nil
+	}
+
+	if r.Result.Items.Kind() == '[' {
+		var items []*kvsItem
+		if err := json.Unmarshal(r.Result.Items, &items); err != nil {
+			return nil, err
+		}
+
+		return items, nil
+	}
+
+	var keyed map[string]*kvsItem
+	if err := json.Unmarshal(r.Result.Items, &keyed); err != nil {
+		return nil, err
+	}
+
+	items := make([]*kvsItem, 0, len(keyed))
+
+	for key, item := range keyed {
+		item.Key = key
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// KeyValues returns every key-value store entry of the device, fetching them one page at a time.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/KVS#kvsgetmany
+func (d *Device) KeyValues(client *http.Client) (map[string]any, error) {
+	values := make(map[string]any)
+
+	for {
+		var params any
+		if len(values) > 0 {
+			params = map[string]any{"offset": len(values)}
+		}
+
+		resp := &kvsGetManyResponse{}
+
+		if err := d.call(client, "KVS.GetMany", params, resp); err != nil {
+			return nil, err
+		}
+
+		items, err := resp.items()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			values[item.Key] = item.Value
+		}
+
+		// Older firmware versions return every item at once, without a total
+		if len(items) == 0 || len(values) >= resp.Result.Total {
+			return values, nil
+		}
+	}
+}
+
+// KeyValue returns the value of a key-value store entry, and whether the device holds it.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/KVS#kvsget
+func (d *Device) KeyValue(client *http.Client, key string) (any, bool, error) {
+	resp := &kvsGetResponse{}
+
+	err := d.call(client, "KVS.Get", map[string]any{"key": key}, resp)
+	if isRPCError(err, errCodeNotFound) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return resp.Result.Value, true, nil
+}
+
+// SetKeyValueRequests creates a slice of *http.Request objects for setting key-value store entries, in key order.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/KVS#kvsset
+func (d *Device) SetKeyValueRequests(values map[string]any) ([]*http.Request, error) {
+	requests := make([]*http.Request, 0, len(values))
+
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		r, err := request(d, "KVS.Set", map[string]any{
+			"key":   key,
+			"value": values[key],
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		requests = append(requests, r)
+	}
+
+	return requests, nil
+}
+
+// DeleteKeyValueRequests creates a slice of *http.Request objects for deleting key-value store entries.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/KVS#kvsdelete
+func (d *Device) DeleteKeyValueRequests(keys []string) ([]*http.Request, error) {
+	requests := make([]*http.Request, 0, len(keys))
+
+	for _, key := range keys {
+		r, err := request(d, "KVS.Delete", map[string]any{"key": key})
+		if err != nil {
+			return nil, err
+		}
+
+		requests = append(requests, r)
+	}
+
+	return requests, nil
+}
@@ -0,0 +1,163 @@
+package shellygen2
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestDevice_KeyValues(t *testing.T) {
+	tests := []struct {
+		rt     http.RoundTripper
+		err    error
+		values map[string]any
+		name   string
+	}{
+		{
+			name: "failure: dispatch failed",
+			rt: &roundTripper{
+				err: net.ErrClosed,
+			},
+			err: net.ErrClosed,
+		},
+		{
+			name: "failure: rpc error",
+			rt: &sequenceRoundTripper{
+				bodies: []string{`{"error":{"code":404,"message":"No handler for KVS.GetMany"}}`},
+			},
+			err: &rpcError{Code: 404},
+		},
+		{
+			name: "success: keyed items",
+			rt: &sequenceRoundTripper{
+				bodies: []string{`{"result":{"items":{"room":{"etag":"a","value":"kitchen"},"threshold":{"etag":"b","value":21.5}}}}`},
+			},
+			values: map[string]any{
+				"room":      "kitchen",
+				"threshold": 21.5,
+			},
+		},
+		{
+			name: "success: paginated items",
+			rt: &sequenceRoundTripper{
+				bodies: []string{
+					`{"result":{"items":[{"key":"room","etag":"a","value":"kitchen"}],"offset":0,"total":2}}`,
+					`{"result":{"items":[{"key":"notify","etag":"b","value":true}],"offset":1,"total":2}}`,
+				},
+			},
+			values: map[string]any{
+				"room":   "kitchen",
+				"notify": true,
+			},
+		},
+		{
+			name: "success: no items",
+			rt: &sequenceRoundTripper{
+				bodies: []string{`{"result":{"items":[],"offset":0,"total":0}}`},
+			},
+			values: map[string]any{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values, err := (&Device{}).KeyValues(&http.Client{Transport: test.rt})
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if !reflect.DeepEqual(values, test.values) {
+				t.Fatalf("expected %v, got %v", test.values, values)
+			}
+		})
+	}
+}
+
+func TestDevice_KeyValue(t *testing.T) {
+	tests := []struct {
+		rt    http.RoundTripper
+		value any
+		name  string
+		found bool
+		err   bool
+	}{
+		{
+			name: "failure: dispatch failed",
+			rt: &roundTripper{
+				err: net.ErrClosed,
+			},
+			err: true,
+		},
+		{
+			name: "failure: rpc error",
+			rt: &sequenceRoundTripper{
+				bodies: []string{`{"error":{"code":-103,"message":"Resource unavailable"}}`},
+			},
+			err: true,
+		},
+		{
+			name: "success: key not found",
+			rt: &sequenceRoundTripper{
+				bodies: []string{`{"error":{"code":-105,"message":"Argument 'key', value 'room' not found!"}}`},
+			},
+		},
+		{
+			name: "success: key found",
+			rt: &sequenceRoundTripper{
+				bodies: []string{`{"result":{"etag":"a","value":"kitchen"}}`},
+			},
+			value: "kitchen",
+			found: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, found, err := (&Device{}).KeyValue(&http.Client{Transport: test.rt}, "room")
+			if (err != nil) != test.err {
+				t.Fatalf("expected error %t, got %v", test.err, err)
+			}
+
+			if found != test.found || !reflect.DeepEqual(value, test.value) {
+				t.Fatalf("expected %v (%t), got %v (%t)", test.value, test.found, value, found)
+			}
+		})
+	}
+}
+
+func TestDevice_SetKeyValueRequests(t *testing.T) {
+	rs, err := (&Device{}).SetKeyValueRequests(map[string]any{
+		"threshold": 21.5,
+		"room":      "kitchen",
+	})
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := []string{
+		`{"id":0,"method":"KVS.Set","params":{"key":"room","value":"kitchen"},"src":"IoTap"}`,
+		`{"id":0,"method":"KVS.Set","params":{"key":"threshold","value":21.5},"src":"IoTap"}`,
+	}
+
+	if bodies := rpcBodies(t, rs); !reflect.DeepEqual(bodies, expected) {
+		t.Fatalf("expected %q, got %q", expected, bodies)
+	}
+}
+
+func TestDevice_DeleteKeyValueRequests(t *testing.T) {
+	rs, err := (&Device{}).DeleteKeyValueRequests([]string{"room", "threshold"})
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := []string{
+		`{"id":0,"method":"KVS.Delete","params":{"key":"room"},"src":"IoTap"}`,
+		`{"id":0,"method":"KVS.Delete","params":{"key":"threshold"},"src":"IoTap"}`,
+	}
+
+	if bodies := rpcBodies(t, rs); !reflect.DeepEqual(bodies, expected) {
+		t.Fatalf("expected %q, got %q", expected, bodies)
+	}
+}
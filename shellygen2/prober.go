@@ -38,6 +38,7 @@ func (p *Prober) Unmarshaler() *json.Unmarshalers {
 			Realm    *string `json:"id"`
 			MAC      *string `json:"mac"`
 			Model    *string `json:"model"`
+			Profile  *string `json:"profile"`
 			Gen      *uint8  `json:"gen"`
 			Firmware *string `json:"fw_id"`
 			Version  *string `json:"ver"`
@@ -68,6 +69,11 @@ func (p *Prober) Unmarshaler() *json.Unmarshalers {
 			dev.name = *v.Name
 		}
 
+		// Only devices with multiple profiles report the current one
+		if v.Profile != nil {
+			dev.profile = *v.Profile
+		}
+
 		dev.Realm = *v.Realm
 		dev.model = *v.Model
 		dev.Gen = *v.Gen
@@ -86,6 +86,11 @@ func TestProber_Unmarshaler(t *testing.T) {
 			dev:  &Device{},
 			data: []byte(`{"name":null,"id":"shellypro1-001122334455","mac":"001122334455","model":"SPSW-201XE16EU","gen":2,"fw_id":"20230913-112003/v1.14.0-gcb84623","ver":"1.4.4","app":"Pro1","auth_en":false}`),
 		},
+		{
+			name: "success: device with profile",
+			dev:  &Device{},
+			data: []byte(`{"name":"Shelly Plus 2PM","id":"shellyplus2pm-001122334455","mac":"001122334455","model":"SNSW-102P16EU","gen":2,"fw_id":"20230913-112003/v1.14.0-gcb84623","ver":"1.4.4","app":"Plus2PM","profile":"switch","auth_en":false}`),
+		},
 	}
 
 	for _, test := range tests {
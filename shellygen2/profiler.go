@@ -0,0 +1,34 @@
+package shellygen2
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+// ProfileRequest returns a device profile switch HTTP request, or nil if the device already has the Config profile.
+// The current profile is reported by Shelly.GetDeviceInfo, which is what the device is probed with.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Shelly#shellysetprofile
+func (d *Device) ProfileRequest(config device.Config) (*http.Request, error) {
+	conf, ok := config.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected %q, got %q", device.ErrDriverMismatch, d.Driver(), config.Driver())
+	}
+
+	if conf.Profile == nil || *conf.Profile == d.profile {
+		return nil, nil
+	}
+
+	return request(d, "Shelly.SetProfile", map[string]any{
+		"name": *conf.Profile,
+	})
+}
+
+// ReadyRequest returns an HTTP request that succeeds once the device is back online.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Shelly#http-endpoint-shelly
+func (d *Device) ReadyRequest() (*http.Request, error) {
+	r, _, err := (&Prober{}).Request(d.ip)
+
+	return r, err
+}
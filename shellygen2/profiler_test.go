@@ -0,0 +1,95 @@
+package shellygen2
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+func TestDevice_ProfileRequest(t *testing.T) {
+	sw, cover := "switch", "cover"
+
+	tests := []struct {
+		cfg  device.Config
+		err  error
+		name string
+		body string
+	}{
+		{
+			name: "failure: driver mismatch",
+			cfg:  &config{},
+			err:  device.ErrDriverMismatch,
+		},
+		{
+			name: "success: profile unset",
+			cfg:  &Config{},
+		},
+		{
+			name: "success: profile unchanged",
+			cfg: &Config{
+				Profile: &sw,
+			},
+		},
+		{
+			name: "success: profile switch",
+			cfg: &Config{
+				Profile: &cover,
+			},
+			body: `{"params":{"name":"cover"},"src":"IoTap","method":"Shelly.SetProfile","id":0}`,
+		},
+	}
+
+	dev := &Device{
+		ip:      net.ParseIP("192.168.146.123"),
+		profile: "switch",
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := dev.ProfileRequest(test.cfg)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if test.body == "" {
+				if r != nil {
+					t.Fatalf("expected nil, got %v", r)
+				}
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			if string(body) != test.body {
+				t.Fatalf("expected %s, got %s", test.body, body)
+			}
+		})
+	}
+}
+
+func TestDevice_ReadyRequest(t *testing.T) {
+	dev := &Device{
+		ip: net.ParseIP("192.168.146.123"),
+	}
+
+	r, err := dev.ReadyRequest()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	if r.Method != http.MethodGet {
+		t.Fatalf("expected %s, got %s", http.MethodGet, r.Method)
+	}
+
+	expectedURL := "http://192.168.146.123/shelly"
+	if r.URL.String() != expectedURL {
+		t.Fatalf("expected %s, got %s", expectedURL, r.URL.String())
+	}
+}
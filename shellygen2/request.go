@@ -2,7 +2,9 @@ package shellygen2
 
 import (
 	"bytes"
+	"encoding/json/jsontext"
 	"encoding/json/v2"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -22,6 +24,33 @@ type rpcRequest struct {
 	ID         int    `json:"id"`
 }
 
+// errCodeNotFound is the RPC error code returned when a resource (e.g. KVS key) doesn't exist.
+// See: https://shelly-api-docs.shelly.cloud/gen2/General/CommonErrors
+const errCodeNotFound = -105
+
+// rpcError holds the error of an RPC method request.
+type rpcError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// Error interface implementation.
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// Is checks if the target is an RPC method error with the same code, for use with errors.Is.
+func (e *rpcError) Is(target error) bool {
+	t, ok := target.(*rpcError)
+
+	return ok && t.Code == e.Code
+}
+
+// isRPCError checks if an error is an RPC method error with the given code.
+func isRPCError(err error, code int) bool {
+	return errors.Is(err, &rpcError{Code: code})
+}
+
 // buildURL for Shelly Gen2 requests.
 func buildURL(ip net.IP, path string) string {
 	return fmt.Sprintf("http://%s/%s", ip.String(), strings.TrimPrefix(path, "/"))
@@ -52,3 +81,45 @@ func request(dev *Device, method string, params any) (*http.Request, error) {
 
 	return r, nil
 }
+
+// fetch dispatches an RPC method request without parameters, binding its response.
+func (d *Device) fetch(client *http.Client, method string, resp any) error {
+	return d.call(client, method, nil, resp)
+}
+
+// rpcFrame holds the error member of an RPC response frame.
+type rpcFrame struct {
+	Error *rpcError `json:"error"`
+}
+
+// call dispatches an RPC method request, binding its response.
+// Method errors reported by the device are returned as *rpcError, leaving the response unbound.
+func (d *Device) call(client *http.Client, method string, params, resp any) error {
+	r, err := request(d, method, params)
+	if err != nil {
+		return err
+	}
+
+	var raw jsontext.Value
+
+	dispatcher := httpclient.NewDispatcher(client)
+
+	if err = dispatcher.Dispatch(r, httpclient.WithBinding(&raw), httpclient.WithChallenger(d)); err != nil {
+		return err
+	}
+
+	frame := &rpcFrame{}
+	if err = json.Unmarshal(raw, frame); err != nil {
+		return err
+	}
+
+	if frame.Error != nil {
+		return frame.Error
+	}
+
+	if resp == nil {
+		return nil
+	}
+
+	return json.Unmarshal(raw, resp)
+}
@@ -0,0 +1,202 @@
+package shellygen2
+
+import (
+	"encoding/json/v2"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+// call is a method executed by a scheduled job.
+type call struct {
+	Method string         `json:"method"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// job is a scheduled job resource representation.
+type job struct {
+	ID       int     `json:"id,omitzero"`
+	Enable   bool    `json:"enable"`
+	Timespec string  `json:"timespec"`
+	Calls    []*call `json:"calls"`
+}
+
+// key identifies what a job does and when, regardless of its ID or whether it's enabled.
+func (j *job) key() string {
+	calls, _ := json.Marshal(j.Calls, json.Deterministic(true))
+
+	return j.Timespec + " " + string(calls)
+}
+
+// scheduled returns the display friendly representation of the job.
+func (j *job) scheduled() *device.ScheduledJob {
+	var actions []string
+
+	for _, c := range j.Calls {
+		params, _ := json.Marshal(c.Params, json.Deterministic(true))
+
+		actions = append(actions, fmt.Sprintf("%s %s", c.Method, params))
+	}
+
+	return &device.ScheduledJob{
+		ID:      fmt.Sprint(j.ID),
+		Enabled: j.Enable,
+		Spec:    j.Timespec,
+		Action:  strings.Join(actions, "; "),
+	}
+}
+
+// newJob converts a driver agnostic device.Job into a Switch.Set scheduled job.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Schedule#timespec
+func newJob(j *device.Job) *job {
+	days := "*"
+
+	if len(j.Days) > 0 {
+		var names []string
+		for _, day := range j.Weekdays() {
+			names = append(names, strings.ToUpper(device.Weekdays[day]))
+		}
+
+		days = strings.Join(names, ",")
+	}
+
+	hour, minute := j.Clock()
+
+	return &job{
+		Enable:   true,
+		Timespec: fmt.Sprintf("0 %d %d * * %s", minute, hour, days),
+		Calls: []*call{
+			{
+				Method: "Switch.Set",
+				Params: map[string]any{
+					"id": j.Channel,
+					"on": j.On(),
+				},
+			},
+		},
+	}
+}
+
+// jobListResponse holds the result of a Schedule.List method request.
+type jobListResponse struct {
+	Result struct {
+		Jobs []*job `json:"jobs"`
+	} `json:"result"`
+}
+
+// fetchJobs returns the scheduled jobs of the device.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Schedule#schedulelist
+func (d *Device) fetchJobs(client *http.Client) ([]*job, error) {
+	resp := &jobListResponse{}
+
+	if err := d.fetch(client, "Schedule.List", resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Result.Jobs, nil
+}
+
+// ScheduledJobs returns the scheduled jobs of the device.
+func (d *Device) ScheduledJobs(client *http.Client) ([]*device.ScheduledJob, error) {
+	jobs, err := d.fetchJobs(client)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := make([]*device.ScheduledJob, 0, len(jobs))
+
+	for _, j := range jobs {
+		scheduled = append(scheduled, j.scheduled())
+	}
+
+	return scheduled, nil
+}
+
+// ScheduleRequests creates an ordered slice of *http.Request objects for syncing a device.Schedule.
+// Jobs already on the device are kept, and enabled if needed, while the missing ones are created.
+// Device jobs that aren't in the Schedule are returned, and deleted when pruning.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Schedule
+func (d *Device) ScheduleRequests(client *http.Client, sch *device.Schedule, prune bool) ([]*http.Request, []*device.ScheduledJob, error) {
+	existing, err := d.fetchJobs(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var requests []*http.Request
+
+	matched := make(map[int]bool, len(existing))
+
+	for _, dj := range sch.Jobs {
+		want := newJob(dj)
+
+		var found *job
+		for _, j := range existing {
+			if !matched[j.ID] && j.key() == want.key() {
+				found = j
+				break
+			}
+		}
+
+		if found == nil {
+			r, err := request(d, "Schedule.Create", want)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			requests = append(requests, r)
+			continue
+		}
+
+		matched[found.ID] = true
+
+		if found.Enable {
+			continue
+		}
+
+		r, err := request(d, "Schedule.Update", map[string]any{
+			"id":     found.ID,
+			"enable": true,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		requests = append(requests, r)
+	}
+
+	var extra []*device.ScheduledJob
+
+	for _, j := range existing {
+		if matched[j.ID] {
+			continue
+		}
+
+		extra = append(extra, j.scheduled())
+
+		if !prune {
+			continue
+		}
+
+		r, err := request(d, "Schedule.Delete", map[string]any{"id": j.ID})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		requests = append(requests, r)
+	}
+
+	return requests, extra, nil
+}
+
+// ClearScheduleRequests creates a slice of *http.Request objects for deleting every scheduled job.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Schedule#scheduledeleteall
+func (d *Device) ClearScheduleRequests(*http.Client) ([]*http.Request, error) {
+	r, err := request(d, "Schedule.DeleteAll", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*http.Request{r}, nil
+}
@@ -0,0 +1,172 @@
+package shellygen2
+
+import (
+	"encoding/json/v2"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+// rpcBodies returns the request bodies, with their JSON keys sorted.
+func rpcBodies(t *testing.T, rs []*http.Request) []string {
+	var bodies []string
+
+	for _, r := range rs {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("unable to read body: %v", err)
+		}
+
+		var value any
+		if err = json.Unmarshal(data, &value); err != nil {
+			t.Fatalf("invalid JSON body: %v\n%s", err, data)
+		}
+
+		data, err = json.Marshal(value, json.Deterministic(true))
+		if err != nil {
+			t.Fatalf("unable to marshal body: %v", err)
+		}
+
+		bodies = append(bodies, string(data))
+	}
+
+	return bodies
+}
+
+const jobList = `{"result":{"jobs":[` +
+	`{"id":1,"enable":true,"timespec":"0 0 8 * * MON,FRI","calls":[{"method":"Switch.Set","params":{"id":0,"on":true}}]},` +
+	`{"id":2,"enable":false,"timespec":"0 30 18 * * *","calls":[{"method":"Switch.Set","params":{"id":0,"on":false}}]},` +
+	`{"id":3,"enable":true,"timespec":"@sunset","calls":[{"method":"Script.Start","params":{"id":1}}]}` +
+	`]}}`
+
+func TestDevice_ScheduledJobs(t *testing.T) {
+	rt := &roundTripper{
+		response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(jobList)),
+		},
+	}
+
+	jobs, err := (&Device{}).ScheduledJobs(&http.Client{Transport: rt})
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := []*device.ScheduledJob{
+		{ID: "1", Enabled: true, Spec: "0 0 8 * * MON,FRI", Action: `Switch.Set {"id":0,"on":true}`},
+		{ID: "2", Enabled: false, Spec: "0 30 18 * * *", Action: `Switch.Set {"id":0,"on":false}`},
+		{ID: "3", Enabled: true, Spec: "@sunset", Action: `Script.Start {"id":1}`},
+	}
+
+	if !reflect.DeepEqual(jobs, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, jobs)
+	}
+}
+
+func TestDevice_ScheduleRequests(t *testing.T) {
+	sch := &device.Schedule{
+		Jobs: []*device.Job{
+			{Time: "08:00", Days: []string{"fri", "mon"}, Action: device.ActionOn},
+			{Time: "18:30", Action: device.ActionOff},
+			{Channel: 1, Time: "07:05", Days: []string{"sat"}, Action: device.ActionOn},
+		},
+	}
+
+	tests := []struct {
+		rt     http.RoundTripper
+		err    error
+		name   string
+		bodies []string
+		extra  []string
+		prune  bool
+	}{
+		{
+			name: "failure: dispatch failed",
+			rt: &roundTripper{
+				err: net.ErrClosed,
+			},
+			err: net.ErrClosed,
+		},
+		{
+			name: "failure: rpc error",
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"error":{"code":-103,"message":"Invalid argument"}}`)),
+				},
+			},
+			err: &rpcError{Code: -103},
+		},
+		{
+			name: "success: jobs synced",
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(jobList)),
+				},
+			},
+			bodies: []string{
+				`{"id":0,"method":"Schedule.Update","params":{"enable":true,"id":2},"src":"IoTap"}`,
+				`{"id":0,"method":"Schedule.Create","params":{"calls":[{"method":"Switch.Set","params":{"id":1,"on":true}}],"enable":true,"timespec":"0 5 7 * * SAT"},"src":"IoTap"}`,
+			},
+			extra: []string{"3"},
+		},
+		{
+			name: "success: jobs synced and pruned",
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(jobList)),
+				},
+			},
+			prune: true,
+			bodies: []string{
+				`{"id":0,"method":"Schedule.Update","params":{"enable":true,"id":2},"src":"IoTap"}`,
+				`{"id":0,"method":"Schedule.Create","params":{"calls":[{"method":"Switch.Set","params":{"id":1,"on":true}}],"enable":true,"timespec":"0 5 7 * * SAT"},"src":"IoTap"}`,
+				`{"id":0,"method":"Schedule.Delete","params":{"id":3},"src":"IoTap"}`,
+			},
+			extra: []string{"3"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rs, extra, err := (&Device{}).ScheduleRequests(&http.Client{Transport: test.rt}, sch, test.prune)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if bodies := rpcBodies(t, rs); !reflect.DeepEqual(bodies, test.bodies) {
+				t.Fatalf("expected %q, got %q", test.bodies, bodies)
+			}
+
+			var ids []string
+			for _, job := range extra {
+				ids = append(ids, job.ID)
+			}
+
+			if !reflect.DeepEqual(ids, test.extra) {
+				t.Fatalf("expected %v, got %v", test.extra, ids)
+			}
+		})
+	}
+}
+
+func TestDevice_ClearScheduleRequests(t *testing.T) {
+	rs, err := (&Device{}).ClearScheduleRequests(nil)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := []string{`{"id":0,"method":"Schedule.DeleteAll","src":"IoTap"}`}
+
+	if bodies := rpcBodies(t, rs); !reflect.DeepEqual(bodies, expected) {
+		t.Fatalf("expected %q, got %q", expected, bodies)
+	}
+}
@@ -0,0 +1,250 @@
+package shellygen2
+
+import "github.com/quetzyg/IoTap/device"
+
+// component returns the Schema of a component configuration (i.e. <Component>.SetConfig parameters).
+func component(config map[string]*device.Schema) *device.Schema {
+	return device.ObjectSchema(map[string]*device.Schema{
+		"config": device.ObjectSchema(config),
+	}, "config")
+}
+
+// indexed returns the Schema of an indexed component configuration list, addressed by id.
+func indexed(config map[string]*device.Schema) *device.Schema {
+	return device.ArraySchema(device.ObjectSchema(map[string]*device.Schema{
+		"id":     device.IntegerSchema().AtLeast(0),
+		"config": device.ObjectSchema(config),
+	}, "id", "config"))
+}
+
+// nullableString returns the Schema of an optional string.
+func nullableString() *device.Schema {
+	return device.StringSchema().Nullable()
+}
+
+// toggle returns the Schema of an object with a single enable setting.
+func toggle() *device.Schema {
+	return device.ObjectSchema(map[string]*device.Schema{
+		"enable": device.BooleanSchema(),
+	})
+}
+
+// sslCA returns the Schema of a TLS certificate authority setting.
+func sslCA() *device.Schema {
+	return device.StringSchema("*", "user_ca.pem", "ca.pem").Nullable()
+}
+
+// ipv4 returns the Schema of an IPv4 network setup.
+func ipv4(props map[string]*device.Schema) map[string]*device.Schema {
+	props["ipv4mode"] = device.StringSchema("dhcp", "static")
+	props["ip"] = nullableString()
+	props["netmask"] = nullableString()
+	props["gw"] = nullableString()
+	props["nameserver"] = nullableString()
+
+	return props
+}
+
+// station returns the Schema of a Wi-Fi station setup.
+func station() *device.Schema {
+	return device.ObjectSchema(ipv4(map[string]*device.Schema{
+		"ssid":    nullableString(),
+		"pass":    nullableString(),
+		"is_open": device.BooleanSchema(),
+		"enable":  device.BooleanSchema(),
+	}))
+}
+
+// limits returns the Schema of the electrical protection limits, shared by output components.
+func limits(props map[string]*device.Schema) map[string]*device.Schema {
+	props["power_limit"] = device.NumberSchema().AtLeast(0)
+	props["voltage_limit"] = device.NumberSchema().AtLeast(0)
+	props["undervoltage_limit"] = device.NumberSchema().AtLeast(0)
+	props["current_limit"] = device.NumberSchema().AtLeast(0)
+
+	return props
+}
+
+// components holds the Schemas of the supported Gen2 components and the device profile, keyed by their Config tag.
+// See: https://shelly-api-docs.shelly.cloud/gen2/
+var components = map[string]*device.Schema{
+	"ble": component(map[string]*device.Schema{
+		"enable":   device.BooleanSchema(),
+		"rpc":      toggle(),
+		"observer": toggle(),
+	}),
+	"bthome": component(nil),
+	"cloud": component(map[string]*device.Schema{
+		"enable": device.BooleanSchema(),
+		"server": nullableString(),
+	}),
+	"cover": indexed(limits(map[string]*device.Schema{
+		"name":                  nullableString(),
+		"in_mode":               device.StringSchema("single", "dual", "detached"),
+		"in_locked":             device.BooleanSchema(),
+		"initial_state":         device.StringSchema("open", "closed", "stopped"),
+		"invert_directions":     device.BooleanSchema(),
+		"swap_inputs":           device.BooleanSchema(),
+		"maxtime_open":          device.NumberSchema().AtLeast(0),
+		"maxtime_close":         device.NumberSchema().AtLeast(0),
+		"motor":                 device.ObjectSchema(nil),
+		"obstruction_detection": device.ObjectSchema(nil),
+		"safety_switch":         device.ObjectSchema(nil),
+		"slat":                  device.ObjectSchema(nil),
+	})),
+	"em": indexed(map[string]*device.Schema{
+		"name":                   nullableString(),
+		"blink_mode_selector":    device.StringSchema("active_energy", "apparent_energy"),
+		"phase_selector":         device.StringSchema("all", "a", "b", "c"),
+		"monitor_phase_sequence": device.BooleanSchema(),
+		"ct_type":                device.StringSchema(),
+		"reverse":                device.ObjectSchema(nil),
+	}),
+	"em1": indexed(map[string]*device.Schema{
+		"name":    nullableString(),
+		"ct_type": device.StringSchema(),
+		"reverse": device.BooleanSchema(),
+	}),
+	"eth": component(ipv4(map[string]*device.Schema{
+		"enable": device.BooleanSchema(),
+	})),
+	"humidity": indexed(map[string]*device.Schema{
+		"name":       nullableString(),
+		"report_thr": device.NumberSchema().Between(1, 100),
+		"offset":     device.NumberSchema(),
+	}),
+	"input": indexed(map[string]*device.Schema{
+		"name":          nullableString(),
+		"type":          device.StringSchema("switch", "button", "analog", "count"),
+		"enable":        device.BooleanSchema(),
+		"invert":        device.BooleanSchema(),
+		"factory_reset": device.BooleanSchema(),
+		"report_thr":    device.NumberSchema(),
+		"range_map":     device.ArraySchema(device.NumberSchema()).Nullable(),
+		"xpercent":      device.ObjectSchema(nil).Nullable(),
+		"count_rep_thr": device.IntegerSchema().AtLeast(0),
+		"freq_window":   device.IntegerSchema().AtLeast(0),
+		"freq_thr":      device.NumberSchema(),
+	}),
+	"light": indexed(limits(map[string]*device.Schema{
+		"name":                     nullableString(),
+		"in_mode":                  device.StringSchema("follow", "flip", "activate", "detached", "dim", "dual_dim"),
+		"initial_state":            device.StringSchema("off", "on", "restore_last"),
+		"auto_on":                  device.BooleanSchema(),
+		"auto_on_delay":            device.NumberSchema().AtLeast(0),
+		"auto_off":                 device.BooleanSchema(),
+		"auto_off_delay":           device.NumberSchema().AtLeast(0),
+		"transition_duration":      device.NumberSchema().AtLeast(0),
+		"min_brightness_on_toggle": device.NumberSchema().Between(0, 100),
+		"button_fade_rate":         device.IntegerSchema().Between(1, 5),
+		"range_map":                device.ArraySchema(device.NumberSchema()).Nullable(),
+		"default":                  device.ObjectSchema(nil),
+		"night_mode":               device.ObjectSchema(nil),
+		"button_presets":           device.ObjectSchema(nil),
+	})),
+	"matter": component(map[string]*device.Schema{
+		"enable": device.BooleanSchema(),
+	}),
+	"mqtt": component(map[string]*device.Schema{
+		"enable":          device.BooleanSchema(),
+		"server":          nullableString(),
+		"client_id":       nullableString(),
+		"user":            nullableString(),
+		"pass":            nullableString(),
+		"ssl_ca":          sslCA(),
+		"topic_prefix":    nullableString(),
+		"rpc_ntf":         device.BooleanSchema(),
+		"status_ntf":      device.BooleanSchema(),
+		"use_client_cert": device.BooleanSchema(),
+		"enable_rpc":      device.BooleanSchema(),
+		"enable_control":  device.BooleanSchema(),
+	}),
+	"profile": device.StringSchema().Describe("Device profile (e.g. switch, cover), switched before any component"),
+	"pm1": indexed(map[string]*device.Schema{
+		"name":    nullableString(),
+		"reverse": device.BooleanSchema(),
+	}),
+	"smoke": indexed(map[string]*device.Schema{
+		"name": nullableString(),
+	}),
+	"switch": indexed(limits(map[string]*device.Schema{
+		"name":                       nullableString(),
+		"in_mode":                    device.StringSchema("momentary", "follow", "flip", "detached", "cycle", "activate"),
+		"in_locked":                  device.BooleanSchema(),
+		"initial_state":              device.StringSchema("off", "on", "restore_last", "match_input"),
+		"auto_on":                    device.BooleanSchema(),
+		"auto_on_delay":              device.NumberSchema().AtLeast(0),
+		"auto_off":                   device.BooleanSchema(),
+		"auto_off_delay":             device.NumberSchema().AtLeast(0),
+		"autorecover_voltage_errors": device.BooleanSchema(),
+		"input_id":                   device.IntegerSchema().AtLeast(0),
+	})),
+	"sys": component(map[string]*device.Schema{
+		"device": device.ObjectSchema(map[string]*device.Schema{
+			"name":         nullableString(),
+			"eco_mode":     device.BooleanSchema(),
+			"profile":      device.StringSchema(),
+			"discoverable": device.BooleanSchema(),
+			"addon_type":   nullableString(),
+		}),
+		"location": device.ObjectSchema(map[string]*device.Schema{
+			"tz":  nullableString(),
+			"lat": device.NumberSchema().Between(-90, 90).Nullable(),
+			"lon": device.NumberSchema().Between(-180, 180).Nullable(),
+		}),
+		"debug": device.ObjectSchema(map[string]*device.Schema{
+			"level":      device.IntegerSchema().Between(-1, 4),
+			"file_level": device.IntegerSchema().Between(-1, 4).Nullable(),
+			"mqtt":       toggle(),
+			"websocket":  toggle(),
+			"udp": device.ObjectSchema(map[string]*device.Schema{
+				"addr": nullableString(),
+			}),
+		}),
+		"ui_data": device.ObjectSchema(nil),
+		"rpc_udp": device.ObjectSchema(map[string]*device.Schema{
+			"dst_addr":    nullableString(),
+			"listen_port": device.IntegerSchema().Between(0, 65535).Nullable(),
+		}),
+		"sntp": device.ObjectSchema(map[string]*device.Schema{
+			"server": device.StringSchema(),
+		}),
+	}),
+	"temperature": indexed(map[string]*device.Schema{
+		"name":         nullableString(),
+		"report_thr_C": device.NumberSchema().Between(0.5, 5),
+		"offset_C":     device.NumberSchema(),
+	}),
+	"ui": component(nil),
+	"voltmeter": indexed(map[string]*device.Schema{
+		"name":       nullableString(),
+		"report_thr": device.NumberSchema().AtLeast(0),
+		"range":      device.IntegerSchema().AtLeast(0),
+		"xvoltage":   device.ObjectSchema(nil).Nullable(),
+	}),
+	"wifi": component(map[string]*device.Schema{
+		"ap": device.ObjectSchema(map[string]*device.Schema{
+			"ssid":           nullableString(),
+			"pass":           nullableString(),
+			"is_open":        device.BooleanSchema(),
+			"enable":         device.BooleanSchema(),
+			"range_extender": toggle(),
+		}),
+		"sta":  station(),
+		"sta1": station(),
+		"roam": device.ObjectSchema(map[string]*device.Schema{
+			"rssi_thr": device.IntegerSchema().Between(-127, 0),
+			"interval": device.IntegerSchema().AtLeast(0),
+		}),
+	}),
+	"ws": component(map[string]*device.Schema{
+		"enable": device.BooleanSchema(),
+		"server": nullableString(),
+		"ssl_ca": sslCA(),
+	}),
+}
+
+// Schema returns the configuration Schema of the Shelly Gen2 driver.
+func Schema() *device.Schema {
+	return device.ConfigSchema(components)
+}
@@ -0,0 +1,85 @@
+package shellygen2
+
+import (
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+func TestSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		issues int
+	}{
+		{
+			name: "valid configuration",
+			data: `{
+				"meta": {"device": "Shelly Plus 1"},
+				"policy": {"mode": "whitelist", "models": ["SNSW-001X16EU"]},
+				"sys": {"config": {"device": {"eco_mode": false}, "sntp": {"server": "time.cloudflare.com"}}},
+				"input": [{"id": 0, "config": {"name": null, "type": "switch", "invert": true}}],
+				"switch": [{"id": 0, "config": {"in_mode": "detached", "auto_off": true, "auto_off_delay": 3}}],
+				"wifi": {"config": {"sta": {"enable": true, "ssid": "WIFI", "pass": "${WIFI_PASS}", "ipv4mode": "dhcp"}}},
+				"mqtt": {"config": {"enable": true, "server": "192.168.1.254:1883", "ssl_ca": null}},
+				"overrides": [{"match": {"names": ["^garage"]}, "settings": {"switch": [{"id": 0, "config": {"auto_off": false}}]}}]
+			}`,
+		},
+		{
+			name: "valid additional components",
+			data: `{
+				"cover": [{"id": 0, "config": {"in_mode": "dual", "maxtime_open": 30, "slat": {"enable": true}}}],
+				"light": [{"id": 0, "config": {"initial_state": "restore_last", "default": {"brightness": 50}}}],
+				"pm1": [{"id": 0, "config": {"name": "Heater"}}],
+				"em1": [{"id": 0, "config": {"ct_type": "120A"}}],
+				"temperature": [{"id": 100, "config": {"report_thr_C": 1, "offset_C": -0.5}}],
+				"ui": {"config": {"idle_brightness": 30}},
+				"matter": {"config": {"enable": false}}
+			}`,
+		},
+		{
+			name:   "invalid additional components",
+			data:   `{"cover": [{"id": 0, "config": {"in_mode": "triple"}}], "matter": {"config": {"enabled": true}}}`,
+			issues: 2,
+		},
+		{
+			name:   "unknown component",
+			data:   `{"foo": {"config": {}}}`,
+			issues: 1,
+		},
+		{
+			name:   "unknown component setting",
+			data:   `{"mqtt": {"config": {"enabled": true}}}`,
+			issues: 1,
+		},
+		{
+			name:   "missing component id",
+			data:   `{"switch": [{"config": {"auto_off": true}}]}`,
+			issues: 1,
+		},
+		{
+			name:   "invalid values",
+			data:   `{"switch": [{"id": 0, "config": {"in_mode": "toggle", "auto_off_delay": -1}}]}`,
+			issues: 2,
+		},
+		{
+			name:   "invalid override",
+			data:   `{"overrides": [{"settings": {"wifi": {"config": {"sta": {"ipv4mode": "auto"}}}}}]}`,
+			issues: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := device.ParseNode(device.FormatJSON, []byte(test.data))
+			if err != nil {
+				t.Fatalf("unable to parse data: %v", err)
+			}
+
+			errs := Schema().Validate(n)
+			if len(errs) != test.issues {
+				t.Fatalf("expected %d issues, got %v", test.issues, errs)
+			}
+		})
+	}
+}
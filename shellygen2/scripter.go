@@ -0,0 +1,144 @@
+package shellygen2
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+// codeResponse holds the result of a Script.GetCode method request.
+type codeResponse struct {
+	Result struct {
+		Data string `json:"data"`
+		Left int    `json:"left"`
+	} `json:"result"`
+}
+
+// InstalledScripts returns the scripts installed on the device.
+func (d *Device) InstalledScripts(client *http.Client) ([]*device.InstalledScript, error) {
+	scripts, err := d.fetchScripts(client)
+	if err != nil {
+		return nil, err
+	}
+
+	installed := make([]*device.InstalledScript, 0, len(scripts))
+
+	for _, s := range scripts {
+		installed = append(installed, &device.InstalledScript{
+			Name:    s.Name,
+			ID:      s.ID,
+			Enabled: s.Enable,
+			Running: s.Running,
+		})
+	}
+
+	return installed, nil
+}
+
+// ScriptCode returns the code of a device script, fetching it one chunk at a time.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptgetcode
+func (d *Device) ScriptCode(client *http.Client, id int) ([]byte, error) {
+	var code bytes.Buffer
+
+	for {
+		resp := &codeResponse{}
+
+		err := d.call(client, "Script.GetCode", map[string]any{
+			"id":     id,
+			"offset": code.Len(),
+		}, resp)
+		if err != nil {
+			return nil, err
+		}
+
+		code.WriteString(resp.Result.Data)
+
+		if resp.Result.Left <= 0 || resp.Result.Data == "" {
+			return code.Bytes(), nil
+		}
+	}
+}
+
+// scriptRequests creates a slice of *http.Request objects for calling a Script method on each script.
+func (d *Device) scriptRequests(method string, scripts []*device.InstalledScript) ([]*http.Request, error) {
+	requests := make([]*http.Request, 0, len(scripts))
+
+	for _, s := range scripts {
+		r, err := request(d, method, map[string]any{"id": s.ID})
+		if err != nil {
+			return nil, err
+		}
+
+		requests = append(requests, r)
+	}
+
+	return requests, nil
+}
+
+// StartScriptRequests creates a slice of *http.Request objects for starting device scripts.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptstart
+func (d *Device) StartScriptRequests(scripts []*device.InstalledScript) ([]*http.Request, error) {
+	return d.scriptRequests("Script.Start", scripts)
+}
+
+// StopScriptRequests creates a slice of *http.Request objects for stopping device scripts.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptstop
+func (d *Device) StopScriptRequests(scripts []*device.InstalledScript) ([]*http.Request, error) {
+	return d.scriptRequests("Script.Stop", scripts)
+}
+
+// RemoveScriptRequests creates an ordered slice of *http.Request objects for removing device scripts,
+// with the running ones being stopped beforehand.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptdelete
+func (d *Device) RemoveScriptRequests(scripts []*device.InstalledScript) ([]*http.Request, error) {
+	var running []*device.InstalledScript
+
+	for _, s := range scripts {
+		if s.Running {
+			running = append(running, s)
+		}
+	}
+
+	requests, err := d.StopScriptRequests(running)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := d.scriptRequests("Script.Delete", scripts)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(requests, rs...), nil
+}
+
+// defaultScriptBudget holds the script limits of the models without a budget of their own (e.g. Plus series).
+// The script count limit is set by the Script component, while the code size limit depends on the memory
+// of the model and isn't documented per model, so the sizes are conservative estimates. Devices running out
+// of memory still reject the code at upload time, failing the deployment instead of the lint.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script
+// See: https://shelly-api-docs.shelly.cloud/gen2/Scripts/ShellyScriptLanguageFeatures
+var defaultScriptBudget = &device.ScriptBudget{
+	MaxScripts: 10,
+	MaxSize:    16 << 10,
+}
+
+// scriptBudgets maps model prefixes to the script limits of the models with more memory (see defaultScriptBudget).
+var scriptBudgets = map[string]*device.ScriptBudget{
+	"SP": {MaxScripts: 10, MaxSize: 32 << 10}, // Pro series
+	"S3": {MaxScripts: 10, MaxSize: 32 << 10}, // Gen3 series
+	"S4": {MaxScripts: 10, MaxSize: 32 << 10}, // Gen4 series
+}
+
+// ScriptBudget returns the script limits of the device model.
+func (d *Device) ScriptBudget() *device.ScriptBudget {
+	for prefix, budget := range scriptBudgets {
+		if strings.HasPrefix(d.model, prefix) {
+			return budget
+		}
+	}
+
+	return defaultScriptBudget
+}
@@ -0,0 +1,164 @@
+package shellygen2
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+func TestDevice_InstalledScripts(t *testing.T) {
+	scripts, err := (&Device{}).InstalledScripts(&http.Client{Transport: &sequenceRoundTripper{bodies: []string{
+		`{"result":{"scripts":[{"id":1,"name":"announce.js","enable":true,"running":true},{"id":3,"name":"helper"}]}}`,
+	}}})
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := []*device.InstalledScript{
+		{Name: "announce.js", ID: 1, Enabled: true, Running: true},
+		{Name: "helper", ID: 3},
+	}
+
+	if !reflect.DeepEqual(scripts, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, scripts)
+	}
+}
+
+func TestDevice_ScriptCode(t *testing.T) {
+	tests := []struct {
+		rt   http.RoundTripper
+		err  error
+		name string
+		code string
+	}{
+		{
+			name: "failure: dispatch failed",
+			rt: &roundTripper{
+				err: net.ErrClosed,
+			},
+			err: net.ErrClosed,
+		},
+		{
+			name: "failure: rpc error",
+			rt: &sequenceRoundTripper{
+				bodies: []string{
+					`{"error":{"code":-105,"message":"Argument 'id', value 1 not found!"}}`,
+				},
+			},
+			err: &rpcError{Code: errCodeNotFound},
+		},
+		{
+			name: "success: code fetched in chunks",
+			rt: &sequenceRoundTripper{
+				bodies: []string{
+					`{"result":{"data":"var foo = ","left":6}}`,
+					`{"result":{"data":"\"abc\";","left":0}}`,
+				},
+			},
+			code: `var foo = "abc";`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, err := (&Device{}).ScriptCode(&http.Client{Transport: test.rt}, 1)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if string(code) != test.code {
+				t.Fatalf("expected %q, got %q", test.code, code)
+			}
+		})
+	}
+}
+
+func TestDevice_ScriptRequests(t *testing.T) {
+	scripts := []*device.InstalledScript{
+		{Name: "announce.js", ID: 1, Running: true},
+		{Name: "helper", ID: 3},
+	}
+
+	tests := []struct {
+		requests func(*Device, []*device.InstalledScript) ([]*http.Request, error)
+		name     string
+		bodies   []string
+	}{
+		{
+			name:     "start",
+			requests: (*Device).StartScriptRequests,
+			bodies: []string{
+				`{"id":0,"method":"Script.Start","params":{"id":1},"src":"IoTap"}`,
+				`{"id":0,"method":"Script.Start","params":{"id":3},"src":"IoTap"}`,
+			},
+		},
+		{
+			name:     "stop",
+			requests: (*Device).StopScriptRequests,
+			bodies: []string{
+				`{"id":0,"method":"Script.Stop","params":{"id":1},"src":"IoTap"}`,
+				`{"id":0,"method":"Script.Stop","params":{"id":3},"src":"IoTap"}`,
+			},
+		},
+		{
+			name:     "remove",
+			requests: (*Device).RemoveScriptRequests,
+			bodies: []string{
+				`{"id":0,"method":"Script.Stop","params":{"id":1},"src":"IoTap"}`,
+				`{"id":0,"method":"Script.Delete","params":{"id":1},"src":"IoTap"}`,
+				`{"id":0,"method":"Script.Delete","params":{"id":3},"src":"IoTap"}`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rs, err := test.requests(&Device{}, scripts)
+			if err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			if bodies := rpcBodies(t, rs); !reflect.DeepEqual(bodies, test.bodies) {
+				t.Fatalf("expected %q, got %q", test.bodies, bodies)
+			}
+		})
+	}
+}
+
+func TestDevice_ScriptBudget(t *testing.T) {
+	tests := []struct {
+		expected *device.ScriptBudget
+		name     string
+		model    string
+	}{
+		{
+			name:     "plus series",
+			model:    "SNSW-001X16EU",
+			expected: defaultScriptBudget,
+		},
+		{
+			name:     "pro series",
+			model:    "SPSW-004PE16EU",
+			expected: scriptBudgets["SP"],
+		},
+		{
+			name:     "gen3 series",
+			model:    "S3SW-001X16EU",
+			expected: scriptBudgets["S3"],
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dev := &Device{model: test.model}
+
+			if budget := dev.ScriptBudget(); budget != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, budget)
+			}
+		})
+	}
+}
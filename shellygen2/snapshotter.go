@@ -0,0 +1,124 @@
+package shellygen2
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+// getConfigResponse holds the result of a <Component>.GetConfig method request.
+type getConfigResponse struct {
+	Result map[string]any `json:"result"`
+}
+
+// writeOnlyKeys holds the setting keys whose values aren't returned by GetConfig methods (e.g. Wi-Fi and MQTT passwords).
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/WiFi
+var writeOnlyKeys = []string{"pass"}
+
+// snapshot captures the current configuration of a single device component.
+// Only the settings present in the params are kept, so that a rollback restores exactly what was changed.
+func (d *Device) snapshot(client *http.Client, method string, params *settings) (*settings, error) {
+	var args map[string]any
+
+	id, indexed := (*params)["id"]
+	if indexed {
+		args = map[string]any{"id": id}
+	}
+
+	resp := &getConfigResponse{}
+
+	if err := d.call(client, method, args, resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Result == nil {
+		return nil, fmt.Errorf("%w: %s", device.ErrUnexpected, method)
+	}
+
+	snap := settings{}
+
+	if indexed {
+		snap["id"] = id
+	}
+
+	if cfg, ok := (*params)["config"].(map[string]any); ok {
+		snap["config"] = device.Mask(resp.Result, cfg)
+	}
+
+	return &snap, nil
+}
+
+// writeOnly checks if component settings hold write-only values (see device.HoldsKey).
+func writeOnly(params any) bool {
+	switch params := params.(type) {
+	case *settings:
+		return device.HoldsKey(*params, writeOnlyKeys...)
+
+	case *[]*settings:
+		for _, p := range *params {
+			if device.HoldsKey(*p, writeOnlyKeys...) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Snapshot captures the current configuration of the device components affected by a Config.
+// The resulting Config can be used to restore the device to its previous state.
+// Components set with write-only values (e.g. Wi-Fi passwords) are left out, so they're never rolled back.
+// See: https://shelly-api-docs.shelly.cloud/gen2/General/RPCProtocol#common-configuration-methods
+func (d *Device) Snapshot(client *http.Client, config device.Config) (device.Config, error) {
+	conf, ok := config.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected %q, got %q", device.ErrDriverMismatch, d.Driver(), config.Driver())
+	}
+
+	snap := &Config{}
+
+	confVal := reflect.Indirect(reflect.ValueOf(conf))
+	snapVal := reflect.Indirect(reflect.ValueOf(snap))
+
+	for i := range confVal.Type().NumField() {
+		setting := confVal.Field(i)
+
+		// Skip nil setting pointers, along with the settings that can't be captured
+		if setting.IsNil() || writeOnly(setting.Interface()) {
+			continue
+		}
+
+		// Current setting tag
+		tag := strings.TrimSuffix(confVal.Type().Field(i).Tag.Get("json"), ",omitempty")
+		method := fmt.Sprintf("%s.GetConfig", tag)
+
+		switch params := setting.Interface().(type) {
+		case *settings:
+			s, err := d.snapshot(client, method, params)
+			if err != nil {
+				return nil, err
+			}
+
+			snapVal.Field(i).Set(reflect.ValueOf(s))
+
+		case *[]*settings:
+			var list []*settings
+
+			for _, p := range *params {
+				s, err := d.snapshot(client, method, p)
+				if err != nil {
+					return nil, err
+				}
+
+				list = append(list, s)
+			}
+
+			snapVal.Field(i).Set(reflect.ValueOf(&list))
+		}
+	}
+
+	return snap, nil
+}
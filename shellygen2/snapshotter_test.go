@@ -0,0 +1,182 @@
+package shellygen2
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+func TestDevice_Snapshot(t *testing.T) {
+	tests := []struct {
+		rt   http.RoundTripper
+		cfg  device.Config
+		snap device.Config
+		err  error
+		name string
+	}{
+		{
+			name: "failure: driver mismatch",
+			cfg:  &config{},
+			err:  device.ErrDriverMismatch,
+		},
+		{
+			name: "failure: dispatch failed",
+			cfg: &Config{
+				MQTT: &settings{
+					"config": map[string]any{
+						"enable": true,
+					},
+				},
+			},
+			rt: &roundTripper{
+				err: net.ErrClosed,
+			},
+			err: net.ErrClosed,
+		},
+		{
+			name: "failure: unexpected response",
+			cfg: &Config{
+				MQTT: &settings{
+					"config": map[string]any{
+						"enable": true,
+					},
+				},
+			},
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{}`)),
+				},
+			},
+			err: device.ErrUnexpected,
+		},
+		{
+			name: "failure: rpc error",
+			cfg: &Config{
+				MQTT: &settings{
+					"config": map[string]any{
+						"enable": true,
+					},
+				},
+			},
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"error":{"code":-105,"message":"not found"}}`)),
+				},
+			},
+			err: &rpcError{},
+		},
+		{
+			name: "success: write-only settings left out",
+			cfg: &Config{
+				MQTT: &settings{
+					"config": map[string]any{
+						"enable": true,
+					},
+				},
+				WiFi: &settings{
+					"config": map[string]any{
+						"sta": map[string]any{"ssid": "home", "pass": "secret"},
+					},
+				},
+			},
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"result":{"enable":false,"server":"mqtt.local"}}`)),
+				},
+			},
+			snap: &Config{
+				MQTT: &settings{
+					"config": map[string]any{
+						"enable": false,
+					},
+				},
+			},
+		},
+		{
+			name: "success: single settings",
+			cfg: &Config{
+				MQTT: &settings{
+					"config": map[string]any{
+						"enable": true,
+					},
+				},
+			},
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"result":{"enable":false,"server":"mqtt.local"}}`)),
+				},
+			},
+			snap: &Config{
+				MQTT: &settings{
+					"config": map[string]any{
+						"enable": false,
+					},
+				},
+			},
+		},
+		{
+			name: "success: settings slice",
+			cfg: &Config{
+				Switch: &[]*settings{
+					{
+						"id": 0,
+						"config": map[string]any{
+							"name": "Kettle",
+						},
+					},
+				},
+			},
+			rt: &roundTripper{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"result":{"id":0,"name":null,"in_mode":"follow"}}`)),
+				},
+			},
+			snap: &Config{
+				Switch: &[]*settings{
+					{
+						"id": 0,
+						"config": map[string]any{
+							"name": nil,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	shelly2 := &Device{ip: net.ParseIP("192.168.146.123")}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			snap, err := shelly2.Snapshot(&http.Client{Transport: test.rt}, test.cfg)
+
+			if !reflect.DeepEqual(snap, test.snap) {
+				t.Fatalf("expected %#v, got %#v", test.snap, snap)
+			}
+
+			var re *rpcError
+			if errors.As(test.err, &re) {
+				if !errors.As(err, &re) {
+					t.Fatalf("expected %#v, got %#v", test.err, err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+		})
+	}
+}
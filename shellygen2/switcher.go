@@ -0,0 +1,57 @@
+package shellygen2
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+// switchResponse holds the outcome of a Switch.Set or Switch.Toggle request.
+type switchResponse struct {
+	Result struct {
+		WasOn bool `json:"was_on"`
+	} `json:"result"`
+}
+
+// Switch turns a switch component on, off or toggles it, returning its resulting state.
+// When set, the timer flips the output back once it elapses.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Switch#switchset
+func (d *Device) Switch(client *http.Client, channel int, action string, timer time.Duration) (bool, error) {
+	var (
+		method = "Switch.Set"
+		params = map[string]any{
+			"id": channel,
+		}
+	)
+
+	switch action {
+	case device.ActionToggle:
+		method = "Switch.Toggle"
+
+	default:
+		params["on"] = action == device.ActionOn
+
+		if timer > 0 {
+			params["toggle_after"] = timer.Seconds()
+		}
+	}
+
+	resp := &switchResponse{}
+
+	err := d.call(client, method, params, resp)
+	if isRPCError(err, errCodeNotFound) {
+		return false, fmt.Errorf("%w: switch %d", device.ErrChannelNotFound, channel)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if action == device.ActionToggle {
+		return !resp.Result.WasOn, nil
+	}
+
+	return action == device.ActionOn, nil
+}
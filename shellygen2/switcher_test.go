@@ -0,0 +1,84 @@
+package shellygen2
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+func TestDevice_Switch(t *testing.T) {
+	tests := []struct {
+		err     error
+		name    string
+		action  string
+		body    string
+		request string
+		timer   time.Duration
+		channel int
+		on      bool
+	}{
+		{
+			name:    "failure: channel not found",
+			action:  device.ActionOn,
+			channel: 3,
+			body:    `{"id":0,"src":"shellypro1","error":{"code":-105,"message":"Argument 'id', value 3 not found!"}}`,
+			request: `{"id":0,"method":"Switch.Set","params":{"id":3,"on":true},"src":"IoTap"}`,
+			err:     device.ErrChannelNotFound,
+		},
+		{
+			name:    "success: on",
+			action:  device.ActionOn,
+			body:    `{"id":0,"src":"shellypro1","result":{"was_on":false}}`,
+			request: `{"id":0,"method":"Switch.Set","params":{"id":0,"on":true},"src":"IoTap"}`,
+			on:      true,
+		},
+		{
+			name:    "success: on with timer",
+			action:  device.ActionOn,
+			channel: 1,
+			timer:   90 * time.Second,
+			body:    `{"id":0,"src":"shellypro1","result":{"was_on":false}}`,
+			request: `{"id":0,"method":"Switch.Set","params":{"id":1,"on":true,"toggle_after":90},"src":"IoTap"}`,
+			on:      true,
+		},
+		{
+			name:    "success: off",
+			action:  device.ActionOff,
+			body:    `{"id":0,"src":"shellypro1","result":{"was_on":true}}`,
+			request: `{"id":0,"method":"Switch.Set","params":{"id":0,"on":false},"src":"IoTap"}`,
+		},
+		{
+			name:    "success: toggle",
+			action:  device.ActionToggle,
+			body:    `{"id":0,"src":"shellypro1","result":{"was_on":false}}`,
+			request: `{"id":0,"method":"Switch.Toggle","params":{"id":0},"src":"IoTap"}`,
+			on:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := &recorder{body: test.body}
+
+			dev := &Device{ip: net.ParseIP("192.168.1.10")}
+
+			on, err := dev.Switch(&http.Client{Transport: rec}, test.channel, test.action, test.timer)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if on != test.on {
+				t.Fatalf("expected %t, got %t", test.on, on)
+			}
+
+			if bodies := rpcBodies(t, rec.requests); !reflect.DeepEqual(bodies, []string{test.request}) {
+				t.Fatalf("expected %q, got %q", test.request, bodies)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package shellygen2
+
+import (
+	"encoding/json/v2"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+// componentItem is a device component representation, as returned by Shelly.GetComponents.
+type componentItem struct {
+	Config map[string]any `json:"config"`
+	Key    string         `json:"key"`
+}
+
+// virtual returns the virtual component representation of the component,
+// or nil if it isn't a virtual one (e.g. switch:0, boolean:1).
+func (c *componentItem) virtual() *device.VirtualComponent {
+	typ, id, ok := strings.Cut(c.Key, ":")
+	if !ok || !slices.Contains(device.VirtualTypes, typ) {
+		return nil
+	}
+
+	n, err := strconv.Atoi(id)
+	if err != nil || n < device.VirtualMinID || n > device.VirtualMaxID {
+		return nil
+	}
+
+	return &device.VirtualComponent{
+		Type:   typ,
+		ID:     n,
+		Config: c.Config,
+	}
+}
+
+// componentsResponse holds the result of a Shelly.GetComponents method request.
+type componentsResponse struct {
+	Result struct {
+		Components []*componentItem `json:"components"`
+		Total      int              `json:"total"`
+	} `json:"result"`
+}
+
+// VirtualComponents returns the virtual components of the device, fetching the device components one page at a time.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Shelly#shellygetcomponents
+func (d *Device) VirtualComponents(client *http.Client) ([]*device.VirtualComponent, error) {
+	var (
+		components []*device.VirtualComponent
+		offset     int
+	)
+
+	for {
+		resp := &componentsResponse{}
+
+		err := d.call(client, "Shelly.GetComponents", map[string]any{
+			"offset":  offset,
+			"include": []string{"config"},
+		}, resp)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range resp.Result.Components {
+			if vc := c.virtual(); vc != nil {
+				components = append(components, vc)
+			}
+		}
+
+		offset += len(resp.Result.Components)
+
+		if len(resp.Result.Components) == 0 || offset >= resp.Result.Total {
+			return components, nil
+		}
+	}
+}
+
+// normalise returns the JSON representation of a declared value (e.g. integers become floats),
+// or the value itself when it can't be represented.
+func normalise(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var normalised any
+	if err = json.Unmarshal(data, &normalised); err != nil {
+		return value
+	}
+
+	return normalised
+}
+
+// valueChanged checks if a normalised declared value differs from the device one.
+// Objects are compared against their declared keys only, as devices report every key of nested objects too
+// (e.g. meta.ui), so undeclared keys don't count as changes.
+func valueChanged(declared, current any) bool {
+	object, ok := declared.(map[string]any)
+	if !ok {
+		return !reflect.DeepEqual(declared, current)
+	}
+
+	cur, ok := current.(map[string]any)
+	if !ok {
+		return true
+	}
+
+	for key, value := range object {
+		if valueChanged(value, cur[key]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// configChanged checks if any declared configuration value differs from the device one (see valueChanged).
+// Declared values are normalised to their JSON representation first (see normalise).
+func configChanged(declared, current map[string]any) bool {
+	return valueChanged(normalise(declared), current)
+}
+
+// VirtualRequests creates an ordered slice of *http.Request objects for syncing virtual components.
+// Components that aren't declared are deleted first when pruning, to stay within the device limits,
+// followed by the missing components being added, and the changed ones being updated.
+// See: https://shelly-api-docs.shelly.cloud/gen2/DynamicComponents/Virtual
+func (d *Device) VirtualRequests(client *http.Client, components []*device.VirtualComponent, prune bool) ([]*http.Request, []*device.VirtualComponent, error) {
+	current, err := d.VirtualComponents(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existing := make(map[string]*device.VirtualComponent, len(current))
+	declared := make(map[string]bool, len(components))
+
+	for _, vc := range current {
+		existing[vc.Key()] = vc
+	}
+
+	for _, vc := range components {
+		declared[vc.Key()] = true
+	}
+
+	var (
+		requests []*http.Request
+		extra    []*device.VirtualComponent
+	)
+
+	for _, vc := range current {
+		if declared[vc.Key()] {
+			continue
+		}
+
+		extra = append(extra, vc)
+
+		if !prune {
+			continue
+		}
+
+		r, err := request(d, "Virtual.Delete", map[string]any{"key": vc.Key()})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		requests = append(requests, r)
+	}
+
+	for _, vc := range components {
+		cur, ok := existing[vc.Key()]
+		if !ok {
+			params := map[string]any{
+				"type": vc.Type,
+				"id":   vc.ID,
+			}
+
+			if len(vc.Config) > 0 {
+				params["config"] = vc.Config
+			}
+
+			r, err := request(d, "Virtual.Add", params)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			requests = append(requests, r)
+			continue
+		}
+
+		if !configChanged(vc.Config, cur.Config) {
+			continue
+		}
+
+		// e.g. Boolean.SetConfig
+		r, err := request(d, strings.ToUpper(vc.Type[:1])+vc.Type[1:]+".SetConfig", map[string]any{
+			"id":     vc.ID,
+			"config": vc.Config,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		requests = append(requests, r)
+	}
+
+	return requests, extra, nil
+}
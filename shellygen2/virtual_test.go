@@ -0,0 +1,216 @@
+package shellygen2
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+const (
+	componentsPage1 = `{"result":{"components":[` +
+		`{"key":"switch:0","config":{"id":0,"name":null}},` +
+		`{"key":"boolean:200","config":{"id":200,"name":"Heating","default_value":false,"persisted":true}}` +
+		`],"offset":0,"total":4}}`
+
+	componentsPage2 = `{"result":{"components":[` +
+		`{"key":"number:200","config":{"id":200,"name":"Target","min":5,"max":30,"default_value":21}},` +
+		`{"key":"button:201","config":{"id":201,"name":"Boost"}}` +
+		`],"offset":2,"total":4}}`
+)
+
+func TestDevice_VirtualComponents(t *testing.T) {
+	tests := []struct {
+		rt   http.RoundTripper
+		err  error
+		name string
+		keys []string
+	}{
+		{
+			name: "failure: dispatch failed",
+			rt: &roundTripper{
+				err: net.ErrClosed,
+			},
+			err: net.ErrClosed,
+		},
+		{
+			name: "failure: rpc error",
+			rt: &sequenceRoundTripper{
+				bodies: []string{`{"error":{"code":-103,"message":"Invalid argument 'include'"}}`},
+			},
+			err: &rpcError{Code: -103},
+		},
+		{
+			name: "success: paginated components",
+			rt: &sequenceRoundTripper{
+				bodies: []string{componentsPage1, componentsPage2},
+			},
+			keys: []string{"boolean:200", "number:200", "button:201"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			components, err := (&Device{}).VirtualComponents(&http.Client{Transport: test.rt})
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			var keys []string
+			for _, vc := range components {
+				keys = append(keys, vc.Key())
+			}
+
+			if !reflect.DeepEqual(keys, test.keys) {
+				t.Fatalf("expected %v, got %v", test.keys, keys)
+			}
+		})
+	}
+}
+
+func TestDevice_VirtualRequests(t *testing.T) {
+	components := []*device.VirtualComponent{
+		{Type: "boolean", ID: 200, Config: map[string]any{"name": "Heating", "persisted": true}},
+		{Type: "number", ID: 200, Config: map[string]any{"name": "Target", "max": 25}},
+		{Type: "text", ID: 202, Config: map[string]any{"name": "Status"}},
+		{Type: "enum", ID: 203},
+	}
+
+	tests := []struct {
+		rt     http.RoundTripper
+		err    error
+		name   string
+		bodies []string
+		extra  []string
+		prune  bool
+	}{
+		{
+			name: "failure: dispatch failed",
+			rt: &roundTripper{
+				err: net.ErrClosed,
+			},
+			err: net.ErrClosed,
+		},
+		{
+			name: "failure: rpc error",
+			rt: &sequenceRoundTripper{
+				bodies: []string{`{"error":{"code":-103,"message":"Invalid argument 'include'"}}`},
+			},
+			err: &rpcError{Code: -103},
+		},
+		{
+			name: "success: components synced",
+			rt: &sequenceRoundTripper{
+				bodies: []string{componentsPage1, componentsPage2},
+			},
+			bodies: []string{
+				`{"id":0,"method":"Number.SetConfig","params":{"config":{"max":25,"name":"Target"},"id":200},"src":"IoTap"}`,
+				`{"id":0,"method":"Virtual.Add","params":{"config":{"name":"Status"},"id":202,"type":"text"},"src":"IoTap"}`,
+				`{"id":0,"method":"Virtual.Add","params":{"id":203,"type":"enum"},"src":"IoTap"}`,
+			},
+			extra: []string{"button:201"},
+		},
+		{
+			name: "success: components synced and pruned",
+			rt: &sequenceRoundTripper{
+				bodies: []string{componentsPage1, componentsPage2},
+			},
+			prune: true,
+			bodies: []string{
+				`{"id":0,"method":"Virtual.Delete","params":{"key":"button:201"},"src":"IoTap"}`,
+				`{"id":0,"method":"Number.SetConfig","params":{"config":{"max":25,"name":"Target"},"id":200},"src":"IoTap"}`,
+				`{"id":0,"method":"Virtual.Add","params":{"config":{"name":"Status"},"id":202,"type":"text"},"src":"IoTap"}`,
+				`{"id":0,"method":"Virtual.Add","params":{"id":203,"type":"enum"},"src":"IoTap"}`,
+			},
+			extra: []string{"button:201"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rs, extra, err := (&Device{}).VirtualRequests(&http.Client{Transport: test.rt}, components, test.prune)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if bodies := rpcBodies(t, rs); !reflect.DeepEqual(bodies, test.bodies) {
+				t.Fatalf("expected %q, got %q", test.bodies, bodies)
+			}
+
+			var keys []string
+			for _, vc := range extra {
+				keys = append(keys, vc.Key())
+			}
+
+			if !reflect.DeepEqual(keys, test.extra) {
+				t.Fatalf("expected %v, got %v", test.extra, keys)
+			}
+		})
+	}
+}
+
+func TestConfigChanged(t *testing.T) {
+	current := map[string]any{
+		"id":   200.0,
+		"name": "Target",
+		"meta": map[string]any{
+			"ui": map[string]any{"view": "slider", "unit": "°C", "step": 0.5},
+		},
+	}
+
+	tests := []struct {
+		declared map[string]any
+		name     string
+		changed  bool
+	}{
+		{
+			name:     "unchanged: no declared values",
+			declared: nil,
+		},
+		{
+			name:     "unchanged: normalised values",
+			declared: map[string]any{"id": 200, "name": "Target"},
+		},
+		{
+			name: "unchanged: nested declared keys",
+			declared: map[string]any{
+				"meta": map[string]any{"ui": map[string]any{"view": "slider"}},
+			},
+		},
+		{
+			name:     "changed: top level value",
+			declared: map[string]any{"name": "Heating"},
+			changed:  true,
+		},
+		{
+			name: "changed: nested value",
+			declared: map[string]any{
+				"meta": map[string]any{"ui": map[string]any{"view": "label"}},
+			},
+			changed: true,
+		},
+		{
+			name: "changed: missing nested key",
+			declared: map[string]any{
+				"meta": map[string]any{"ui": map[string]any{"icon": "fire"}},
+			},
+			changed: true,
+		},
+		{
+			name:     "changed: object replacing a value",
+			declared: map[string]any{"name": map[string]any{"en": "Target"}},
+			changed:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if changed := configChanged(test.declared, current); changed != test.changed {
+				t.Fatalf("expected %t, got %t", test.changed, changed)
+			}
+		})
+	}
+}
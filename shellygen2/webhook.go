@@ -0,0 +1,179 @@
+package shellygen2
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+// hook is a webhook resource representation.
+type hook struct {
+	ID     int      `json:"id,omitzero"`
+	CID    int      `json:"cid"`
+	Enable bool     `json:"enable"`
+	Event  string   `json:"event"`
+	Name   string   `json:"name,omitempty"`
+	URLs   []string `json:"urls"`
+}
+
+// installed returns the display friendly representation of the hook.
+func (h *hook) installed() *device.InstalledHook {
+	return &device.InstalledHook{
+		ID:      fmt.Sprint(h.ID),
+		Event:   h.Event,
+		Channel: h.CID,
+		Enabled: h.Enable,
+		URLs:    h.URLs,
+	}
+}
+
+// hookListResponse holds the result of a Webhook.List method request.
+type hookListResponse struct {
+	Result struct {
+		Hooks []*hook `json:"hooks"`
+	} `json:"result"`
+}
+
+// supportedHooksResponse holds the result of a Webhook.ListSupported method request.
+// Recent firmware versions list the event types as object keys, while older ones use an array.
+type supportedHooksResponse struct {
+	Result struct {
+		Types     map[string]any `json:"types"`
+		HookTypes []string       `json:"hook_types"`
+	} `json:"result"`
+}
+
+// events returns the supported event types.
+func (r *supportedHooksResponse) events() []string {
+	events := slices.Clone(r.Result.HookTypes)
+
+	for event := range r.Result.Types {
+		events = append(events, event)
+	}
+
+	return events
+}
+
+// fetchHooks returns the webhooks of the device.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Webhook#webhooklist
+func (d *Device) fetchHooks(client *http.Client) ([]*hook, error) {
+	resp := &hookListResponse{}
+
+	if err := d.fetch(client, "Webhook.List", resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Result.Hooks, nil
+}
+
+// InstalledHooks returns the webhooks of the device.
+func (d *Device) InstalledHooks(client *http.Client) ([]*device.InstalledHook, error) {
+	hooks, err := d.fetchHooks(client)
+	if err != nil {
+		return nil, err
+	}
+
+	installed := make([]*device.InstalledHook, 0, len(hooks))
+
+	for _, h := range hooks {
+		installed = append(installed, h.installed())
+	}
+
+	return installed, nil
+}
+
+// HookRequests creates an ordered slice of *http.Request objects for syncing webhooks.
+// Hooks are matched by event, component ID and name, being updated when their URLs or state differ,
+// and created when missing. Hooks for events the device doesn't support are skipped.
+// Device hooks that aren't declared are returned, and deleted when pruning.
+// See: https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Webhook
+func (d *Device) HookRequests(client *http.Client, hooks []*device.Hook, prune bool) ([]*http.Request, []*device.InstalledHook, error) {
+	supported := &supportedHooksResponse{}
+
+	if err := d.fetch(client, "Webhook.ListSupported", supported); err != nil {
+		return nil, nil, err
+	}
+
+	events := supported.events()
+
+	existing, err := d.fetchHooks(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var requests []*http.Request
+
+	matched := make(map[int]bool, len(existing))
+
+	for _, dh := range hooks {
+		if !slices.Contains(events, dh.Event) {
+			continue
+		}
+
+		var found *hook
+		for _, h := range existing {
+			if !matched[h.ID] && h.Event == dh.Event && h.CID == dh.Channel && h.Name == dh.Name {
+				found = h
+				break
+			}
+		}
+
+		if found == nil {
+			r, err := request(d, "Webhook.Create", &hook{
+				CID:    dh.Channel,
+				Enable: dh.Enabled(),
+				Event:  dh.Event,
+				Name:   dh.Name,
+				URLs:   dh.URLs,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+
+			requests = append(requests, r)
+			continue
+		}
+
+		matched[found.ID] = true
+
+		if found.Enable == dh.Enabled() && slices.Equal(found.URLs, dh.URLs) {
+			continue
+		}
+
+		r, err := request(d, "Webhook.Update", map[string]any{
+			"id":     found.ID,
+			"enable": dh.Enabled(),
+			"urls":   dh.URLs,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		requests = append(requests, r)
+	}
+
+	var extra []*device.InstalledHook
+
+	for _, h := range existing {
+		if matched[h.ID] {
+			continue
+		}
+
+		extra = append(extra, h.installed())
+
+		if !prune {
+			continue
+		}
+
+		r, err := request(d, "Webhook.Delete", map[string]any{"id": h.ID})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		requests = append(requests, r)
+	}
+
+	return requests, extra, nil
+}
@@ -0,0 +1,143 @@
+package shellygen2
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quetzyg/IoTap/device"
+)
+
+// sequenceRoundTripper returns the mocked responses in order, one per request.
+type sequenceRoundTripper struct {
+	bodies []string
+	count  int
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (srt *sequenceRoundTripper) RoundTrip(_ *http.Request) (*http.Response, error) {
+	body := srt.bodies[srt.count]
+	srt.count++
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+const (
+	supportedHooks = `{"result":{"types":{"switch.on":{},"switch.off":{},"input.toggle_on":{}}}}`
+
+	hookList = `{"result":{"hooks":[` +
+		`{"id":1,"cid":0,"enable":true,"event":"switch.on","name":"notify","urls":["http://example.com/on"]},` +
+		`{"id":2,"cid":0,"enable":false,"event":"switch.off","name":"notify","urls":["http://example.com/off"]},` +
+		`{"id":3,"cid":0,"enable":true,"event":"input.toggle_on","name":"legacy","urls":["http://example.com/toggle"]}` +
+		`]}}`
+)
+
+func TestDevice_InstalledHooks(t *testing.T) {
+	hooks, err := (&Device{}).InstalledHooks(&http.Client{Transport: &sequenceRoundTripper{bodies: []string{hookList}}})
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := []*device.InstalledHook{
+		{ID: "1", Event: "switch.on", Enabled: true, URLs: []string{"http://example.com/on"}},
+		{ID: "2", Event: "switch.off", Enabled: false, URLs: []string{"http://example.com/off"}},
+		{ID: "3", Event: "input.toggle_on", Enabled: true, URLs: []string{"http://example.com/toggle"}},
+	}
+
+	if !reflect.DeepEqual(hooks, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, hooks)
+	}
+}
+
+func TestDevice_HookRequests(t *testing.T) {
+	hooks := []*device.Hook{
+		{Event: "switch.on", Name: "notify", URLs: []string{"http://example.com/on"}},
+		{Event: "switch.off", Name: "notify", URLs: []string{"http://example.com/off?v=2"}},
+		{Event: "switch.on", Channel: 1, URLs: []string{"http://example.com/on"}},
+		{Event: "out_on_url", URLs: []string{"http://example.com/gen1"}},
+	}
+
+	tests := []struct {
+		rt     http.RoundTripper
+		err    error
+		name   string
+		bodies []string
+		extra  []string
+		prune  bool
+	}{
+		{
+			name: "failure: dispatch failed",
+			rt: &roundTripper{
+				err: net.ErrClosed,
+			},
+			err: net.ErrClosed,
+		},
+		{
+			name: "failure: supported events rpc error",
+			rt: &sequenceRoundTripper{
+				bodies: []string{`{"error":{"code":-114,"message":"Method Webhook.ListSupported failed"}}`, hookList},
+			},
+			err: &rpcError{Code: -114},
+		},
+		{
+			name: "failure: hook list rpc error",
+			rt: &sequenceRoundTripper{
+				bodies: []string{supportedHooks, `{"error":{"code":-114,"message":"Method Webhook.List failed"}}`},
+			},
+			err: &rpcError{Code: -114},
+		},
+		{
+			name: "success: hooks synced",
+			rt: &sequenceRoundTripper{
+				bodies: []string{supportedHooks, hookList},
+			},
+			bodies: []string{
+				`{"id":0,"method":"Webhook.Update","params":{"enable":true,"id":2,"urls":["http://example.com/off?v=2"]},"src":"IoTap"}`,
+				`{"id":0,"method":"Webhook.Create","params":{"cid":1,"enable":true,"event":"switch.on","urls":["http://example.com/on"]},"src":"IoTap"}`,
+			},
+			extra: []string{"3"},
+		},
+		{
+			name: "success: hooks synced with older firmware and pruned",
+			rt: &sequenceRoundTripper{
+				bodies: []string{`{"result":{"hook_types":["switch.on","switch.off"]}}`, hookList},
+			},
+			prune: true,
+			bodies: []string{
+				`{"id":0,"method":"Webhook.Update","params":{"enable":true,"id":2,"urls":["http://example.com/off?v=2"]},"src":"IoTap"}`,
+				`{"id":0,"method":"Webhook.Create","params":{"cid":1,"enable":true,"event":"switch.on","urls":["http://example.com/on"]},"src":"IoTap"}`,
+				`{"id":0,"method":"Webhook.Delete","params":{"id":3},"src":"IoTap"}`,
+			},
+			extra: []string{"3"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rs, extra, err := (&Device{}).HookRequests(&http.Client{Transport: test.rt}, hooks, test.prune)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %#v, got %#v", test.err, err)
+			}
+
+			if bodies := rpcBodies(t, rs); !reflect.DeepEqual(bodies, test.bodies) {
+				t.Fatalf("expected %q, got %q", test.bodies, bodies)
+			}
+
+			var ids []string
+			for _, hook := range extra {
+				ids = append(ids, hook.ID)
+			}
+
+			if !reflect.DeepEqual(ids, test.extra) {
+				t.Fatalf("expected %v, got %v", test.extra, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+let target = "{{ .Vars.target }}";
+let threshold = {{ .Vars.threshold }};
+
+{{- if eq .Model "SNSW-001X16EU" }}
+print("{{ .Name }} relay ready");
+{{- end }}